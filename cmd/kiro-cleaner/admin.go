@@ -0,0 +1,253 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/accounts"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+var (
+	scanAllUsers  bool
+	cleanAllUsers bool
+	adminUsers    []string
+)
+
+func init() {
+	scanCmd.Flags().BoolVar(&scanAllUsers, "all-users", false, "Admin mode (root): scan the Kiro data of every user in admin.allow_users")
+	scanCmd.Flags().StringSliceVar(&adminUsers, "users", nil, "With --all-users: only these users (must be in admin.allow_users)")
+	cleanCmd.Flags().BoolVar(&cleanAllUsers, "all-users", false, "Admin mode (root): clean the Kiro data of every user in admin.allow_users")
+	cleanCmd.Flags().StringSliceVar(&adminUsers, "users", nil, "With --all-users: only these users (must be in admin.allow_users)")
+}
+
+// userUsage 一个用户的存储统计
+type userUsage struct {
+	user     accounts.User
+	installs int
+	chats    int64
+	logs     int64
+	cache    int64
+	temp     int64
+	total    int64
+}
+
+// cleanable 日志、缓存、临时文件等可以直接清理的部分
+func (u userUsage) cleanable() int64 {
+	return u.logs + u.cache + u.temp
+}
+
+// adminUsersToProcess 检查管理员模式的前提，返回允许处理的用户
+// 需要在 Linux 上以 root 运行；只处理 admin.allow_users 中的用户，--users 中有不允许的用户时整体拒绝
+func adminUsersToProcess() ([]accounts.User, bool) {
+	if runtime.GOOS != "linux" {
+		termUI.PrintError("--all-users is only supported on Linux")
+		return nil, false
+	}
+	if os.Geteuid() != 0 {
+		termUI.PrintError("--all-users must be run as root (e.g. sudo kiro-cleaner ... --all-users)")
+		return nil, false
+	}
+	allow := config.LoadConfig().Admin.AllowUsers
+	if len(allow) == 0 {
+		termUI.PrintError("No users are allowed in admin mode")
+		termUI.PrintInfo(fmt.Sprintf("Add user names to \"admin.allow_users\" in %s", config.ConfigPath()))
+		return nil, false
+	}
+	for _, name := range adminUsers {
+		if !accounts.Allowed(name, allow) {
+			termUI.PrintError(fmt.Sprintf("Refusing to act on %s: not in admin.allow_users", name))
+			return nil, false
+		}
+	}
+
+	passwd, err := accounts.ReadPasswd(accounts.PasswdPath)
+	if err != nil {
+		termUI.PrintError(err.Error())
+		return nil, false
+	}
+	var users []accounts.User
+	var skipped []string
+	for _, u := range accounts.LoginUsers(passwd) {
+		switch {
+		case len(adminUsers) > 0 && !accounts.Allowed(u.Name, adminUsers):
+		case accounts.Allowed(u.Name, allow):
+			users = append(users, u)
+		default:
+			skipped = append(skipped, u.Name)
+		}
+	}
+	if len(skipped) > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Skipped %d users not in admin.allow_users: %s", len(skipped), strings.Join(skipped, ", ")))
+	}
+	if len(users) == 0 {
+		termUI.PrintWarning("None of the allowed users has a home directory on this machine")
+	}
+	return users, len(users) > 0
+}
+
+// ownedInstalls 返回属于该用户的 Kiro 安装（需先 Activate），其他用户的目录（如通过进程命令行发现的）被忽略
+func ownedInstalls(u accounts.User) []storage.Install {
+	var owned []storage.Install
+	for _, in := range storage.DiscoverInstalls() {
+		if u.Owns(in.Path) {
+			owned = append(owned, in)
+		}
+	}
+	return owned
+}
+
+// runAdminScan 统计每个允许的用户的 Kiro 数据
+func runAdminScan(cmd *cobra.Command, args []string) error {
+	users, ok := adminUsersToProcess()
+	if !ok {
+		return nil
+	}
+
+	spinner := termUI.Spinner("Scanning home directories...")
+	var rows []userUsage
+	for _, u := range users {
+		spinner.UpdateText(fmt.Sprintf("Scanning %s...", u.Name))
+		rows = append(rows, scanUser(u))
+	}
+	spinner.Stop()
+
+	termUI.PrintSection("Kiro Data by User")
+	fmt.Printf("  %s\n", pterm.NewStyle(pterm.FgGray).Sprintf("%-16s %8s %10s %10s %10s %10s %10s %10s",
+		"USER", "INSTALLS", "CHATS", "LOGS", "CACHE", "TEMP", "CLEANABLE", "TOTAL"))
+	var sum userUsage
+	for _, r := range rows {
+		printUserUsage(pterm.NewStyle(pterm.FgCyan, pterm.Bold).Sprintf("%-16s", r.user.Name), r)
+		sum.installs += r.installs
+		sum.chats += r.chats
+		sum.logs += r.logs
+		sum.cache += r.cache
+		sum.temp += r.temp
+		sum.total += r.total
+	}
+	fmt.Println("  " + strings.Repeat("─", 98))
+	printUserUsage(pterm.NewStyle(pterm.Bold).Sprintf("%-16s", "Total"), sum)
+	fmt.Println()
+
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner clean --all-users --dry-run' to preview cleaning every allowed user",
+		"Use --users alice,bob to limit admin mode to some of the allowed users",
+	})
+	return nil
+}
+
+// printUserUsage 打印一个用户的统计行
+func printUserUsage(name string, r userUsage) {
+	fmt.Printf("  %s %8d %10s %10s %10s %10s %s %10s\n",
+		name,
+		r.installs,
+		storage.FormatSize(r.chats),
+		storage.FormatSize(r.logs),
+		storage.FormatSize(r.cache),
+		storage.FormatSize(r.temp),
+		pterm.NewStyle(pterm.FgGreen).Sprintf("%10s", storage.FormatSize(r.cleanable())),
+		storage.FormatSize(r.total))
+}
+
+// scanUser 逐个安装统计用户的 Kiro 数据
+func scanUser(u accounts.User) userUsage {
+	restore := u.Activate()
+	defer restore()
+	defer storage.UseInstall("")
+
+	usage := userUsage{user: u}
+	for _, in := range ownedInstalls(u) {
+		if storage.UseInstall(in.Label) != nil {
+			continue
+		}
+		usage.installs++
+
+		fileScanner := scanner.NewFileScanner()
+		fileScanner.Scan()
+		stats, _ := fileScanner.GetStorageStats()
+		if stats == nil {
+			stats = &types.StorageStats{}
+		}
+		chatScanner := scanner.NewChatScanner()
+		chatScanner.ScanWorkspaces()
+		convStats, _ := chatScanner.GetConversationStats()
+		if convStats == nil {
+			convStats = &types.ConversationStats{}
+		}
+
+		usage.chats += convStats.TotalSize
+		usage.logs += stats.LogSize
+		usage.cache += stats.CacheSize
+		usage.temp += stats.TempSize
+		usage.total += stats.TotalSize + convStats.TotalSize
+	}
+	return usage
+}
+
+// runAdminClean 逐个以允许的用户身份运行 clean
+// 子进程使用该用户的 UID/GID 和主目录，备份、隔离目录和审计日志都由该用户自己写入
+func runAdminClean(cmd *cobra.Command, args []string) error {
+	if killKiro || restartKiro {
+		termUI.PrintError("--kill-kiro and --restart-kiro cannot be used with --all-users (they would stop every user's Kiro)")
+		return nil
+	}
+	if cleanInstall != "" {
+		termUI.PrintError("--install cannot be used with --all-users, labels differ between users")
+		return nil
+	}
+	users, ok := adminUsersToProcess()
+	if !ok {
+		return nil
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("cannot locate the kiro-cleaner binary: %v", err)
+	}
+
+	childArgs := userCleanArgs(os.Args[1:])
+	cleaned := 0
+	for _, u := range users {
+		if cleanUser(exe, childArgs, u) {
+			cleaned++
+		}
+	}
+	fmt.Println()
+	termUI.PrintSuccess(fmt.Sprintf("Processed %d of %d users", cleaned, len(users)))
+	return nil
+}
+
+// userCleanArgs 去掉管理员模式的参数，得到在每个用户的子进程中运行的命令行
+func userCleanArgs(args []string) []string {
+	var out []string
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i]; {
+		case arg == "--all-users", strings.HasPrefix(arg, "--all-users="), strings.HasPrefix(arg, "--users="):
+		case arg == "--users":
+			i++ // 跳过参数值
+		default:
+			out = append(out, arg)
+		}
+	}
+	return out
+}
+
+// cleanUser 以该用户的身份运行 clean，返回是否成功
+func cleanUser(exe string, args []string, u accounts.User) bool {
+	termUI.PrintSection(fmt.Sprintf("User %s (%s)", u.Name, u.Home))
+	child := u.Command(exe, args...)
+	child.Stdin = os.Stdin
+	child.Stdout = os.Stdout
+	child.Stderr = os.Stderr
+	if err := child.Run(); err != nil {
+		termUI.PrintWarning(fmt.Sprintf("Cleaning %s failed: %v", u.Name, err))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,298 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/archive"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/policy"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+)
+
+// chatsArchiveCmd chats archive command
+var chatsArchiveCmd = &cobra.Command{
+	Use:   "archive",
+	Short: "Compress old conversations into ~/.kiro-cleaner/archive",
+	Long: `Pack conversations older than --older-than days into one compressed archive
+per workspace under ~/.kiro-cleaner/archive, with an index of executionId, first
+message, model and times, then remove the originals. Pinned conversations and
+projects whose policy never deletes chats are skipped.`,
+	RunE: runChatsArchive,
+}
+
+// chatsArchiveListCmd chats archive list command
+var chatsArchiveListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List archived conversations",
+	RunE:  runChatsArchiveList,
+}
+
+// chatsArchiveExtractCmd chats archive extract command
+var chatsArchiveExtractCmd = &cobra.Command{
+	Use:   "extract <id|search>",
+	Short: "Restore an archived conversation to its workspace",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runChatsArchiveExtract,
+}
+
+var (
+	archiveOlderThan  int
+	cleanArchiveChats bool
+)
+
+func init() {
+	chatsArchiveCmd.AddCommand(chatsArchiveListCmd)
+	chatsArchiveCmd.AddCommand(chatsArchiveExtractCmd)
+	chatsCmd.AddCommand(chatsArchiveCmd)
+
+	chatsArchiveCmd.SetHelpFunc(customSubCmdHelpFunc)
+	chatsArchiveListCmd.SetHelpFunc(customSubCmdHelpFunc)
+	chatsArchiveExtractCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	chatsArchiveCmd.Flags().IntVar(&archiveOlderThan, "older-than", 30, "Archive conversations not modified for N days")
+	chatsArchiveCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview only, nothing is archived")
+	chatsArchiveCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+
+	cleanCmd.Flags().BoolVar(&cleanArchiveChats, "archive-chats", false, "Compress conversations into ~/.kiro-cleaner/archive instead of deleting them")
+}
+
+// archiveResult 归档结果
+type archiveResult struct {
+	archived   int
+	original   int64 // 归档前的大小
+	compressed int64 // 压缩包增加的大小
+	errors     []error
+}
+
+// archiveChatItems 按工作区压缩对话并删除原文件，返回未处理的其他清理项
+// 压缩失败的工作区保留原文件
+func archiveChatItems(items []cleanItem, chatScanner *scanner.ChatScanner, entry *audit.Entry) ([]cleanItem, *archiveResult) {
+	result := &archiveResult{}
+	byWorkspace := make(map[string][]cleanItem)
+	var rest []cleanItem
+	for _, item := range items {
+		if item.reason != "chat" {
+			rest = append(rest, item)
+			continue
+		}
+		ws := filepath.Base(filepath.Dir(item.path))
+		byWorkspace[ws] = append(byWorkspace[ws], item)
+	}
+
+	var workspaces []string
+	for ws := range byWorkspace {
+		workspaces = append(workspaces, ws)
+	}
+	sort.Strings(workspaces)
+
+	store := archive.NewStore(config.ArchiveDir())
+	guard := newKiroGuard(chatScanner)
+	now := time.Now()
+	for _, ws := range workspaces {
+		chats := byWorkspace[ws]
+		var paths []string
+		for _, item := range chats {
+			paths = append(paths, item.path)
+		}
+		entries, err := store.Archive(ws, paths, now)
+		if err != nil {
+			result.errors = append(result.errors, fmt.Errorf("archive %s: %v", ws, err))
+			entry.AddError(fmt.Errorf("archive failed, conversations kept: %v", err))
+			continue
+		}
+		if info, err := os.Stat(filepath.Join(store.Dir(), ws, entries[0].Archive)); err == nil {
+			result.compressed += info.Size()
+		}
+		for _, item := range chats {
+			if err := guard.Remove(item.path); err != nil {
+				result.errors = append(result.errors, err)
+				entry.AddError(err)
+				entry.AddFile(item.path, item.size, item.reason, audit.ActionFailed)
+				continue
+			}
+			result.archived++
+			result.original += item.size
+			entry.AddFile(item.path, item.size, item.reason, audit.ActionArchived)
+		}
+	}
+	return rest, result
+}
+
+// printArchiveResult 显示归档结果
+func printArchiveResult(result *archiveResult) {
+	if result.archived > 0 {
+		termUI.PrintSuccess(fmt.Sprintf("Archived %d conversations (%s → %s) in %s",
+			result.archived, storage.FormatSize(result.original), storage.FormatSize(result.compressed), config.ArchiveDir()))
+	}
+	for _, err := range result.errors {
+		termUI.PrintWarning(err.Error())
+	}
+}
+
+// runChatsArchive 压缩旧对话
+func runChatsArchive(cmd *cobra.Command, args []string) error {
+	chatScanner := scanner.NewChatScanner()
+	old, err := chatScanner.FindCleanableConversations(archiveOlderThan, 0)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Kiro data not found: %v", err))
+		return nil
+	}
+	pinned, err := loadPins(chatScanner)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Cannot read pinned conversations: %v", err))
+		return err
+	}
+	policies := loadWorkspacePolicies(chatScanner)
+
+	var items []cleanItem
+	var totalSize int64
+	counts := make(map[string]int)
+	skipped := 0
+	for _, chat := range old {
+		if pinned.Contains(chat.Path) || policies.PolicyForChat(chat.Path).Protects(policy.ScopeChats) {
+			skipped++
+			continue
+		}
+		items = append(items, cleanItem{path: chat.Path, size: chat.Size, reason: "chat"})
+		totalSize += chat.Size
+		counts[filepath.Base(filepath.Dir(chat.Path))]++
+	}
+	if skipped > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Kept %d pinned or policy-protected conversations", skipped))
+	}
+	if len(items) == 0 {
+		termUI.PrintSuccess(fmt.Sprintf("No conversations older than %d days", archiveOlderThan))
+		return nil
+	}
+
+	termUI.PrintSection("Archive")
+	var workspaces []string
+	for ws := range counts {
+		workspaces = append(workspaces, ws)
+	}
+	sort.Strings(workspaces)
+	for _, ws := range workspaces {
+		fmt.Printf("  %s %-14s %d conversations\n",
+			pterm.NewStyle(pterm.FgCyan).Sprint("●"),
+			truncateMiddle(ws, 14),
+			counts[ws])
+	}
+	fmt.Println()
+	termUI.PrintInfo(fmt.Sprintf("%d conversations (%s) will be compressed into %s", len(items), storage.FormatSize(totalSize), config.ArchiveDir()))
+
+	if dryRun {
+		termUI.PrintDryRunNotice()
+		return nil
+	}
+	if !yes && !config.LoadConfig().SkipConfirm {
+		if !termUI.Confirm("Archive these conversations?") {
+			pterm.Info.Println("Cancelled")
+			return nil
+		}
+	}
+
+	auditEntry := startAudit(cmd, args)
+	_, result := archiveChatItems(items, chatScanner, auditEntry)
+	recordAudit(auditEntry)
+	printArchiveResult(result)
+	termUI.PrintInfo("Run 'kiro-cleaner chats archive extract <id>' to bring a conversation back")
+	return nil
+}
+
+// runChatsArchiveList 列出归档的对话
+func runChatsArchiveList(cmd *cobra.Command, args []string) error {
+	entries, err := archive.NewStore(config.ArchiveDir()).List()
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to read archive: %v", err))
+		return err
+	}
+	if len(entries) == 0 {
+		termUI.PrintInfo("No archived conversations")
+		return nil
+	}
+
+	termUI.PrintSection("Archived Chats")
+	var totalSize int64
+	for _, e := range entries {
+		title := e.Title
+		if title == "" {
+			title = e.Name
+		}
+		when := e.EndTime
+		if when.IsZero() {
+			when = e.ArchivedAt
+		}
+		model := e.Model
+		if model == "" {
+			model = "-"
+		}
+		fmt.Printf("  %s  %-40s %-16s %s %10s\n",
+			pterm.NewStyle(pterm.FgCyan).Sprintf("%-8s", e.ID()),
+			truncateMiddle(title, 40),
+			truncateMiddle(model, 16),
+			pterm.NewStyle(pterm.FgGray).Sprint(when.Format("2006-01-02 15:04")),
+			storage.FormatSize(e.Size))
+		totalSize += e.Size
+	}
+	fmt.Println()
+	termUI.PrintInfo(fmt.Sprintf("%d conversations, %s before compression", len(entries), storage.FormatSize(totalSize)))
+	return nil
+}
+
+// runChatsArchiveExtract 将归档的对话恢复到原工作区
+func runChatsArchiveExtract(cmd *cobra.Command, args []string) error {
+	agentPath, err := scanner.NewChatScanner().FindKiroAgentPath()
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Kiro data not found: %v", err))
+		return nil
+	}
+	store := archive.NewStore(config.ArchiveDir())
+	entries, err := store.List()
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to read archive: %v", err))
+		return err
+	}
+
+	ref := strings.Join(args, " ")
+	matches := archive.Lookup(ref, entries)
+	switch {
+	case len(matches) == 0:
+		termUI.PrintError(fmt.Sprintf("No archived conversation matches %q", ref))
+		termUI.PrintInfo("Run 'kiro-cleaner chats archive list' to see archived conversations")
+		return nil
+	case len(matches) > 1:
+		termUI.PrintWarning(fmt.Sprintf("%d archived conversations match %q, use an ID:", len(matches), ref))
+		for i, e := range matches {
+			if i == maxMatches {
+				break
+			}
+			fmt.Printf("  %s  %s\n", pterm.NewStyle(pterm.FgCyan).Sprintf("%-8s", e.ID()), truncateMiddle(e.Title, 60))
+		}
+		return nil
+	}
+
+	e := matches[0]
+	auditEntry := startAudit(cmd, args)
+	defer recordAudit(auditEntry)
+	dest, err := store.Extract(e, filepath.Join(agentPath, e.Workspace))
+	if dest != "" {
+		auditEntry.AddFile(dest, e.Size, "archive", audit.ActionRestored)
+	}
+	if err != nil {
+		auditEntry.AddError(err)
+		termUI.PrintError(fmt.Sprintf("Failed to extract: %v", err))
+		return err
+	}
+	termUI.PrintSuccess(fmt.Sprintf("Restored %s to %s", e.ID(), dest))
+	termUI.PrintInfo("Reopen the workspace in Kiro to see the conversation again")
+	return nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/crashpad"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/history"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+)
+
+// auditCmd audit command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "List past clean runs from the audit log",
+	Long: `List clean runs recorded in ~/.kiro-cleaner/audit.jsonl, newest first.
+Each run records the command, flags, config hash, affected files, backup ID, errors and duration.`,
+	RunE: runAudit,
+}
+
+// auditShowCmd audit show command
+var auditShowCmd = &cobra.Command{
+	Use:   "show <run-id>",
+	Short: "Show the files and errors of a recorded run",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runAuditShow,
+}
+
+var (
+	auditCommand string
+	auditSince   int
+	auditPath    string
+	auditErrors  bool
+	auditLimit   int
+)
+
+func init() {
+	auditCmd.AddCommand(auditShowCmd)
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.SetHelpFunc(customSubCmdHelpFunc)
+	auditShowCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	auditCmd.Flags().StringVar(&auditCommand, "command", "", "Only show runs of this command (e.g. clean, \"sessions clean\")")
+	auditCmd.Flags().IntVar(&auditSince, "since", 0, "Only show runs from the last N days")
+	auditCmd.Flags().StringVar(&auditPath, "path", "", "Only show runs that touched a path containing this text")
+	auditCmd.Flags().BoolVar(&auditErrors, "errors", false, "Only show runs with errors")
+	auditCmd.Flags().IntVar(&auditLimit, "limit", 20, "Show at most N runs (0 = all)")
+	auditShowCmd.Flags().StringVar(&auditPath, "path", "", "Only show files whose path contains this text")
+}
+
+// startAudit 开始记录一次运行，命令名不含程序名（如 "sessions clean"）
+func startAudit(cmd *cobra.Command, args []string) *audit.Entry {
+	entry := audit.NewEntry(strings.TrimPrefix(cmd.CommandPath(), rootCmd.Name()+" "))
+	entry.Args = args
+	entry.Version = version
+	entry.ConfigHash = config.LoadConfig().Hash()
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		entry.Flags[f.Name] = f.Value.String()
+	})
+	return entry
+}
+
+// recordAudit 结束计时并写入审计日志，写入失败只提示不中断
+func recordAudit(entry *audit.Entry) {
+	entry.Finish()
+	if err := audit.NewLog(config.AuditLogPath()).Append(entry); err != nil {
+		termUI.PrintWarning(fmt.Sprintf("Failed to write audit log: %v", err))
+	}
+}
+
+// auditRemoval 按文件是否仍存在记录删除结果
+func auditRemoval(entry *audit.Entry, path string, size int64, reason string) {
+	action := audit.ActionDeleted
+	if _, err := os.Lstat(path); err == nil {
+		action = audit.ActionFailed
+	}
+	entry.AddFile(path, size, reason, action)
+}
+
+// auditHistory 记录文件编辑历史清理结果
+func auditHistory(entry *audit.Entry, actions []history.PruneAction, result *history.PruneResult) {
+	for _, action := range actions {
+		for _, v := range action.Drop {
+			auditRemoval(entry, v.Path, v.Size, "history")
+		}
+		if !action.RemoveDir {
+			entry.AddFile(filepath.Join(action.History.Dir, history.EntriesFileName), 0, "history", audit.ActionRewritten)
+		}
+	}
+	for _, err := range result.Errors {
+		entry.AddError(err)
+	}
+}
+
+// auditCrashReports 记录崩溃报告清理结果
+func auditCrashReports(entry *audit.Entry, plans [][]crashpad.Report, result *crashpad.DeleteResult) {
+	for _, plan := range plans {
+		for _, r := range plan {
+			auditRemoval(entry, r.Path, r.Size, "crash")
+		}
+	}
+	for _, err := range result.Errors {
+		entry.AddError(err)
+	}
+}
+
+// runAudit 列出审计记录
+func runAudit(cmd *cobra.Command, args []string) error {
+	filter := audit.Filter{
+		Command:    auditCommand,
+		Path:       auditPath,
+		ErrorsOnly: auditErrors,
+	}
+	if auditSince > 0 {
+		filter.Since = time.Now().AddDate(0, 0, -auditSince)
+	}
+
+	entries, err := audit.NewLog(config.AuditLogPath()).Query(filter)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to read audit log: %v", err))
+		return err
+	}
+	if len(entries) == 0 {
+		termUI.PrintSuccess("No matching runs in the audit log")
+		return nil
+	}
+
+	total := len(entries)
+	if auditLimit > 0 && len(entries) > auditLimit {
+		entries = entries[:auditLimit]
+	}
+
+	termUI.PrintSection("Audit Log")
+	for _, e := range entries {
+		status := pterm.NewStyle(pterm.FgGreen).Sprint("ok")
+		if len(e.Errors) > 0 {
+			status = pterm.NewStyle(pterm.FgRed).Sprintf("%d errors", len(e.Errors))
+		}
+		files := len(e.Files)
+		if auditPath != "" {
+			files = len(e.MatchingFiles(auditPath))
+		}
+		fmt.Printf("  %s  %s  %-16s %6d files %10s  %s\n",
+			pterm.NewStyle(pterm.FgCyan).Sprint(e.ID),
+			e.Time.Local().Format("2006-01-02 15:04"),
+			truncateMiddle(e.Command, 16),
+			files,
+			storage.FormatSize(e.FreedBytes),
+			status)
+	}
+
+	if total > len(entries) {
+		fmt.Println()
+		termUI.PrintInfo(fmt.Sprintf("Showing %d of %d runs, use --limit 0 to show all", len(entries), total))
+	}
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner audit show <run-id>' to see every file a run touched",
+		"Use --path to find the run that removed a specific file or conversation",
+	})
+	return nil
+}
+
+// runAuditShow 显示单条审计记录
+func runAuditShow(cmd *cobra.Command, args []string) error {
+	entry, err := audit.NewLog(config.AuditLogPath()).Find(args[0])
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Run not found: %s", args[0]))
+		return nil
+	}
+
+	termUI.PrintSection("Audit Record")
+	rows := []summaryRow{
+		{"Run", entry.ID},
+		{"Time", entry.Time.Local().Format("2006-01-02 15:04:05")},
+		{"Command", "kiro-cleaner " + strings.Join(append([]string{entry.Command}, entry.Args...), " ")},
+		{"Version", entry.Version},
+		{"Config", entry.ConfigHash},
+		{"Duration", entry.Duration().Round(time.Millisecond).String()},
+		{"Freed", storage.FormatSize(entry.FreedBytes)},
+	}
+	if entry.QuarantinedBytes > 0 {
+		rows = append(rows, summaryRow{"Quarantine", storage.FormatSize(entry.QuarantinedBytes)})
+	}
+	if entry.BackupID != "" {
+		rows = append(rows, summaryRow{"Backup", entry.BackupID})
+	}
+	if entry.Result != "" {
+		rows = append(rows, summaryRow{"Result", entry.Result})
+	}
+	for _, row := range rows {
+		name := pterm.NewStyle(pterm.FgMagenta, pterm.Bold).Sprintf("%-10s", row.name)
+		fmt.Printf("  %s %s\n", name, row.value)
+	}
+	if len(entry.Flags) > 0 {
+		var flags []string
+		for name, value := range entry.Flags {
+			flags = append(flags, fmt.Sprintf("--%s=%s", name, value))
+		}
+		sort.Strings(flags)
+		fmt.Printf("  %s %s\n", pterm.NewStyle(pterm.FgMagenta, pterm.Bold).Sprintf("%-10s", "Flags"), strings.Join(flags, " "))
+	}
+
+	files := entry.Files
+	if auditPath != "" {
+		files = entry.MatchingFiles(auditPath)
+	}
+	if len(files) > 0 {
+		termUI.PrintSection(fmt.Sprintf("Files (%d)", len(files)))
+		for _, f := range files {
+			color := pterm.FgRed
+			switch f.Action {
+			case audit.ActionQuarantined:
+				color = pterm.FgYellow
+			case audit.ActionRewritten:
+				color = pterm.FgBlue
+			case audit.ActionFailed:
+				color = pterm.FgGray
+			}
+			fmt.Printf("  %s %-11s %-8s %10s  %s\n",
+				pterm.NewStyle(color).Sprint("●"),
+				f.Action,
+				f.Reason,
+				storage.FormatSize(f.Size),
+				f.Path)
+		}
+	}
+
+	if len(entry.Errors) > 0 {
+		termUI.PrintSection(fmt.Sprintf("Errors (%d)", len(entry.Errors)))
+		for _, e := range entry.Errors {
+			fmt.Printf("  %s %s\n", pterm.NewStyle(pterm.FgRed).Sprint("✗"), e)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/auto"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/policy"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/preflight"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// autoCmd auto command
+var autoCmd = &cobra.Command{
+	Use:   "auto",
+	Short: "Clean only what crossed a threshold (for cron/systemd)",
+	Long: `Scan, compare with the thresholds in the "auto" section of the config
+and clean only the categories that crossed them, without prompting.
+
+Exit codes:
+  0  nothing to do
+  1  failed
+  2  cleaned
+  3  Kiro is running, nothing cleaned (set auto.allow_while_running to override),
+     or every file over a threshold was held open by Kiro`,
+	RunE: runAuto,
+}
+
+var (
+	autoAllowRunning bool
+	autoProfile      string
+)
+
+func init() {
+	rootCmd.AddCommand(autoCmd)
+	autoCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	autoCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be cleaned, exit 0")
+	autoCmd.Flags().BoolVar(&autoAllowRunning, "allow-running", false, "Clean while Kiro is running, skipping open files")
+	autoCmd.Flags().StringVar(&autoProfile, "profile", "", "Use thresholds from auto.profiles.<name>")
+}
+
+// autoFileCategories 文件类型对应的自动清理类别
+var autoFileCategories = map[types.FileType]string{
+	types.TypeCache:  auto.CategoryCache,
+	types.TypeOrphan: auto.CategoryCache, // 孤立的快照和差异内容按缓存的阈值清理
+	types.TypeLog:    auto.CategoryLogs,
+	types.TypeIndex:  auto.CategoryIndex,
+	types.TypeTemp:   auto.CategoryTemp,
+}
+
+// autoThresholds 解析配置中的阈值
+func autoThresholds(cfg config.AutoConfig) (auto.Thresholds, error) {
+	var t auto.Thresholds
+	sizes := []struct {
+		name  string
+		value string
+		dest  *int64
+	}{
+		{"max_total", cfg.MaxTotal, &t.MaxTotal},
+		{"max_cache", cfg.MaxCache, &t.MaxCache},
+		{"max_logs", cfg.MaxLogs, &t.MaxLogs},
+		{"max_index", cfg.MaxIndex, &t.MaxIndex},
+		{"max_temp", cfg.MaxTemp, &t.MaxTemp},
+	}
+	for _, s := range sizes {
+		size, err := preflight.ParseSize(s.value)
+		if err != nil {
+			return t, fmt.Errorf("auto.%s: %v", s.name, err)
+		}
+		*s.dest = size
+	}
+	if cfg.ChatMaxAgeDays > 0 {
+		t.ChatMaxAge = time.Duration(cfg.ChatMaxAgeDays) * 24 * time.Hour
+	}
+	return t, nil
+}
+
+// autoProfileNames 返回配置中的 auto 阈值组名称
+func autoProfileNames(cfg config.AutoConfig) string {
+	if len(cfg.Profiles) == 0 {
+		return "none"
+	}
+	var names []string
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// runAuto 按阈值自动清理
+func runAuto(cmd *cobra.Command, args []string) error {
+	// 每次实际运行都写入审计日志（包括无需清理的情况），供 schedule status 查看
+	auditEntry := startAudit(cmd, args)
+	if !dryRun {
+		defer func() {
+			auditEntry.Result = auto.ResultName(exitCode)
+			recordAudit(auditEntry)
+		}()
+	}
+
+	cfg := config.LoadConfig()
+	autoCfg, err := cfg.Auto.AutoProfile(autoProfile)
+	if err != nil {
+		auditEntry.AddError(err)
+		termUI.PrintError(fmt.Sprintf("Invalid auto profile: %v", err))
+		exitCode = auto.ExitFailed
+		return nil
+	}
+	thresholds, err := autoThresholds(autoCfg)
+	if err != nil {
+		auditEntry.AddError(err)
+		termUI.PrintError(fmt.Sprintf("Invalid auto config: %v", err))
+		exitCode = auto.ExitFailed
+		return nil
+	}
+	if thresholds.IsEmpty() {
+		termUI.PrintWarning("No auto thresholds configured")
+		termUI.PrintInfo(fmt.Sprintf("Set the \"auto\" section in %s", config.ConfigPath()))
+		return nil
+	}
+
+	// Kiro 运行时默认不清理；允许时跳过被打开的文件
+	running, _, _ := utils.IsKiroRunning()
+	allowRunning := autoAllowRunning || autoCfg.AllowWhileRunning
+	if running && !allowRunning && !dryRun {
+		termUI.PrintWarning("Kiro is running, nothing cleaned")
+		termUI.PrintInfo("Set auto.allow_while_running or use --allow-running to clean anyway")
+		exitCode = auto.ExitKiroRunning
+		return nil
+	}
+	var locked lockedFiles
+	if running {
+		kiroRoots, _ := storage.NewStorageDetector().FindKiroPaths()
+		locked, _ = detectLockedFiles(kiroRoots)
+	}
+
+	// 扫描并统计各类别占用
+	fileScanner := scanner.NewFileScanner()
+	files, err := fileScanner.Scan()
+	if err != nil {
+		auditEntry.AddError(err)
+		termUI.PrintError(fmt.Sprintf("Scan failed: %v", err))
+		exitCode = auto.ExitFailed
+		return nil
+	}
+	stats, _ := fileScanner.GetStorageStats()
+	chatScanner := scanner.NewChatScanner()
+	chatScanner.ScanWorkspacesWithProgress(nil)
+	convStats, _ := chatScanner.GetConversationStats()
+
+	usage := auto.Usage{Sizes: make(map[string]int64)}
+	if stats != nil {
+		usage.Total += stats.TotalSize
+	}
+	if convStats != nil {
+		usage.Total += convStats.TotalSize
+		usage.Sizes[auto.CategoryChats] = convStats.TotalSize
+	}
+	for _, file := range files {
+		if category, ok := autoFileCategories[file.FileType]; ok {
+			usage.Sizes[category] += file.Size
+		}
+	}
+	var oldChats []types.CleanableConversation
+	exportChats := make(map[string]bool)
+	if autoCfg.ChatMaxAgeDays > 0 {
+		// 固定的和项目策略保护的对话不计入也不清理；固定列表无法读取时不清理对话
+		policies := loadWorkspacePolicies(chatScanner)
+		pinned, pinErr := loadPins(chatScanner)
+		if pinErr != nil {
+			termUI.PrintWarning(fmt.Sprintf("Keeping all conversations, cannot read pinned list: %v", pinErr))
+		}
+		var candidates []types.CleanableConversation
+		if pinErr == nil {
+			candidates, _ = chatScanner.FindCleanableConversations(autoCfg.ChatMaxAgeDays, 0)
+		}
+		for _, chat := range candidates {
+			if pinned.Contains(chat.Path) {
+				continue
+			}
+			p := policies.PolicyForChat(chat.Path)
+			if p.Protects(policy.ScopeChats) {
+				continue
+			}
+			exportChats[chat.Path] = p != nil && p.ExportBeforeDelete
+			oldChats = append(oldChats, chat)
+		}
+		usage.OldChats = len(oldChats)
+		for _, chat := range oldChats {
+			usage.OldChatBytes += chat.Size
+		}
+	}
+
+	triggers := auto.Evaluate(usage, thresholds)
+	if len(triggers) == 0 {
+		termUI.PrintSuccess(fmt.Sprintf("All thresholds OK (total %s)", storage.FormatSize(usage.Total)))
+		return nil
+	}
+
+	termUI.PrintSection("Thresholds Exceeded")
+	for _, t := range triggers {
+		if t.Name == "chat_max_age" {
+			fmt.Printf("  %-14s %d conversations older than %d days (%s)\n",
+				t.Name, t.Value, autoCfg.ChatMaxAgeDays, storage.FormatSize(usage.OldChatBytes))
+			continue
+		}
+		fmt.Printf("  %-14s %10s > %s\n", t.Name, storage.FormatSize(t.Value), storage.FormatSize(t.Limit))
+	}
+
+	// 收集触发类别中的文件
+	categories := auto.Categories(triggers)
+	selected := make(map[string]bool)
+	for _, c := range categories {
+		selected[c] = true
+	}
+	var toClean []cleanItem
+	var skippedLocked []string
+	for _, file := range files {
+		category, ok := autoFileCategories[file.FileType]
+		if !ok || !selected[category] {
+			continue
+		}
+		toClean = append(toClean, cleanItem{path: file.Path, size: file.Size, reason: category})
+	}
+	if selected[auto.CategoryChats] {
+		for _, chat := range oldChats {
+			toClean = append(toClean, cleanItem{path: chat.Path, size: chat.Size, reason: auto.CategoryChats, export: exportChats[chat.Path]})
+		}
+	}
+
+	var totalSize int64
+	for _, item := range toClean {
+		totalSize += item.size
+	}
+	fmt.Println()
+	termUI.PrintInfo(fmt.Sprintf("Cleaning %s: %d files, %s", strings.Join(categories, ", "), len(toClean), storage.FormatSize(totalSize)))
+
+	if dryRun {
+		termUI.PrintDryRunNotice()
+		return nil
+	}
+
+	toClean, _, exportErr := exportPolicyChats(toClean)
+	if exportErr != nil {
+		termUI.PrintWarning(fmt.Sprintf("Conversations kept, export failed: %v", exportErr))
+		auditEntry.AddError(fmt.Errorf("conversation export failed, conversations kept: %v", exportErr))
+	}
+
+	guard := newKiroGuard(chatScanner)
+	var cleaned, errors int
+	var cleanedSize int64
+	for _, item := range toClean {
+		if locked.holds(item.path) {
+			skippedLocked = append(skippedLocked, item.path)
+			continue
+		}
+		if err := guard.Remove(item.path); err != nil {
+			errors++
+			auditEntry.AddError(err)
+			auditEntry.AddFile(item.path, item.size, item.reason, audit.ActionFailed)
+			continue
+		}
+		cleaned++
+		cleanedSize += item.size
+		auditEntry.AddFile(item.path, item.size, item.reason, audit.ActionDeleted)
+	}
+
+	if len(skippedLocked) > 0 {
+		locked.printLockedSummary(skippedLocked)
+	}
+	termUI.PrintCleanResult(cleaned, storage.FormatSize(cleanedSize), errors)
+
+	// 阈值已超出但文件都被 Kiro 占用时不算"无需清理"，否则定时任务会误以为空间已释放
+	switch {
+	case errors > 0:
+		exitCode = auto.ExitFailed
+	case cleaned > 0:
+		exitCode = auto.ExitCleaned
+	case len(skippedLocked) > 0:
+		exitCode = auto.ExitKiroRunning
+	}
+	return nil
+}
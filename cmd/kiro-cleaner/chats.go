@@ -0,0 +1,393 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/pins"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/policy"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/tokens"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// chatsCmd chats command
+var chatsCmd = &cobra.Command{
+	Use:   "chats",
+	Short: "List conversations and manage pinned ones",
+	Long: `List the newest Kiro conversations (.chat files) with their ID.
+Pinned conversations are never deleted by clean, auto or the cleanup engine.`,
+	RunE: runChats,
+}
+
+// chatsPinCmd chats pin command
+var chatsPinCmd = &cobra.Command{
+	Use:   "pin <id|path|search>",
+	Short: "Pin a conversation so no clean deletes it",
+	Long: `Pin a conversation by executionId (or a prefix of it), file name, path,
+or by searching the first message. Pins follow the file if Kiro moves it.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: runChatsPin,
+}
+
+// chatsUnpinCmd chats unpin command
+var chatsUnpinCmd = &cobra.Command{
+	Use:   "unpin <id|path|search>",
+	Short: "Unpin a conversation",
+	Args:  cobra.MinimumNArgs(1),
+	RunE:  runChatsUnpin,
+}
+
+// chatsPinnedCmd chats pinned command
+var chatsPinnedCmd = &cobra.Command{
+	Use:   "pinned",
+	Short: "List pinned conversations",
+	RunE:  runChatsPinned,
+}
+
+var chatsLimit int
+
+func init() {
+	chatsCmd.AddCommand(chatsPinCmd)
+	chatsCmd.AddCommand(chatsUnpinCmd)
+	chatsCmd.AddCommand(chatsPinnedCmd)
+	rootCmd.AddCommand(chatsCmd)
+
+	chatsCmd.SetHelpFunc(customSubCmdHelpFunc)
+	chatsPinCmd.SetHelpFunc(customSubCmdHelpFunc)
+	chatsUnpinCmd.SetHelpFunc(customSubCmdHelpFunc)
+	chatsPinnedCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	chatsCmd.Flags().IntVar(&chatsLimit, "limit", 20, "Number of conversations to list (0=all)")
+}
+
+// chatReasonPolicy 对话超过项目策略的 chat_retention_days
+const chatReasonPolicy = "project_retention"
+
+// selectedChat 被选中清理的对话及其适用的项目策略
+type selectedChat struct {
+	chat   types.CleanableConversation
+	policy *policy.Policy
+}
+
+// chatReasonText 返回对话被选中的原因描述
+func chatReasonText(c selectedChat, keepLast, days int) string {
+	switch c.chat.Reason {
+	case chatReasonPolicy:
+		return fmt.Sprintf("past project retention (%dd)", c.policy.ChatRetentionDays)
+	case types.ChatReasonBeyondLast:
+		text := fmt.Sprintf("#%d in workspace, beyond newest %d", c.chat.Rank, keepLast)
+		if days > 0 {
+			text += fmt.Sprintf(", older than %dd", days)
+		}
+		return text
+	}
+	if days > 0 {
+		return fmt.Sprintf("older than %dd", days)
+	}
+	return "no retention rule"
+}
+
+// printChatReasons 汇总对话被选中的原因，--verbose 时逐个列出
+func printChatReasons(chats []selectedChat, keepLast, days int) {
+	if len(chats) == 0 {
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, c := range chats {
+		counts[c.chat.Reason]++
+	}
+	var parts []string
+	if n := counts[types.ChatReasonBeyondLast]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d beyond the newest %d of their workspace", n, keepLast))
+	}
+	if n := counts[chatReasonPolicy]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d past project retention", n))
+	}
+	if n := len(chats) - counts[types.ChatReasonBeyondLast] - counts[chatReasonPolicy]; n > 0 {
+		if days > 0 {
+			parts = append(parts, fmt.Sprintf("%d older than %d days", n, days))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d with no retention rule", n))
+		}
+	}
+	termUI.PrintInfo("Conversations: " + strings.Join(parts, ", "))
+
+	if !verbose {
+		return
+	}
+	for _, c := range chats {
+		name := truncateMiddle(c.chat.Workspace, 12) + "/" + filepath.Base(c.chat.Path)
+		fmt.Printf("  %s %-40s %10s  %s\n",
+			pterm.NewStyle(pterm.FgCyan).Sprint("●"),
+			truncateMiddle(name, 40),
+			storage.FormatSize(c.chat.Size),
+			pterm.NewStyle(pterm.FgGray).Sprint(chatReasonText(c, keepLast, days)))
+	}
+}
+
+// maxMatches 有歧义时最多列出的对话数量
+const maxMatches = 10
+
+// listChats 读取所有对话文件的 executionId 和标题，新的在前
+func listChats(chatScanner *scanner.ChatScanner) ([]pins.Chat, error) {
+	all, err := chatScanner.FindConversations(types.ChatRetention{})
+	if err != nil {
+		return nil, err
+	}
+	chats := make([]pins.Chat, 0, len(all))
+	for _, c := range all {
+		chat, err := pins.ReadChat(c.Path)
+		if err != nil {
+			chat = pins.Chat{Path: c.Path, Size: c.Size, ModTime: c.ModTime}
+		}
+		chats = append(chats, chat)
+	}
+	sort.SliceStable(chats, func(i, j int) bool { return chats[i].ModTime.After(chats[j].ModTime) })
+	return chats, nil
+}
+
+// loadPins 读取固定列表，并让固定项跟随被 Kiro 移动的对话
+// 读取失败时返回错误，调用方应保留所有对话
+func loadPins(chatScanner *scanner.ChatScanner) (*pins.Registry, error) {
+	registry, err := pins.Load(config.PinsPath())
+	if err != nil {
+		return nil, err
+	}
+	if len(registry.Missing()) > 0 {
+		if chats, err := listChats(chatScanner); err == nil && registry.Follow(chats) > 0 {
+			if err := registry.Save(); err != nil {
+				termUI.PrintWarning(fmt.Sprintf("Failed to update pinned paths: %v", err))
+			}
+		}
+	}
+	return registry, nil
+}
+
+// printChat 打印一行对话信息
+func printChat(c pins.Chat, pinned bool) {
+	mark := "  "
+	if pinned {
+		mark = pterm.NewStyle(pterm.FgYellow, pterm.Bold).Sprint("📌")
+	}
+	title := c.Title
+	if title == "" {
+		title = filepath.Base(c.Path)
+	}
+	age := "missing"
+	if !c.ModTime.IsZero() {
+		age = formatAge(time.Since(c.ModTime))
+	}
+	tokenCount := "-"
+	if c.Tokens > 0 {
+		tokenCount = "~" + tokens.Format(c.Tokens) + " tok"
+	}
+	fmt.Printf("  %s %s  %-44s %10s %12s  %s\n",
+		mark,
+		pterm.NewStyle(pterm.FgCyan).Sprintf("%-8s", c.ShortID()),
+		truncateMiddle(title, 44),
+		storage.FormatSize(c.Size),
+		tokenCount,
+		pterm.NewStyle(pterm.FgGray).Sprint(age))
+}
+
+// printWorkspaceTokens 列出估算 token 最多的工作区，能识别项目时显示项目根目录
+func printWorkspaceTokens(workspaces []types.WorkspaceStats, limit int) {
+	ranked := make([]types.WorkspaceStats, 0, len(workspaces))
+	for _, ws := range workspaces {
+		if ws.TotalTokens > 0 {
+			ranked = append(ranked, ws)
+		}
+	}
+	if len(ranked) == 0 {
+		return
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		return ranked[i].TotalTokens > ranked[j].TotalTokens
+	})
+	if limit > 0 && len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	policies := loadWorkspacePolicies(scanner.NewChatScanner())
+	termUI.PrintSection("Tokens by Workspace")
+	for _, ws := range ranked {
+		name := ws.WorkspaceID
+		if w := policies.ForChatDir(ws.Path); w != nil {
+			name = w.Root
+		}
+		fmt.Printf("  %-48s %10s %6d chats\n",
+			truncateMiddle(name, 48),
+			"~"+tokens.Format(ws.TotalTokens),
+			ws.ConversationCount)
+	}
+}
+
+// resolveChat 查找唯一匹配的对话，没有或有多个匹配时打印提示并返回 false
+func resolveChat(ref string, chats []pins.Chat, registry *pins.Registry) (pins.Chat, bool) {
+	matches := pins.Lookup(ref, chats)
+	switch len(matches) {
+	case 0:
+		termUI.PrintError(fmt.Sprintf("No conversation matches %q", ref))
+		termUI.PrintInfo("Run 'kiro-cleaner chats' to list conversations and their IDs")
+		return pins.Chat{}, false
+	case 1:
+		return matches[0], true
+	}
+
+	termUI.PrintWarning(fmt.Sprintf("%d conversations match %q, use an ID or path:", len(matches), ref))
+	for i, c := range matches {
+		if i == maxMatches {
+			fmt.Printf("  %s\n", pterm.NewStyle(pterm.FgGray).Sprintf("... and %d more", len(matches)-maxMatches))
+			break
+		}
+		printChat(c, registry.IsPinned(c))
+	}
+	return pins.Chat{}, false
+}
+
+// runChats 列出最新的对话
+func runChats(cmd *cobra.Command, args []string) error {
+	chatScanner := scanner.NewChatScanner()
+	chats, err := listChats(chatScanner)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Kiro data not found: %v", err))
+		return nil
+	}
+	if len(chats) == 0 {
+		termUI.PrintSuccess("No conversations found")
+		return nil
+	}
+	registry, err := loadPins(chatScanner)
+	if err != nil {
+		termUI.PrintWarning(fmt.Sprintf("Cannot read pinned conversations: %v", err))
+	}
+
+	termUI.PrintSection("Conversations")
+	shown := chats
+	if chatsLimit > 0 && len(shown) > chatsLimit {
+		shown = shown[:chatsLimit]
+	}
+	for _, c := range shown {
+		printChat(c, registry != nil && registry.IsPinned(c))
+	}
+	if len(shown) < len(chats) {
+		fmt.Printf("  %s\n", pterm.NewStyle(pterm.FgGray).Sprintf("... %d older conversations (use --limit 0 to list all)", len(chats)-len(shown)))
+	}
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner chats pin <id|search>' to protect a conversation from every clean",
+		"Run 'kiro-cleaner chats pinned' to list pinned conversations",
+	})
+	return nil
+}
+
+// runChatsPin 固定对话
+func runChatsPin(cmd *cobra.Command, args []string) error {
+	chatScanner := scanner.NewChatScanner()
+	chats, err := listChats(chatScanner)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Kiro data not found: %v", err))
+		return nil
+	}
+	registry, err := loadPins(chatScanner)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Cannot read pinned conversations: %v", err))
+		return err
+	}
+
+	chat, ok := resolveChat(strings.Join(args, " "), chats, registry)
+	if !ok {
+		return nil
+	}
+	if !registry.Add(chat, time.Now()) {
+		termUI.PrintInfo(fmt.Sprintf("Already pinned: %s", chat.ShortID()))
+		return nil
+	}
+	if err := registry.Save(); err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to save pins: %v", err))
+		return err
+	}
+	termUI.PrintSuccess(fmt.Sprintf("Pinned %s %s", chat.ShortID(), truncateMiddle(chat.Title, 50)))
+	return nil
+}
+
+// runChatsUnpin 取消固定对话
+func runChatsUnpin(cmd *cobra.Command, args []string) error {
+	chatScanner := scanner.NewChatScanner()
+	registry, err := loadPins(chatScanner)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Cannot read pinned conversations: %v", err))
+		return err
+	}
+
+	// 只在固定的对话中查找（文件已被删除的固定项也可以取消）
+	var pinned []pins.Chat
+	for _, p := range registry.Pins() {
+		pinned = append(pinned, pinnedChat(p))
+	}
+	chat, ok := resolveChat(strings.Join(args, " "), pinned, registry)
+	if !ok {
+		return nil
+	}
+	if !registry.Remove(chat) {
+		termUI.PrintInfo(fmt.Sprintf("Not pinned: %s", chat.ShortID()))
+		return nil
+	}
+	if err := registry.Save(); err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to save pins: %v", err))
+		return err
+	}
+	termUI.PrintSuccess(fmt.Sprintf("Unpinned %s %s", chat.ShortID(), truncateMiddle(chat.Title, 50)))
+	return nil
+}
+
+// runChatsPinned 列出固定的对话
+func runChatsPinned(cmd *cobra.Command, args []string) error {
+	registry, err := loadPins(scanner.NewChatScanner())
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Cannot read pinned conversations: %v", err))
+		return err
+	}
+	if registry.Len() == 0 {
+		termUI.PrintInfo("No pinned conversations")
+		termUI.PrintInfo("Run 'kiro-cleaner chats pin <id|search>' to pin one")
+		return nil
+	}
+
+	termUI.PrintSection("Pinned")
+	missing := 0
+	for _, p := range registry.Pins() {
+		c := pinnedChat(p)
+		if c.ModTime.IsZero() {
+			missing++
+		}
+		printChat(c, true)
+	}
+	if missing > 0 {
+		fmt.Println()
+		termUI.PrintWarning(fmt.Sprintf("%d pinned conversations no longer exist; unpin them with 'kiro-cleaner chats unpin <id>'", missing))
+	}
+	return nil
+}
+
+// pinnedChat 返回固定项对应的对话信息，文件不存在时只有固定时记录的信息
+func pinnedChat(p pins.Pin) pins.Chat {
+	if c, err := pins.ReadChat(p.Path); err == nil {
+		return c
+	}
+	c := pins.Chat{Path: p.Path, ExecutionID: p.ExecutionID, Title: p.Title}
+	if info, err := os.Stat(p.Path); err == nil {
+		c.Size = info.Size()
+		c.ModTime = info.ModTime()
+	}
+	return c
+}
@@ -7,13 +7,21 @@ import (
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
 	"github.com/pterm/pterm"
 	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/crashpad"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/history"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/policy"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/profile"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/tokens"
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/ui"
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
 	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
@@ -82,12 +90,19 @@ func init() {
 	cleanCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview only, no deletion")
 	cleanCmd.Flags().BoolVarP(&force, "force", "f", false, "Skip confirmation")
 	cleanCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation (same as -f)")
+	cleanCmd.Flags().BoolVar(&withBackup, "backup", false, "Back up files before deleting (checks free disk space first)")
 	cleanCmd.Flags().BoolVar(&killKiro, "kill-kiro", false, "Automatically stop Kiro before cleaning")
+	cleanCmd.Flags().BoolVar(&restartKiro, "restart-kiro", false, "Stop Kiro before cleaning and relaunch it afterwards")
+	cleanCmd.Flags().IntVar(&killTimeout, "kill-timeout", 0, "Seconds to wait for Kiro to exit before killing it (default: kill_timeout)")
 	cleanCmd.Flags().BoolVar(&keepLogs, "keep-logs", false, "Keep log files")
 	cleanCmd.Flags().BoolVar(&keepCache, "keep-cache", false, "Keep cache files")
 	cleanCmd.Flags().BoolVar(&keepChats, "keep-chats", false, "Keep chat conversations")
 	cleanCmd.Flags().BoolVar(&keepIndex, "keep-index", false, "Keep code index")
 	cleanCmd.Flags().IntVar(&keepRecent, "keep-recent", 0, "Keep files modified within N days (0=keep none)")
+	cleanCmd.Flags().IntVar(&keepLastChats, "keep-last-chats", 0, "Keep the newest N conversations of each workspace (combines with --keep-recent)")
+	cleanCmd.Flags().IntVar(&historyKeepLast, "history-keep-last", 0, "Keep the last N versions of each file in edit history")
+	cleanCmd.Flags().IntVar(&historyOlderThan, "history-older-than", 0, "Drop edit history versions older than N days")
+	cleanCmd.Flags().BoolVar(&historyDropMissing, "history-drop-missing", false, "Drop edit history of files that no longer exist")
 	
 	// Install command flags
 	installCmd.Flags().StringVar(&installPath, "path", defaultInstallPath(), "Installation path")
@@ -98,12 +113,20 @@ var (
 	force       bool
 	yes         bool
 	killKiro    bool
+	restartKiro bool
+	killTimeout int
 	keepLogs    bool
 	keepCache   bool
 	keepChats   bool
 	keepIndex   bool
 	keepRecent  int
 	installPath string
+
+	keepLastChats int
+
+	historyKeepLast    int
+	historyOlderThan   int
+	historyDropMissing bool
 )
 
 // defaultInstallPath 返回默认安装路径
@@ -116,6 +139,31 @@ func defaultInstallPath() string {
 
 // runScan 扫描存储
 func runScan(cmd *cobra.Command, args []string) error {
+	// --all-users：管理员模式，统计每个允许的用户
+	if scanAllUsers {
+		return runAdminScan(cmd, args)
+	}
+	
+	// --install：只扫描指定的安装
+	if !selectInstall(scanInstall) {
+		return nil
+	}
+	
+	// --secrets：只查找敏感信息
+	if scanSecrets || scanRedact {
+		return runSecretScan(cmd, args)
+	}
+	
+	// --profile：只统计该预设会清理的内容
+	var prof *profile.Profile
+	if scanProfile != "" {
+		p, ok := loadProfile(scanProfile, config.LoadConfig())
+		if !ok {
+			return nil
+		}
+		prof = p
+	}
+	
 	// 创建进度展示
 	progressDisplay := ui.NewProgressDisplay()
 	progressDisplay.Start()
@@ -162,13 +210,37 @@ func runScan(cmd *cobra.Command, args []string) error {
 	termUI.PrintSuccess("Scan complete")
 	fmt.Println()
 	
+	// 上次清理时间来自审计日志；每次扫描记录一个快照供 trend 命令使用
+	stats.LastCleanup = lastCleanup()
+	recordSnapshot(stats, convStats, files)
+	
 	// 显示结果
-	displayScanResult(stats, convStats, files)
+	var sel *scanSelection
+	if prof != nil {
+		sel = newScanSelection(prof, chatScanner)
+	}
+	displayScanResult(stats, convStats, files, sel)
+	displayWorkspacePolicies(loadWorkspacePolicies(chatScanner))
+	displayInstalls()
 	return nil
 }
 
 // runClean 清理数据
 func runClean(cmd *cobra.Command, args []string) error {
+	// --all-users：管理员模式，逐个清理允许的用户
+	if cleanAllUsers {
+		return runAdminClean(cmd, args)
+	}
+	
+	// --install：只清理指定的安装
+	if !selectInstall(cleanInstall) {
+		return nil
+	}
+	return cleanKiroData(cmd, args)
+}
+
+// cleanKiroData 清理当前用户（HOME）下发现的 Kiro 数据
+func cleanKiroData(cmd *cobra.Command, args []string) (err error) {
 	// 加载全局配置
 	cfg := config.LoadConfig()
 	
@@ -191,34 +263,101 @@ func runClean(cmd *cobra.Command, args []string) error {
 	if !cmd.Flags().Changed("keep-recent") && cfg.KeepRecent > 0 {
 		keepRecent = cfg.KeepRecent
 	}
+	if !cmd.Flags().Changed("keep-last-chats") && cfg.KeepLastChats > 0 {
+		keepLastChats = cfg.KeepLastChats
+	}
+	
+	// --profile：预设决定清理的类别和保留天数，显式的命令行参数仍然优先
+	var prof *profile.Profile
+	if cleanProfile != "" {
+		p, ok := loadProfile(cleanProfile, cfg)
+		if !ok {
+			return nil
+		}
+		prof = p
+		applyProfile(cmd, prof)
+		termUI.PrintInfo(fmt.Sprintf("Profile %s: %s", prof.Name, prof.Summary()))
+	}
+	includes := func(category string) bool {
+		return prof == nil || prof.Includes(category)
+	}
 	
+	auditEntry := startAudit(cmd, args)
+	// 失败或被拒绝的运行也写入审计日志；预览、取消和无需清理时没有可记录的内容
+	defer func() {
+		if err != nil {
+			auditEntry.AddError(err)
+		}
+		if err != nil || len(auditEntry.Files) > 0 || len(auditEntry.Errors) > 0 {
+			recordAudit(auditEntry)
+		}
+	}()
 	spinner := termUI.Spinner("Scanning for cleanable files...")
 
 	// 检测 Kiro 是否运行
 	running, _, _ := utils.IsKiroRunning()
 	
-	// 如果 Kiro 正在运行且设置了 --kill-kiro，先停止 Kiro
-	if running && killKiro && !dryRun {
+	// 如果 Kiro 正在运行且设置了 --kill-kiro 或 --restart-kiro，先停止 Kiro
+	if running && (killKiro || restartKiro) && !dryRun {
+		timeout := cfg.KillTimeout
+		if cmd.Flags().Changed("kill-timeout") {
+			timeout = killTimeout
+		}
+		
+		// 重启需要先记录主进程的命令行和工作目录
+		var launch *utils.LaunchInfo
+		if restartKiro {
+			_, processes, _ := utils.IsKiroRunning()
+			info, err := utils.CaptureLaunchInfo(processes)
+			if err != nil {
+				spinner.Fail("Cannot restart Kiro")
+				termUI.PrintWarning(fmt.Sprintf("Could not record Kiro's command line: %v", err))
+				termUI.PrintInfo("Use --kill-kiro instead and start Kiro manually")
+				auditEntry.AddError(fmt.Errorf("cannot restart Kiro: %v", err))
+				return nil
+			}
+			launch = info
+		}
+		
 		spinner.UpdateText("Stopping Kiro...")
-		if err := utils.StopKiro(true); err != nil {
+		stopResult, err := utils.StopKiroWithTimeout(time.Duration(timeout) * time.Second)
+		
+		// 清理结束（包括取消、失败或无需清理）后重新启动 Kiro，停止失败时部分进程可能已经退出
+		if launch != nil {
+			defer relaunchKiro(launch, stopResult.StartedAt)
+		}
+		
+		if err != nil {
 			spinner.Fail("Failed to stop Kiro")
 			termUI.PrintWarning(fmt.Sprintf("Could not stop Kiro: %v", err))
+			auditEntry.AddError(fmt.Errorf("failed to stop Kiro: %v", err))
+			// --restart-kiro 承诺清理时 Kiro 已停止，停止失败时不继续清理
+			if launch != nil {
+				termUI.PrintInfo("Nothing was cleaned; close Kiro manually and run clean again")
+				return nil
+			}
 			termUI.PrintInfo("Try closing Kiro manually or use --force to continue anyway")
 			if !skipConfirm {
 				return nil
 			}
 		} else {
-			// 等待 Kiro 完全退出
-			if utils.WaitForKiroExit(5 * time.Second) {
-				running = false
-				spinner.UpdateText("Scanning for cleanable files...")
-			} else {
-				spinner.Fail("Kiro did not exit in time")
-				termUI.PrintWarning("Kiro is still running, some files may be locked")
+			running = false
+			if stopResult.Killed > 0 {
+				termUI.PrintWarning(fmt.Sprintf("Kiro did not exit within %ds, killed %d processes", timeout, stopResult.Killed))
 			}
+			spinner.UpdateText("Scanning for cleanable files...")
 		}
 	}
 	
+	// Kiro 运行时精确检测被打开的文件（Linux），清理时跳过这些文件
+	var locked lockedFiles
+	preciseLocks := false
+	var skippedLocked []string
+	if running {
+		kiroRoots, _ := storage.NewStorageDetector().FindKiroPaths()
+		locked, preciseLocks = detectLockedFiles(kiroRoots)
+	}
+	
 	// 扫描文件
 	fileScanner := scanner.NewFileScanner()
 	files, _ := fileScanner.Scan()
@@ -226,27 +365,52 @@ func runClean(cmd *cobra.Command, args []string) error {
 	// 扫描会话
 	chatScanner := scanner.NewChatScanner()
 	
+	// 项目根目录下的 .kiro-cleaner 策略叠加在全局配置之上
+	policies := loadWorkspacePolicies(chatScanner)
+	warnPolicyErrors(policies)
+	skippedPolicy := 0
+	
 	// 收集要清理的文件
 	var toClean []cleanItem
 	var totalSize int64
 	
-	// 计算保留截止时间
-	var keepCutoff time.Time
-	if keepRecent > 0 {
-		keepCutoff = time.Now().AddDate(0, 0, -keepRecent)
+	// 计算每个类别的保留截止时间
+	cutoffs := categoryCutoffs(prof, keepRecent, time.Now())
+	
+	// 文件编辑历史通过 history 清理器处理，保证 entries.json 与版本文件一致
+	historyPruners := newHistoryPruners()
+	inHistory := func(path string) bool {
+		for _, pruner := range historyPruners {
+			if pruner.Contains(path) {
+				return true
+			}
+		}
+		return false
 	}
 	
 	// 处理文件系统中的文件
 	for _, file := range files {
-		// 如果设置了 keepRecent，跳过最近的文件
-		if keepRecent > 0 && file.Modified.After(keepCutoff) {
+		if inHistory(file.Path) {
+			continue
+		}
+		
+		// 跳过保留期内的文件
+		if tooRecent(cutoffs, profileFileCategories[file.FileType], file.Modified) {
+			continue
+		}
+		
+		// 跳过被进程打开的文件
+		if locked.holds(file.Path) {
+			skippedLocked = append(skippedLocked, file.Path)
 			continue
 		}
 		
 		switch file.FileType {
 		case types.TypeTemp:
-			toClean = append(toClean, cleanItem{path: file.Path, size: file.Size, reason: "temp"})
-			totalSize += file.Size
+			if includes(profile.CategoryTemp) {
+				toClean = append(toClean, cleanItem{path: file.Path, size: file.Size, reason: "temp"})
+				totalSize += file.Size
+			}
 		case types.TypeLog:
 			if !keepLogs {
 				toClean = append(toClean, cleanItem{path: file.Path, size: file.Size, reason: "log"})
@@ -258,35 +422,149 @@ func runClean(cmd *cobra.Command, args []string) error {
 				totalSize += file.Size
 			}
 		case types.TypeBackup:
-			toClean = append(toClean, cleanItem{path: file.Path, size: file.Size, reason: "history"})
-			totalSize += file.Size
+			if includes(profile.CategoryHistory) {
+				toClean = append(toClean, cleanItem{path: file.Path, size: file.Size, reason: "history"})
+				totalSize += file.Size
+			}
 		case types.TypeIndex:
 			if !keepIndex {
 				toClean = append(toClean, cleanItem{path: file.Path, size: file.Size, reason: "index"})
 				totalSize += file.Size
 			}
+		case types.TypeOrphan:
+			// 没有被任何对话、会话或执行状态引用，删除是安全的
+			if !keepOrphans(prof, keepCache, cmd.Flags().Changed("keep-cache")) {
+				toClean = append(toClean, cleanItem{path: file.Path, size: file.Size, reason: "orphan"})
+				totalSize += file.Size
+			}
+		}
+	}
+	
+	// 处理文件编辑历史
+	historyPolicy := buildHistoryPolicy(cmd)
+	var historyActions [][]history.PruneAction
+	historyVersions := 0
+	for _, pruner := range historyPruners {
+		if !includes(profile.CategoryHistory) {
+			historyActions = append(historyActions, nil)
+			continue
+		}
+		planned, err := pruner.Plan(historyPolicy)
+		if err != nil {
+			planned = nil
+		}
+		var actions []history.PruneAction
+		for _, action := range planned {
+			if policies.PolicyForPath(action.History.SourcePath).Protects(policy.ScopeHistory) {
+				skippedPolicy++
+				continue
+			}
+			if locked.holds(action.History.Dir) {
+				skippedLocked = append(skippedLocked, action.History.Dir)
+				continue
+			}
+			actions = append(actions, action)
+		}
+		historyActions = append(historyActions, actions)
+		for _, action := range actions {
+			toClean = append(toClean, cleanItem{path: action.History.Dir, size: action.Bytes, reason: "history", viaHistory: true})
+			totalSize += action.Bytes
+			historyVersions += len(action.Drop)
+		}
+	}
+	
+	// 处理崩溃报告（按保留策略，而不是作为临时文件）
+	crashRetentionPolicy := crashRetention(cfg)
+	if keep := time.Duration(categoryDays(prof, keepRecent, profile.CategoryCrash)) * 24 * time.Hour; keep > crashRetentionPolicy.MaxAge {
+		crashRetentionPolicy.MaxAge = keep
+	}
+	var crashManagers []*crashpad.Manager
+	var crashReports [][]crashpad.Report
+	if includes(profile.CategoryCrash) {
+		crashManagers, crashReports = scanCrashReports()
+	}
+	crashPlans := make([][]crashpad.Report, len(crashManagers))
+	for i := range crashManagers {
+		for _, r := range crashpad.Plan(crashReports[i], crashRetentionPolicy) {
+			if locked.holds(r.Path) {
+				skippedLocked = append(skippedLocked, r.Path)
+				continue
+			}
+			crashPlans[i] = append(crashPlans[i], r)
+		}
+		for _, r := range crashPlans[i] {
+			toClean = append(toClean, cleanItem{path: r.Path, size: r.Size, reason: "crash", viaCrashpad: true})
+			totalSize += r.Size
 		}
 	}
 	
 	// 处理会话文件
-	if !keepChats {
-		allChats, err := chatScanner.FindCleanableConversations(0, 0)
+	// 项目的 chat_retention_days 即使在全局保留对话时也生效，除非显式指定了 --keep-chats
+	policyPurge := !cmd.Flags().Changed("keep-chats") && (prof == nil || prof.Includes(profile.CategoryChats))
+	// 固定的对话永远不清理；固定列表无法读取时保留所有对话
+	pinned, pinErr := loadPins(chatScanner)
+	if pinErr != nil {
+		termUI.PrintWarning(fmt.Sprintf("Keeping all conversations, cannot read pinned list: %v", pinErr))
+	}
+	skippedPinned := 0
+	
+	// --keep-last-chats：每个工作区最新的N个对话始终保留，其余对话再按保留天数判断
+	var selectedChats []selectedChat
+	if pinErr == nil && (!keepChats || (policyPurge && len(policies.WithPolicy()) > 0)) {
+		allChats, err := chatScanner.FindConversations(types.ChatRetention{KeepLast: keepLastChats})
 		if err == nil {
+			now := time.Now()
 			for _, chat := range allChats {
-				if keepRecent > 0 && chat.ModTime.After(keepCutoff) {
+				if pinned.Contains(chat.Path) {
+					skippedPinned++
 					continue
 				}
-				toClean = append(toClean, cleanItem{path: chat.Path, size: chat.Size, reason: "chat"})
+				p := policies.PolicyForChat(chat.Path)
+				switch chatPolicy(p, chat.ModTime, now) {
+				case chatKeep:
+					if !keepChats {
+						skippedPolicy++
+					}
+					continue
+				case chatPurge:
+					if !policyPurge {
+						continue
+					}
+					chat.Reason = chatReasonPolicy
+				default:
+					if keepChats || tooRecent(cutoffs, profile.CategoryChats, chat.ModTime) {
+						continue
+					}
+				}
+				if locked.holds(chat.Path) {
+					skippedLocked = append(skippedLocked, chat.Path)
+					continue
+				}
+				toClean = append(toClean, cleanItem{path: chat.Path, size: chat.Size, reason: "chat", export: p != nil && p.ExportBeforeDelete})
 				totalSize += chat.Size
+				selectedChats = append(selectedChats, selectedChat{chat: chat, policy: p})
 			}
 		}
 	}
 	
 	spinner.Success("Scan complete")
 	fmt.Println()
+	if skippedPolicy > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Kept %d items protected by project policies", skippedPolicy))
+	}
+	if skippedPinned > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Kept %d pinned conversations", skippedPinned))
+	}
 	
-	// Kiro 运行警告
-	if running && !dryRun {
+	// Kiro 运行警告：能精确检测时只报告被跳过的文件
+	if running && preciseLocks {
+		if len(skippedLocked) > 0 {
+			locked.printLockedSummary(skippedLocked)
+		} else {
+			termUI.PrintInfo("Kiro is running, but none of the files to clean are open")
+		}
+		fmt.Println()
+	} else if running && !dryRun {
 		termUI.PrintWarning("Kiro is running, some files may be locked")
 		termUI.PrintInfo("Use --kill-kiro to automatically stop Kiro before cleaning")
 		if !skipConfirm {
@@ -322,9 +600,11 @@ func runClean(cmd *cobra.Command, args []string) error {
 	}{
 		{"log", pterm.FgYellow},
 		{"cache", pterm.FgBlue},
+		{"orphan", pterm.FgLightBlue},
 		{"index", pterm.FgGreen},
 		{"chat", pterm.FgCyan},
 		{"history", pterm.FgMagenta},
+		{"crash", pterm.FgLightRed},
 		{"temp", pterm.FgRed},
 	}
 	
@@ -334,6 +614,15 @@ func runClean(cmd *cobra.Command, args []string) error {
 			if item.key == "chat" {
 				countStr = fmt.Sprintf("%d conversations", count)
 			}
+			if item.key == "history" {
+				countStr = fmt.Sprintf("%d versions in %d files", historyVersions, count)
+			}
+			if item.key == "crash" {
+				countStr = fmt.Sprintf("%d reports", count)
+			}
+			if item.key == "orphan" {
+				countStr = fmt.Sprintf("%d files, unreferenced", count)
+			}
 			cleanItems = append(cleanItems, ui.CleanableItem{
 				Name:  item.key,
 				Size:  storage.FormatSize(typeSize[item.key]),
@@ -344,6 +633,24 @@ func runClean(cmd *cobra.Command, args []string) error {
 	}
 	
 	termUI.PrintCleanableItems(cleanItems, storage.FormatSize(totalSize))
+	printChatReasons(selectedChats, keepLastChats, categoryDays(prof, keepRecent, profile.CategoryChats))
+	if n := countExports(toClean); n > 0 {
+		termUI.PrintInfo(fmt.Sprintf("%d conversations will be exported to %s first (project policy)", n, config.ExportsDir()))
+	}
+	if cleanArchiveChats && typeCount["chat"] > 0 {
+		termUI.PrintInfo(fmt.Sprintf("%d conversations will be compressed into %s instead of deleted", typeCount["chat"], config.ArchiveDir()))
+	}
+	
+	// --backup：预检备份卷剩余空间，不足时拒绝或改为隔离
+	var backupPlan *backupPreflight
+	if withBackup {
+		plan, ok := prepareBackup(toClean, &cfg.Safety)
+		if !ok {
+			// 非零退出码，脚本和 auto 不会把拒绝当作清理成功
+			return fmt.Errorf("backup preflight failed, nothing deleted")
+		}
+		backupPlan = plan
+	}
 	
 	// 预览模式
 	if dryRun {
@@ -359,6 +666,32 @@ func runClean(cmd *cobra.Command, args []string) error {
 		}
 	}
 	
+	// 备份要删除的文件，备份失败时不删除
+	if backupPlan != nil && backupPlan.quarantine == nil && len(backupPlan.files) > 0 {
+		backupID, err := backupPlan.manager.CreateBackup(backupPlan.files)
+		if err != nil {
+			termUI.PrintError(fmt.Sprintf("Backup failed, nothing deleted: %v", err))
+			return err
+		}
+		termUI.PrintSuccess(fmt.Sprintf("Backup created: %s", backupID))
+		auditEntry.BackupID = backupID
+	}
+	
+	// 项目策略要求先导出的对话，导出失败则保留
+	toClean, exportPath, exportErr := exportPolicyChats(toClean)
+	if exportErr != nil {
+		termUI.PrintWarning(fmt.Sprintf("Conversations kept, export failed: %v", exportErr))
+		auditEntry.AddError(fmt.Errorf("conversation export failed, conversations kept: %v", exportErr))
+	} else if exportPath != "" {
+		termUI.PrintInfo(fmt.Sprintf("Conversations exported to %s", exportPath))
+	}
+	
+	// --archive-chats：对话压缩到归档目录后再删除原文件
+	var archived *archiveResult
+	if cleanArchiveChats {
+		toClean, archived = archiveChatItems(toClean, chatScanner, auditEntry)
+	}
+	
 	// 执行清理
 	progressBar, _ := pterm.DefaultProgressbar.
 		WithTotal(len(toClean)).
@@ -368,32 +701,119 @@ func runClean(cmd *cobra.Command, args []string) error {
 	
 	var cleaned int
 	var cleanedSize int64
+	var quarantinedSize int64
 	var errors int
 	
+	// 所有删除都经过路径守卫，拒绝 Kiro 数据目录之外或经符号链接逃逸的路径
+	guard := newKiroGuard(chatScanner)
 	for _, item := range toClean {
-		if err := os.Remove(item.path); err == nil {
+		if item.viaHistory || item.viaCrashpad {
+			continue
+		}
+		var err error
+		action := audit.ActionDeleted
+		if backupPlan != nil && backupPlan.quarantine != nil {
+			_, err = backupPlan.quarantine.Move(item.path)
+			action = audit.ActionQuarantined
+		} else {
+			err = guard.Remove(item.path)
+		}
+		if err == nil {
 			cleaned++
-			cleanedSize += item.size
+			if action == audit.ActionQuarantined {
+				quarantinedSize += item.size
+			} else {
+				cleanedSize += item.size
+			}
 		} else {
 			errors++
+			action = audit.ActionFailed
+			auditEntry.AddError(err)
 		}
+		auditEntry.AddFile(item.path, item.size, item.reason, action)
 		progressBar.Increment()
 	}
 	
+	// 清理文件编辑历史（改写 entries.json，删除空目录）
+	for i, pruner := range historyPruners {
+		result := pruner.Apply(historyActions[i])
+		auditHistory(auditEntry, historyActions[i], result)
+		cleaned += result.VersionsRemoved
+		cleanedSize += result.FreedBytes
+		errors += len(result.Errors)
+		progressBar.Add(len(historyActions[i]))
+	}
+	
+	// 清理崩溃报告（按配置先导出，导出失败则保留报告）
+	crashCount := 0
+	for _, plan := range crashPlans {
+		crashCount += len(plan)
+	}
+	crashResult, crashBundle, crashErr := deleteCrashReports(crashManagers, crashPlans, cfg.CrashExport)
+	if crashErr != nil {
+		auditEntry.AddError(fmt.Errorf("crash report export failed, reports kept: %v", crashErr))
+	} else {
+		auditCrashReports(auditEntry, crashPlans, crashResult)
+	}
+	cleaned += crashResult.Deleted
+	cleanedSize += crashResult.FreedBytes
+	errors += len(crashResult.Errors)
+	progressBar.Add(crashCount)
+	
 	progressBar.Stop()
+	if crashErr != nil {
+		termUI.PrintWarning(fmt.Sprintf("Crash reports kept, export failed: %v", crashErr))
+	} else if crashBundle != "" {
+		termUI.PrintInfo(fmt.Sprintf("Crash reports exported to %s", crashBundle))
+	}
+	if archived != nil {
+		printArchiveResult(archived)
+		cleaned += archived.archived
+		if saved := archived.original - archived.compressed; saved > 0 {
+			cleanedSize += saved
+		}
+		errors += len(archived.errors)
+	}
 	termUI.PrintCleanResult(cleaned, storage.FormatSize(cleanedSize), errors)
+	if quarantinedSize > 0 {
+		printQuarantineSummary(backupPlan.quarantine, quarantinedSize)
+	}
 	return nil
 }
 
 // cleanItem 清理项
 type cleanItem struct {
-	path   string
-	size   int64
-	reason string
+	path        string
+	size        int64
+	reason      string
+	viaHistory  bool // 由 history 清理器处理
+	viaCrashpad bool // 由 crashpad 管理器处理
+	export      bool // 项目策略要求删除前先导出
+}
+
+// buildHistoryPolicy 根据命令行参数构建历史清理策略
+// 未指定任何历史参数时删除全部历史（--keep-recent 仍然生效）
+func buildHistoryPolicy(cmd *cobra.Command) history.Policy {
+	policy := history.Policy{
+		KeepLast:    historyKeepLast,
+		DropMissing: historyDropMissing,
+	}
+	if historyOlderThan > 0 {
+		policy.MaxAge = time.Duration(historyOlderThan) * 24 * time.Hour
+	}
+	if policy.IsEmpty() {
+		if keepRecent > 0 {
+			policy.MaxAge = time.Duration(keepRecent) * 24 * time.Hour
+		} else {
+			policy.DropAll = true
+		}
+	}
+	return policy
 }
 
 // displayScanResult 显示扫描结果
-func displayScanResult(stats *types.StorageStats, convStats *types.ConversationStats, files []types.FileInfo) {
+// sel 不为空时只统计预设会清理的内容
+func displayScanResult(stats *types.StorageStats, convStats *types.ConversationStats, files []types.FileInfo, sel *scanSelection) {
 	// 按类型统计文件大小和数量
 	typeSizes := make(map[types.FileType]int64)
 	typeCounts := make(map[types.FileType]int)
@@ -403,7 +823,7 @@ func displayScanResult(stats *types.StorageStats, convStats *types.ConversationS
 	}
 	
 	// 计算 Other
-	classifiedSize := stats.CacheSize + stats.LogSize + stats.TempSize + typeSizes[types.TypeBackup] + typeSizes[types.TypeIndex] + convStats.TotalSize
+	classifiedSize := stats.CacheSize + stats.LogSize + stats.TempSize + typeSizes[types.TypeBackup] + typeSizes[types.TypeIndex] + typeSizes[types.TypeCrash] + typeSizes[types.TypeOrphan] + convStats.TotalSize
 	otherSize := stats.TotalSize + convStats.TotalSize - classifiedSize
 	if otherSize < 0 {
 		otherSize = 0
@@ -411,13 +831,19 @@ func displayScanResult(stats *types.StorageStats, convStats *types.ConversationS
 	
 	// 存储概览
 	storageItems := []ui.StorageItem{
-		{Name: "Conversations", Size: storage.FormatSize(convStats.TotalSize), Extra: fmt.Sprintf("%d chats", convStats.TotalConversations), Color: pterm.FgCyan},
+		{Name: "Conversations", Size: storage.FormatSize(convStats.TotalSize), Extra: fmt.Sprintf("%d chats, ~%s tokens", convStats.TotalConversations, tokens.Format(convStats.TotalTokens)), Color: pterm.FgCyan},
 		{Name: "Logs", Size: storage.FormatSize(stats.LogSize), Color: pterm.FgYellow},
 		{Name: "Cache", Size: storage.FormatSize(stats.CacheSize), Color: pterm.FgBlue},
 		{Name: "Index", Size: storage.FormatSize(typeSizes[types.TypeIndex]), Extra: "code search", Color: pterm.FgGreen},
 		{Name: "History", Size: storage.FormatSize(typeSizes[types.TypeBackup]), Color: pterm.FgMagenta},
+		{Name: "Crash", Size: storage.FormatSize(typeSizes[types.TypeCrash]), Extra: "crash reports", Color: pterm.FgLightRed},
 		{Name: "Temp", Size: storage.FormatSize(stats.TempSize), Color: pterm.FgRed},
 	}
+	if typeSizes[types.TypeOrphan] > 0 {
+		storageItems = append(storageItems, ui.StorageItem{
+			Name: "Orphans", Size: storage.FormatSize(typeSizes[types.TypeOrphan]), Extra: "unreferenced snapshots and diffs", Color: pterm.FgLightBlue,
+		})
+	}
 	
 	if otherSize > 0 {
 		storageItems = append(storageItems, ui.StorageItem{
@@ -431,6 +857,10 @@ func displayScanResult(stats *types.StorageStats, convStats *types.ConversationS
 	})
 	
 	termUI.PrintStorageOverview(storageItems)
+	if !stats.LastCleanup.IsZero() {
+		termUI.PrintInfo(fmt.Sprintf("Last cleanup %s ago (%s)", formatAge(time.Since(stats.LastCleanup)), stats.LastCleanup.Format("2006-01-02 15:04")))
+	}
+	printWorkspaceTokens(convStats.WorkspaceBreakdown, 5)
 	
 	// 可清理项统计
 	tempSize := typeSizes[types.TypeTemp]
@@ -438,10 +868,43 @@ func displayScanResult(stats *types.StorageStats, convStats *types.ConversationS
 	cacheSize := stats.CacheSize
 	historySize := typeSizes[types.TypeBackup]
 	indexSize := typeSizes[types.TypeIndex]
+	orphanSize, orphanCount := typeSizes[types.TypeOrphan], typeCounts[types.TypeOrphan]
 	chatSize := convStats.TotalSize
 	chatCount := convStats.TotalConversations
+	tempCount := typeCounts[types.TypeTemp]
+	crashPolicy := crashRetention(config.LoadConfig())
+	includeCrash := true
+	if sel != nil {
+		sizes, counts := sel.cleanableSizes(files)
+		tempSize, tempCount = sizes[profile.CategoryTemp], counts[profile.CategoryTemp]
+		logSize = sizes[profile.CategoryLogs]
+		cacheSize = sizes[profile.CategoryCache]
+		historySize = sizes[profile.CategoryHistory]
+		indexSize = sizes[profile.CategoryIndex]
+		orphanSize, orphanCount = sizes[profile.CategoryOrphans], counts[profile.CategoryOrphans]
+		chatSize, chatCount = sel.chatSize, sel.chatCount
+		includeCrash = sel.profile.Includes(profile.CategoryCrash)
+		if keep := time.Duration(sel.profile.MinAgeDays(profile.CategoryCrash)) * 24 * time.Hour; keep > crashPolicy.MaxAge {
+			crashPolicy.MaxAge = keep
+		}
+		fmt.Println()
+		termUI.PrintInfo(fmt.Sprintf("Profile %s: %s", sel.profile.Name, sel.profile.Summary()))
+	}
+	
+	// 崩溃报告只统计超出保留策略的部分
+	var crashSize int64
+	crashCount := 0
+	if includeCrash {
+		_, crashReports := scanCrashReports()
+		for _, reports := range crashReports {
+			for _, r := range crashpad.Plan(reports, crashPolicy) {
+				crashSize += r.Size
+				crashCount++
+			}
+		}
+	}
 	
-	totalCleanable := tempSize + logSize + cacheSize + historySize + indexSize + chatSize
+	totalCleanable := tempSize + logSize + cacheSize + orphanSize + historySize + indexSize + chatSize + crashSize
 	
 	if totalCleanable > 0 {
 		var cleanItems []ui.CleanableItem
@@ -456,6 +919,11 @@ func displayScanResult(stats *types.StorageStats, convStats *types.ConversationS
 				Name: "Cache", Size: storage.FormatSize(cacheSize), Color: pterm.FgBlue,
 			})
 		}
+		if orphanSize > 0 {
+			cleanItems = append(cleanItems, ui.CleanableItem{
+				Name: "Orphans", Size: storage.FormatSize(orphanSize), Count: fmt.Sprintf("%d files, safe", orphanCount), Color: pterm.FgLightBlue,
+			})
+		}
 		if indexSize > 0 {
 			cleanItems = append(cleanItems, ui.CleanableItem{
 				Name: "Index", Size: storage.FormatSize(indexSize), Count: "will rebuild", Color: pterm.FgGreen,
@@ -471,20 +939,32 @@ func displayScanResult(stats *types.StorageStats, convStats *types.ConversationS
 				Name: "History", Size: storage.FormatSize(historySize), Color: pterm.FgMagenta,
 			})
 		}
+		if crashSize > 0 {
+			cleanItems = append(cleanItems, ui.CleanableItem{
+				Name: "Crash", Size: storage.FormatSize(crashSize), Count: fmt.Sprintf("%d reports", crashCount), Color: pterm.FgLightRed,
+			})
+		}
 		if tempSize > 0 {
 			cleanItems = append(cleanItems, ui.CleanableItem{
-				Name: "Temp", Size: storage.FormatSize(tempSize), Count: fmt.Sprintf("%d files", typeCounts[types.TypeTemp]), Color: pterm.FgRed,
+				Name: "Temp", Size: storage.FormatSize(tempSize), Count: fmt.Sprintf("%d files", tempCount), Color: pterm.FgRed,
 			})
 		}
 		
 		termUI.PrintCleanableItems(cleanItems, storage.FormatSize(totalCleanable))
 		
 		// 提示
-		termUI.PrintTips([]string{
+		tips := []string{
 			"Run 'kiro-cleaner clean' to free up space",
 			"Use --keep-* flags to preserve specific types",
 			"Use --dry-run to preview without deleting",
-		})
+		}
+		if sel != nil {
+			tips = []string{
+				fmt.Sprintf("Run 'kiro-cleaner clean --profile %s' to free up space", sel.profile.Name),
+				"Use --dry-run to preview without deleting",
+			}
+		}
+		termUI.PrintTips(tips)
 	} else {
 		fmt.Println()
 		termUI.PrintSuccess("Nothing to clean - your Kiro is tidy!")
@@ -501,7 +981,28 @@ func runConfig(cmd *cobra.Command, args []string) error {
 		{"keep_chats", fmt.Sprintf("%v", cfg.KeepChats), "Keep conversations"},
 		{"keep_index", fmt.Sprintf("%v", cfg.KeepIndex), "Keep code index"},
 		{"keep_recent", fmt.Sprintf("%d days", cfg.KeepRecent), "Keep recent files"},
+		{"keep_last_chats", fmt.Sprintf("%d", cfg.KeepLastChats), "Newest conversations kept per workspace"},
+		{"crash_keep_days", fmt.Sprintf("%d days", cfg.CrashKeepDays), "Keep crash reports for"},
+		{"crash_keep_last", fmt.Sprintf("%d", cfg.CrashKeepLast), "Always keep newest crash reports"},
+		{"crash_export", fmt.Sprintf("%v", cfg.CrashExport), "Export crash reports before deletion"},
 		{"skip_confirm", fmt.Sprintf("%v", cfg.SkipConfirm), "Skip confirmation prompts"},
+		{"kill_timeout", fmt.Sprintf("%d seconds", cfg.KillTimeout), "Wait before force-killing Kiro"},
+		{"trend_threshold", cfg.TrendThreshold, "Size the trend command forecasts"},
+		{"profiles", strings.Join(profile.Names(cfg.Profiles), ", "), "Cleanup profiles for clean/scan --profile"},
+		{"auto.max_total", cfg.Auto.MaxTotal, "auto: clean logs, cache, temp above this total"},
+		{"auto.max_cache", cfg.Auto.MaxCache, "auto: clean cache above this size"},
+		{"auto.max_logs", cfg.Auto.MaxLogs, "auto: clean logs above this size"},
+		{"auto.max_index", cfg.Auto.MaxIndex, "auto: clean index above this size"},
+		{"auto.max_temp", cfg.Auto.MaxTemp, "auto: clean temp files above this size"},
+		{"auto.chat_max_age_days", fmt.Sprintf("%d days", cfg.Auto.ChatMaxAgeDays), "auto: delete chats older than this (0 = never)"},
+		{"auto.allow_while_running", fmt.Sprintf("%v", cfg.Auto.AllowWhileRunning), "auto: clean while Kiro is running"},
+		{"auto.profiles", autoProfileNames(cfg.Auto), "auto: named thresholds for auto --profile"},
+		{"watch.limits", watchLimitsString(cfg.Watch.Limits), "watch: category size limits"},
+		{"watch.action", cfg.Watch.Action, "watch: command to run when a limit is passed"},
+		{"watch.cooldown_minutes", fmt.Sprintf("%d minutes", cfg.Watch.CooldownMinutes), "watch: wait between actions"},
+		{"safety.min_disk_space", cfg.Safety.MinDiskSpace, "Free space to keep when backing up"},
+		{"safety.quarantine_fallback", fmt.Sprintf("%v", cfg.Safety.QuarantineFallback), "Move files aside if a backup does not fit"},
+		{"admin.allow_users", strings.Join(cfg.Admin.AllowUsers, ", "), "Users scan/clean --all-users may act on"},
 	}
 	
 	termUI.PrintConfigTable(config.ConfigPath(), settings)
@@ -707,3 +1208,29 @@ func copyFile(src, dst string) error {
 	_, err = io.Copy(destFile, sourceFile)
 	return err
 }
+
+// relaunchKiro 重新启动 Kiro 并报告停机时长，Kiro 仍在运行（停止失败）时不重复启动
+func relaunchKiro(launch *utils.LaunchInfo, stoppedAt time.Time) {
+	if running, _, _ := utils.IsKiroRunning(); running {
+		termUI.PrintInfo("Kiro is still running, not restarting it")
+		return
+	}
+	pid, err := utils.RelaunchKiro(launch)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to restart Kiro: %v", err))
+		termUI.PrintInfo(fmt.Sprintf("Start it manually: %s %s", launch.Exe, strings.Join(launch.Args, " ")))
+		return
+	}
+	downtime := time.Since(stoppedAt).Round(100 * time.Millisecond)
+	termUI.PrintSuccess(fmt.Sprintf("Kiro restarted (pid %d), down for %s", pid, downtime))
+}
+
+// newKiroGuard 创建只允许操作 Kiro 数据目录（和 Kiro Agent 目录）的路径守卫
+func newKiroGuard(chatScanner *scanner.ChatScanner) *safety.Guard {
+	roots, _ := storage.NewStorageDetector().FindKiroPaths()
+	guard := safety.NewGuard(roots...)
+	if agentPath, err := chatScanner.FindKiroAgentPath(); err == nil {
+		guard.AddRoot(agentPath)
+	}
+	return guard
+}
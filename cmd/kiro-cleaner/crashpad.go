@@ -0,0 +1,340 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/crashpad"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+)
+
+// crashpadCmd crashpad command
+var crashpadCmd = &cobra.Command{
+	Use:   "crashpad",
+	Short: "List Kiro crash reports",
+	Long: `List pending and completed Kiro crash reports (Crashpad minidumps)
+with their time, size, crashing process type and module.`,
+	RunE: runCrashpad,
+}
+
+// crashpadShowCmd crashpad show command
+var crashpadShowCmd = &cobra.Command{
+	Use:   "show <report-id>",
+	Short: "Show details of a crash report",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runCrashpadShow,
+}
+
+// crashpadExportCmd crashpad export command
+var crashpadExportCmd = &cobra.Command{
+	Use:   "export [report-id...]",
+	Short: "Export crash reports as a zip bundle",
+	Long: `Export crash reports with a summary.txt into a zip bundle for bug reports.
+Exports all reports when no ID is given.`,
+	RunE: runCrashpadExport,
+}
+
+// crashpadCleanCmd crashpad clean command
+var crashpadCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Delete crash reports past the retention policy",
+	Long: `Delete crash reports older than the retention period, always keeping the newest ones.
+Reports are exported to a bundle first unless --no-export is given.`,
+	RunE: runCrashpadClean,
+}
+
+var (
+	crashExportDir string
+	crashOlderThan int
+	crashKeepLast  int
+	crashNoExport  bool
+)
+
+func init() {
+	crashpadCmd.AddCommand(crashpadShowCmd)
+	crashpadCmd.AddCommand(crashpadExportCmd)
+	crashpadCmd.AddCommand(crashpadCleanCmd)
+	rootCmd.AddCommand(crashpadCmd)
+
+	for _, c := range []*cobra.Command{crashpadCmd, crashpadShowCmd, crashpadExportCmd, crashpadCleanCmd} {
+		c.SetHelpFunc(customSubCmdHelpFunc)
+	}
+
+	crashpadExportCmd.Flags().StringVar(&crashExportDir, "dir", "", "Output directory (default: ~/.kiro-cleaner/crash-reports)")
+
+	crashpadCleanCmd.Flags().IntVar(&crashOlderThan, "older-than", 0, "Delete reports older than N days (default: crash_keep_days)")
+	crashpadCleanCmd.Flags().IntVar(&crashKeepLast, "keep-last", 0, "Always keep the newest N reports (default: crash_keep_last)")
+	crashpadCleanCmd.Flags().BoolVar(&crashNoExport, "no-export", false, "Do not export reports before deleting them")
+	crashpadCleanCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview only, no deletion")
+	crashpadCleanCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+}
+
+// newCrashpadManagers 为每个 Kiro 数据目录创建 Crashpad 管理器
+func newCrashpadManagers() []*crashpad.Manager {
+	var managers []*crashpad.Manager
+	kiroPaths, _ := storage.NewStorageDetector().FindKiroPaths()
+	for _, kiroPath := range kiroPaths {
+		managers = append(managers, crashpad.NewManager(crashpad.Root(kiroPath)))
+	}
+	return managers
+}
+
+// crashRetention 根据全局配置构建崩溃报告保留策略
+func crashRetention(cfg *config.GlobalConfig) crashpad.Retention {
+	return crashpad.Retention{
+		MaxAge:   time.Duration(cfg.CrashKeepDays) * 24 * time.Hour,
+		KeepLast: cfg.CrashKeepLast,
+	}
+}
+
+// scanCrashReports 扫描所有 Kiro 数据目录中的崩溃报告
+func scanCrashReports() ([]*crashpad.Manager, [][]crashpad.Report) {
+	managers := newCrashpadManagers()
+	reports := make([][]crashpad.Report, len(managers))
+	for i, mgr := range managers {
+		found, err := mgr.Scan()
+		if err != nil {
+			termUI.PrintWarning(fmt.Sprintf("Failed to scan crash reports: %v", err))
+			continue
+		}
+		reports[i] = found
+	}
+	return managers, reports
+}
+
+// deleteCrashReports 按配置先导出再删除崩溃报告，导出失败时不删除
+func deleteCrashReports(managers []*crashpad.Manager, plans [][]crashpad.Report, export bool) (*crashpad.DeleteResult, string, error) {
+	var all []crashpad.Report
+	for _, plan := range plans {
+		all = append(all, plan...)
+	}
+
+	total := &crashpad.DeleteResult{}
+	if len(all) == 0 {
+		return total, "", nil
+	}
+
+	bundle := ""
+	if export {
+		var err error
+		bundle, err = crashpad.Export(all, config.CrashReportsDir())
+		if err != nil {
+			return total, "", err
+		}
+	}
+
+	for i, mgr := range managers {
+		result := mgr.Delete(plans[i])
+		total.Deleted += result.Deleted
+		total.FreedBytes += result.FreedBytes
+		total.Errors = append(total.Errors, result.Errors...)
+	}
+	return total, bundle, nil
+}
+
+// crashDetail 返回报告的进程类型和崩溃模块描述
+func crashDetail(r crashpad.Report) string {
+	if r.Dump == nil {
+		return "unreadable minidump"
+	}
+	detail := r.Dump.ProcessType
+	if detail == "" {
+		detail = "unknown process"
+	}
+	if r.Dump.CrashModule != "" {
+		detail += " in " + r.Dump.CrashModule
+	}
+	return detail
+}
+
+// runCrashpad 列出崩溃报告
+func runCrashpad(cmd *cobra.Command, args []string) error {
+	_, reports := scanCrashReports()
+
+	var count int
+	var totalSize int64
+	for _, found := range reports {
+		count += len(found)
+		for _, r := range found {
+			totalSize += r.Size
+		}
+	}
+
+	if count == 0 {
+		termUI.PrintSuccess("No crash reports found")
+		return nil
+	}
+
+	termUI.PrintSection("Crash Reports")
+	for _, found := range reports {
+		for _, r := range found {
+			stateColor := pterm.FgGreen
+			if r.State != crashpad.StateCompleted {
+				stateColor = pterm.FgYellow
+			}
+			fmt.Printf("  %s  %-9s %s %10s  %s\n",
+				pterm.NewStyle(pterm.FgCyan).Sprint(truncateMiddle(r.ID, 12)),
+				pterm.NewStyle(stateColor).Sprint(r.State),
+				r.Timestamp.Format("2006-01-02 15:04"),
+				storage.FormatSize(r.Size),
+				pterm.NewStyle(pterm.FgGray).Sprint(crashDetail(r)))
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("─────────────────────────────────────────────")
+	fmt.Printf("  %s %d reports, %s\n",
+		pterm.NewStyle(pterm.FgWhite, pterm.Bold).Sprint("Total"),
+		count,
+		pterm.NewStyle(pterm.FgGreen, pterm.Bold).Sprint(storage.FormatSize(totalSize)))
+
+	cfg := config.LoadConfig()
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner crashpad export' to bundle reports for a bug report",
+		fmt.Sprintf("'clean' keeps the newest %d reports and anything newer than %d days", cfg.CrashKeepLast, cfg.CrashKeepDays),
+	})
+	return nil
+}
+
+// runCrashpadShow 显示单个崩溃报告
+func runCrashpadShow(cmd *cobra.Command, args []string) error {
+	for _, mgr := range newCrashpadManagers() {
+		report, err := mgr.Find(args[0])
+		if err != nil {
+			continue
+		}
+		termUI.PrintSection("Crash Report")
+		fmt.Print(crashpad.Summary([]crashpad.Report{*report}))
+		for _, path := range report.Files {
+			fmt.Printf("  %s\n", pterm.NewStyle(pterm.FgGray).Sprint(path))
+		}
+		return nil
+	}
+	termUI.PrintError(fmt.Sprintf("Crash report not found: %s", args[0]))
+	return nil
+}
+
+// runCrashpadExport 导出崩溃报告
+func runCrashpadExport(cmd *cobra.Command, args []string) error {
+	managers, reports := scanCrashReports()
+
+	var selected []crashpad.Report
+	if len(args) == 0 {
+		for _, found := range reports {
+			selected = append(selected, found...)
+		}
+	} else {
+		for _, id := range args {
+			var report *crashpad.Report
+			for _, mgr := range managers {
+				if r, err := mgr.Find(id); err == nil {
+					report = r
+					break
+				}
+			}
+			if report == nil {
+				termUI.PrintError(fmt.Sprintf("Crash report not found: %s", id))
+				return nil
+			}
+			selected = append(selected, *report)
+		}
+	}
+
+	if len(selected) == 0 {
+		termUI.PrintSuccess("No crash reports found")
+		return nil
+	}
+
+	outDir := crashExportDir
+	if outDir == "" {
+		outDir = config.CrashReportsDir()
+	}
+	bundle, err := crashpad.Export(selected, outDir)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Export failed: %v", err))
+		return err
+	}
+	termUI.PrintSuccess(fmt.Sprintf("Exported %d crash reports to %s", len(selected), bundle))
+	return nil
+}
+
+// runCrashpadClean 按保留策略清理崩溃报告
+func runCrashpadClean(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadConfig()
+	retention := crashRetention(cfg)
+	if cmd.Flags().Changed("older-than") {
+		retention.MaxAge = time.Duration(crashOlderThan) * 24 * time.Hour
+	}
+	if cmd.Flags().Changed("keep-last") {
+		retention.KeepLast = crashKeepLast
+	}
+	export := cfg.CrashExport && !crashNoExport
+
+	managers, reports := scanCrashReports()
+	plans := make([][]crashpad.Report, len(managers))
+	var count int
+	var totalSize int64
+	for i := range managers {
+		plans[i] = crashpad.Plan(reports[i], retention)
+		for _, r := range plans[i] {
+			count++
+			totalSize += r.Size
+		}
+	}
+
+	if count == 0 {
+		termUI.PrintSuccess("No crash reports past the retention policy")
+		return nil
+	}
+
+	termUI.PrintCleanPreview(count, storage.FormatSize(totalSize))
+	for _, plan := range plans {
+		for _, r := range plan {
+			fmt.Printf("  %s %-12s %10s  %s\n",
+				pterm.NewStyle(pterm.FgRed).Sprint("●"),
+				truncateMiddle(r.ID, 12),
+				storage.FormatSize(r.Size),
+				pterm.NewStyle(pterm.FgGray).Sprint(formatAge(r.Age())+", "+crashDetail(r)))
+		}
+	}
+	if export {
+		fmt.Println()
+		termUI.PrintInfo(fmt.Sprintf("Reports will be exported to %s first", config.CrashReportsDir()))
+	}
+
+	if dryRun {
+		termUI.PrintDryRunNotice()
+		return nil
+	}
+
+	if !yes && !cfg.SkipConfirm {
+		if !termUI.Confirm("Delete these crash reports?") {
+			pterm.Info.Println("Cancelled")
+			return nil
+		}
+	}
+
+	auditEntry := startAudit(cmd, args)
+	result, bundle, err := deleteCrashReports(managers, plans, export)
+	if err != nil {
+		auditEntry.AddError(fmt.Errorf("export failed, nothing deleted: %v", err))
+		recordAudit(auditEntry)
+		termUI.PrintError(fmt.Sprintf("Export failed, nothing deleted: %v", err))
+		return err
+	}
+	auditCrashReports(auditEntry, plans, result)
+	recordAudit(auditEntry)
+	if bundle != "" {
+		termUI.PrintInfo(fmt.Sprintf("Exported to %s", bundle))
+	}
+	termUI.PrintCleanResult(result.Deleted, storage.FormatSize(result.FreedBytes), len(result.Errors))
+	if verbose {
+		for _, e := range result.Errors {
+			termUI.PrintWarning(e.Error())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/dedupe"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/pins"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/policy"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// chatsDedupeCmd chats dedupe command
+var chatsDedupeCmd = &cobra.Command{
+	Use:   "dedupe",
+	Short: "Find duplicate conversations left by retries and re-runs",
+	Long: `Compare the message sequences of all conversations in each workspace and
+report exact duplicates and conversations that are a strict prefix of another
+one. Whitespace differences are ignored. With --clean the duplicates are backed
+up and deleted, keeping the longest (or with --keep newest, the most recent)
+copy of each group. With --keep newest, copies with more messages than the
+kept one are never deleted. Pinned and policy-protected conversations are
+never deleted.`,
+	RunE: runChatsDedupe,
+}
+
+var (
+	dedupeKeep  string
+	dedupeClean bool
+)
+
+func init() {
+	chatsCmd.AddCommand(chatsDedupeCmd)
+	chatsDedupeCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	chatsDedupeCmd.Flags().StringVar(&dedupeKeep, "keep", dedupe.KeepLongest, "Copy to keep in each group: longest or newest")
+	chatsDedupeCmd.Flags().BoolVar(&dedupeClean, "clean", false, "Delete the duplicates")
+	chatsDedupeCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview only, nothing is deleted")
+	chatsDedupeCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+}
+
+// runChatsDedupe 找出重复的对话，--clean 时删除
+func runChatsDedupe(cmd *cobra.Command, args []string) error {
+	if dedupeKeep != dedupe.KeepLongest && dedupeKeep != dedupe.KeepNewest {
+		termUI.PrintError(fmt.Sprintf("Unknown --keep %q, use longest or newest", dedupeKeep))
+		return nil
+	}
+
+	chatScanner := scanner.NewChatScanner()
+	spinner := termUI.Spinner("Comparing conversations...")
+	chats, err := listChats(chatScanner)
+	if err != nil {
+		spinner.Stop()
+		termUI.PrintError(fmt.Sprintf("Kiro data not found: %v", err))
+		return nil
+	}
+	byPath := make(map[string]pins.Chat, len(chats))
+	fingerprints := make([]dedupe.Chat, 0, len(chats))
+	for _, c := range chats {
+		fp, err := dedupe.ReadChat(c.Path)
+		if err != nil {
+			continue
+		}
+		byPath[c.Path] = c
+		fingerprints = append(fingerprints, fp)
+	}
+	groups := dedupe.Find(fingerprints, dedupeKeep)
+	spinner.Stop()
+
+	if len(groups) == 0 {
+		termUI.PrintSuccess(fmt.Sprintf("No duplicates among %d conversations", len(fingerprints)))
+		return nil
+	}
+
+	termUI.PrintSection("Duplicate Conversations")
+	duplicates := 0
+	var reclaimable int64
+	for _, g := range groups {
+		printDedupeChat("keep", byPath[g.Keep.Path], g.Keep, pterm.FgGreen)
+		for _, d := range g.Duplicates {
+			printDedupeChat(d.Kind, byPath[d.Path], d.Chat, pterm.FgGray)
+		}
+		fmt.Println()
+		duplicates += len(g.Duplicates)
+		reclaimable += g.Reclaimable()
+	}
+	termUI.PrintInfo(fmt.Sprintf("%d duplicates in %d groups, %s reclaimable",
+		duplicates, len(groups), storage.FormatSize(reclaimable)))
+
+	if !dedupeClean {
+		termUI.PrintTips([]string{
+			"Run 'kiro-cleaner chats dedupe --clean' to delete the duplicates",
+			"Use --keep newest to keep the most recent copy instead of the longest",
+		})
+		return nil
+	}
+	return cleanDuplicates(cmd, chatScanner, groups, byPath)
+}
+
+// cleanDuplicates 备份后删除重复的对话，跳过固定和受策略保护的对话
+func cleanDuplicates(cmd *cobra.Command, chatScanner *scanner.ChatScanner, groups []dedupe.Group, byPath map[string]pins.Chat) error {
+	registry, err := loadPins(chatScanner)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Cannot read pinned conversations: %v", err))
+		return nil
+	}
+	policies := loadWorkspacePolicies(chatScanner)
+
+	var files []types.FileInfo
+	var total int64
+	skipped := 0
+	for _, g := range groups {
+		for _, d := range g.Duplicates {
+			if registry.IsPinned(byPath[d.Path]) || policies.PolicyForChat(d.Path).Protects(policy.ScopeChats) {
+				skipped++
+				continue
+			}
+			files = append(files, types.FileInfo{Path: d.Path, Name: filepath.Base(d.Path), Size: d.Size})
+			total += d.Size
+		}
+	}
+	if skipped > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Kept %d pinned or policy-protected duplicates", skipped))
+	}
+	if len(files) == 0 {
+		termUI.PrintSuccess("Nothing to delete")
+		return nil
+	}
+
+	if dryRun {
+		termUI.PrintInfo(fmt.Sprintf("Would delete %d duplicates (%s)", len(files), storage.FormatSize(total)))
+		termUI.PrintDryRunNotice()
+		return nil
+	}
+	if !yes && !config.LoadConfig().SkipConfirm {
+		if !termUI.Confirm(fmt.Sprintf("Delete %d duplicate conversations (%s)?", len(files), storage.FormatSize(total))) {
+			pterm.Info.Println("Cancelled")
+			return nil
+		}
+	}
+
+	// 备份要删除的对话，备份失败时不删除
+	auditEntry := startAudit(cmd, nil)
+	backupID, err := backup.NewBackupManager(&types.BackupConfig{Enabled: true}).CreateBackup(files)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Backup failed, nothing deleted: %v", err))
+		auditEntry.AddError(err)
+		recordAudit(auditEntry)
+		return err
+	}
+	termUI.PrintSuccess(fmt.Sprintf("Backup created: %s", backupID))
+	auditEntry.BackupID = backupID
+
+	guard := newKiroGuard(chatScanner)
+	deleted := 0
+	var freed int64
+	var errors int
+	for _, f := range files {
+		action := audit.ActionDeleted
+		if err := guard.Remove(f.Path); err != nil {
+			termUI.PrintWarning(fmt.Sprintf("%s: %v", f.Path, err))
+			auditEntry.AddError(err)
+			action = audit.ActionFailed
+			errors++
+		} else {
+			deleted++
+			freed += f.Size
+		}
+		auditEntry.AddFile(f.Path, f.Size, "duplicate", action)
+	}
+	recordAudit(auditEntry)
+
+	if deleted > 0 {
+		termUI.PrintSuccess(fmt.Sprintf("Deleted %d duplicates, freed %s", deleted, storage.FormatSize(freed)))
+	}
+	if errors > 0 {
+		termUI.PrintWarning(fmt.Sprintf("%d duplicates failed", errors))
+	}
+	return nil
+}
+
+// printDedupeChat 打印重复组中的一个对话
+func printDedupeChat(label string, c pins.Chat, fp dedupe.Chat, color pterm.Color) {
+	title := c.Title
+	if title == "" {
+		title = filepath.Base(fp.Path)
+	}
+	fmt.Printf("  %s %s  %-40s %10s %5d msgs  %s\n",
+		pterm.NewStyle(color, pterm.Bold).Sprintf("%-6s", label),
+		pterm.NewStyle(pterm.FgCyan).Sprintf("%-8s", c.ShortID()),
+		truncateMiddle(title, 40),
+		storage.FormatSize(fp.Size),
+		fp.Messages,
+		pterm.NewStyle(pterm.FgGray).Sprint(formatAge(time.Since(fp.Updated))))
+}
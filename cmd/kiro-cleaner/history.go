@@ -0,0 +1,223 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/history"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// historyCmd history command
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show file edit history usage",
+	Long:  `Show Kiro file edit history (User/History/<hash>/entries.json and versioned copies).`,
+	RunE:  runHistory,
+}
+
+// historyPruneCmd history prune command
+var historyPruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Prune file edit history",
+	Long: `Prune file edit history with a retention policy.
+entries.json is backed up and rewritten to match, and emptied history directories are removed.`,
+	RunE: runHistoryPrune,
+}
+
+var (
+	pruneKeepLast    int
+	pruneOlderThan   int
+	pruneDropMissing bool
+	pruneAll         bool
+)
+
+func init() {
+	historyCmd.AddCommand(historyPruneCmd)
+	rootCmd.AddCommand(historyCmd)
+
+	historyCmd.SetHelpFunc(customSubCmdHelpFunc)
+	historyPruneCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	historyPruneCmd.Flags().IntVar(&pruneKeepLast, "keep-last", 0, "Keep the last N versions of each file")
+	historyPruneCmd.Flags().IntVar(&pruneOlderThan, "older-than", 0, "Drop versions older than N days")
+	historyPruneCmd.Flags().BoolVar(&pruneDropMissing, "drop-missing", false, "Drop history of files that no longer exist on disk")
+	historyPruneCmd.Flags().BoolVar(&pruneAll, "all", false, "Drop all edit history")
+	historyPruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview only, no deletion")
+	historyPruneCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+}
+
+// newHistoryPruners 为每个 Kiro 数据目录创建历史清理器
+func newHistoryPruners() []*history.Pruner {
+	var pruners []*history.Pruner
+	kiroPaths, _ := storage.NewStorageDetector().FindKiroPaths()
+	for _, kiroPath := range kiroPaths {
+		pruner := history.NewPruner(history.Root(kiroPath))
+		pruner.SetBackupManager(backup.NewBackupManager(&types.BackupConfig{Enabled: true}))
+		pruners = append(pruners, pruner)
+	}
+	return pruners
+}
+
+// summaryRow 简单的名称/值行
+type summaryRow struct {
+	name  string
+	value string
+}
+
+// runHistory 显示文件编辑历史统计
+func runHistory(cmd *cobra.Command, args []string) error {
+	var files, versions, missingSources int
+	var totalSize int64
+	var oldest time.Time
+
+	for _, pruner := range newHistoryPruners() {
+		histories, err := pruner.Scan()
+		if err != nil {
+			termUI.PrintWarning(fmt.Sprintf("Failed to scan history: %v", err))
+			continue
+		}
+		for _, h := range histories {
+			files++
+			versions += len(h.Versions)
+			totalSize += h.TotalSize
+			if h.SourcePath != "" && !h.SourceExists {
+				missingSources++
+			}
+			for _, v := range h.Versions {
+				if !v.Timestamp.IsZero() && (oldest.IsZero() || v.Timestamp.Before(oldest)) {
+					oldest = v.Timestamp
+				}
+			}
+		}
+	}
+
+	if files == 0 {
+		termUI.PrintSuccess("No file edit history found")
+		return nil
+	}
+
+	items := []summaryRow{
+		{"Files", fmt.Sprintf("%d", files)},
+		{"Versions", fmt.Sprintf("%d", versions)},
+		{"Size", storage.FormatSize(totalSize)},
+		{"Deleted files", fmt.Sprintf("%d", missingSources)},
+	}
+	if !oldest.IsZero() {
+		items = append(items, summaryRow{"Oldest", formatAge(time.Since(oldest))})
+	}
+
+	termUI.PrintSection("File Edit History")
+	for _, item := range items {
+		name := pterm.NewStyle(pterm.FgMagenta, pterm.Bold).Sprintf("%-14s", item.name)
+		fmt.Printf("  %s %10s\n", name, item.value)
+	}
+
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner history prune --keep-last 10' to keep 10 versions per file",
+		"Use --older-than N and --drop-missing to drop old or orphaned history",
+	})
+	return nil
+}
+
+// runHistoryPrune 清理文件编辑历史
+func runHistoryPrune(cmd *cobra.Command, args []string) error {
+	policy := history.Policy{
+		KeepLast:    pruneKeepLast,
+		DropMissing: pruneDropMissing,
+		DropAll:     pruneAll,
+	}
+	if pruneOlderThan > 0 {
+		policy.MaxAge = time.Duration(pruneOlderThan) * 24 * time.Hour
+	}
+	if policy.IsEmpty() {
+		termUI.PrintWarning("No prune policy given")
+		termUI.PrintInfo("Use --keep-last, --older-than, --drop-missing or --all")
+		return nil
+	}
+
+	pruners := newHistoryPruners()
+	plans := make([][]history.PruneAction, len(pruners))
+	var versions, dirs, dangling int
+	var totalSize int64
+	reasonCounts := make(map[string]int)
+	for i, pruner := range pruners {
+		actions, err := pruner.Plan(policy)
+		if err != nil {
+			termUI.PrintWarning(fmt.Sprintf("Failed to scan history: %v", err))
+			continue
+		}
+		plans[i] = actions
+		for _, action := range actions {
+			versions += len(action.Drop)
+			dangling += action.Dangling
+			totalSize += action.Bytes
+			if action.RemoveDir {
+				dirs++
+			}
+			for _, reason := range action.Reasons {
+				reasonCounts[reason]++
+			}
+		}
+	}
+
+	if versions == 0 && dirs == 0 && dangling == 0 {
+		termUI.PrintSuccess("Nothing to prune")
+		return nil
+	}
+
+	termUI.PrintCleanPreview(versions, storage.FormatSize(totalSize))
+	reasons := make([]string, 0, len(reasonCounts))
+	for reason := range reasonCounts {
+		reasons = append(reasons, reason)
+	}
+	sort.Strings(reasons)
+	for _, reason := range reasons {
+		count := reasonCounts[reason]
+		fmt.Printf("  %s %-32s %d versions\n", pterm.NewStyle(pterm.FgMagenta).Sprint("●"), reason, count)
+	}
+	if dirs > 0 {
+		fmt.Printf("  %s %-32s %d\n", pterm.NewStyle(pterm.FgGray).Sprint("●"), "directories removed entirely", dirs)
+	}
+	if dangling > 0 {
+		fmt.Printf("  %s %-32s %d\n", pterm.NewStyle(pterm.FgGray).Sprint("●"), "index entries without a file", dangling)
+	}
+
+	if dryRun {
+		termUI.PrintDryRunNotice()
+		return nil
+	}
+
+	if !yes && !config.LoadConfig().SkipConfirm {
+		if !termUI.Confirm("Prune this history?") {
+			pterm.Info.Println("Cancelled")
+			return nil
+		}
+	}
+
+	auditEntry := startAudit(cmd, args)
+	var removed, errCount int
+	var freed int64
+	for i, pruner := range pruners {
+		result := pruner.Apply(plans[i])
+		auditHistory(auditEntry, plans[i], result)
+		removed += result.VersionsRemoved
+		freed += result.FreedBytes
+		errCount += len(result.Errors)
+		if verbose {
+			for _, e := range result.Errors {
+				termUI.PrintWarning(e.Error())
+			}
+		}
+	}
+
+	recordAudit(auditEntry)
+	termUI.PrintCleanResult(removed, storage.FormatSize(freed), errCount)
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+)
+
+// installsCmd installs command
+var installsCmd = &cobra.Command{
+	Use:   "installs",
+	Short: "List the Kiro installations found on this machine",
+	Long: `List every Kiro user data directory found on this machine: the default
+location, other release channels (e.g. Insiders), Flatpak and Snap sandboxes,
+portable installs, KIRO_USER_DATA_DIR, --user-data-dir of running Kiro
+processes and the "kiro_paths" entries of ~/.kiro-cleaner/config.json.
+
+Use the label with 'scan --install' or 'clean --install' to work on a single
+installation. Without --install all of them are scanned and cleaned.`,
+	RunE: runInstalls,
+}
+
+var (
+	scanInstall  string
+	cleanInstall string
+)
+
+func init() {
+	rootCmd.AddCommand(installsCmd)
+	installsCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	scanCmd.Flags().StringVar(&scanInstall, "install", "", "Only scan the Kiro installation with this label (see 'kiro-cleaner installs')")
+	cleanCmd.Flags().StringVar(&cleanInstall, "install", "", "Only clean the Kiro installation with this label (see 'kiro-cleaner installs')")
+
+	// config.json 中的 kiro_paths
+	storage.RegisterSource(storage.SourceFunc{SourceName: "config", Find: configInstallCandidates})
+}
+
+// configInstallCandidates 返回配置文件 kiro_paths 中的目录
+func configInstallCandidates() []storage.Candidate {
+	var list []storage.Candidate
+	for _, path := range config.LoadConfig().KiroPaths {
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		list = append(list, storage.Candidate{Label: "config", Path: path})
+	}
+	return list
+}
+
+// runInstalls 列出发现的 Kiro 安装
+func runInstalls(cmd *cobra.Command, args []string) error {
+	installs := storage.DiscoverInstalls()
+	if len(installs) == 0 {
+		termUI.PrintWarning("No Kiro installation found")
+		termUI.PrintInfo(fmt.Sprintf("Set %s or add the directory to \"kiro_paths\" in the config", storage.UserDataDirEnv))
+		return nil
+	}
+	printInstalls(installs)
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner scan --install <label>' to scan a single installation",
+		"Run 'kiro-cleaner clean --install <label>' to clean a single installation",
+	})
+	return nil
+}
+
+// printInstalls 打印安装列表
+func printInstalls(installs []storage.Install) {
+	termUI.PrintSection("Kiro Installations")
+	for _, in := range installs {
+		fmt.Printf("  %s %s  %s\n",
+			pterm.NewStyle(pterm.FgCyan, pterm.Bold).Sprintf("%-16s", in.Label),
+			pterm.NewStyle(pterm.FgGray).Sprintf("%-9s", in.Source),
+			in.Path)
+	}
+	fmt.Println()
+}
+
+// selectInstall 按 --install 限定后续扫描和清理的安装，失败时打印错误和可选标签
+func selectInstall(label string) bool {
+	if label == "" {
+		return true
+	}
+	if err := storage.UseInstall(label); err != nil {
+		termUI.PrintError(fmt.Sprintf("Unknown install %q", label))
+		if installs := storage.DiscoverInstalls(); len(installs) > 0 {
+			printInstalls(installs)
+		}
+		return false
+	}
+	in, _ := storage.SelectedInstall()
+	termUI.PrintInfo(fmt.Sprintf("Using %s install: %s", in.Label, in.Path))
+	return true
+}
+
+// displayInstalls 发现多个安装且没有用 --install 限定时，说明扫描结果包含了哪些安装
+func displayInstalls() {
+	if _, ok := storage.SelectedInstall(); ok {
+		return
+	}
+	installs := storage.DiscoverInstalls()
+	if len(installs) < 2 {
+		return
+	}
+	printInstalls(installs)
+	termUI.PrintInfo("Results above include all installations, use --install <label> to pick one")
+}
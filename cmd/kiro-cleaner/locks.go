@@ -0,0 +1,102 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+)
+
+// lockedFiles 被进程打开的文件，按路径索引
+type lockedFiles map[string]utils.OpenFile
+
+// detectLockedFiles 检测 Kiro 数据目录下被打开的文件
+// 返回 false 表示当前系统无法精确检测（非 Linux 或 procfs 不可用）
+func detectLockedFiles(roots []string) (lockedFiles, bool) {
+	open, err := utils.OpenFilesUnder(roots)
+	if err != nil {
+		return nil, false
+	}
+	locked := make(lockedFiles, len(open))
+	for _, f := range open {
+		locked[resolvePath(f.Path)] = f
+	}
+	return locked, true
+}
+
+// resolvePath 解析路径中的符号链接，使扫描得到的路径能和 /proc/<pid>/fd 中的真实路径比较
+// 文件本身已不存在时只解析所在目录
+func resolvePath(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	if dir, err := filepath.EvalSymlinks(filepath.Dir(path)); err == nil {
+		return filepath.Join(dir, filepath.Base(path))
+	}
+	return filepath.Clean(path)
+}
+
+// holds 判断文件被打开，或目录内有文件被打开
+func (l lockedFiles) holds(path string) bool {
+	if len(l) == 0 {
+		return false
+	}
+	path = resolvePath(path)
+	if _, ok := l[path]; ok {
+		return true
+	}
+	prefix := path + string(filepath.Separator)
+	for p := range l {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// printLockedSummary 按进程汇总被跳过的文件
+func (l lockedFiles) printLockedSummary(skipped []string) {
+	type holder struct {
+		name  string
+		pid   int
+		count int
+	}
+	holders := make(map[int]*holder)
+	for _, path := range skipped {
+		path = resolvePath(path)
+		prefix := path + string(filepath.Separator)
+		for p, f := range l {
+			if p != path && !strings.HasPrefix(p, prefix) {
+				continue
+			}
+			h, ok := holders[f.PID]
+			if !ok {
+				h = &holder{name: f.Process, pid: f.PID}
+				holders[f.PID] = h
+			}
+			h.count++
+		}
+	}
+
+	list := make([]*holder, 0, len(holders))
+	for _, h := range holders {
+		list = append(list, h)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].pid < list[j].pid })
+
+	termUI.PrintWarning(fmt.Sprintf("Skipping %d items held open by running processes", len(skipped)))
+	for _, h := range list {
+		fmt.Printf("  %s %-28s %s\n",
+			pterm.NewStyle(pterm.FgYellow).Sprint("●"),
+			fmt.Sprintf("%s [pid %d]", h.name, h.pid),
+			pterm.NewStyle(pterm.FgGray).Sprintf("%d open files", h.count))
+	}
+	if verbose {
+		for _, path := range skipped {
+			fmt.Printf("    %s\n", pterm.NewStyle(pterm.FgGray).Sprint(path))
+		}
+	}
+}
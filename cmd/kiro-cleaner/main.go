@@ -19,11 +19,12 @@ var (
 )
 
 var (
-	cfgFile   string
-	verbose   bool
-	backup    bool
-	output    string
-	configDir string
+	cfgFile    string
+	verbose    bool
+	withBackup bool
+	output     string
+	configDir  string
+	exitCode   int // 命令设置的退出码（auto 用于区分运行结果）
 
 	rootCmd = &cobra.Command{
 		Use:   "kiro-cleaner",
@@ -77,7 +78,9 @@ func customHelpFunc(cmd *cobra.Command, args []string) {
 		{"kiro-cleaner clean --dry-run", "Preview what will be cleaned"},
 		{"kiro-cleaner clean", "Clean all redundant data"},
 		{"kiro-cleaner clean --kill-kiro", "Stop Kiro and clean"},
+		{"kiro-cleaner clean --restart-kiro", "Stop Kiro, clean, then relaunch it"},
 		{"kiro-cleaner clean --keep-chats", "Clean but keep conversations"},
+		{"kiro-cleaner clean --history-keep-last 10", "Keep 10 edit versions per file"},
 	}
 	for _, e := range examples {
 		dollar := pterm.NewStyle(pterm.FgGray).Sprint("$")
@@ -102,13 +105,25 @@ func customHelpFunc(cmd *cobra.Command, args []string) {
 		desc  string
 		color pterm.Color
 	}{
+		{"audit", "Show what past clean runs did", pterm.FgBlue},
+		{"auto", "Clean what crossed a threshold (cron)", pterm.FgRed},
+		{"chats", "List conversations, pin the ones to keep", pterm.FgCyan},
 		{"clean", "Clean up all redundant data", pterm.FgRed},
 		{"completion", "Generate shell autocompletion script", pterm.FgBlue},
 		{"config", "Show or edit global config", pterm.FgYellow},
+		{"crashpad", "List, export or clean crash reports", pterm.FgLightRed},
 		{"help", "Help about any command", pterm.FgWhite},
+		{"history", "Show or prune file edit history", pterm.FgMagenta},
 		{"install", "Install kiro-cleaner to system PATH", pterm.FgGreen},
+		{"installs", "List Kiro installations and channels", pterm.FgCyan},
+		{"quarantine", "List, restore or purge quarantined files", pterm.FgYellow},
 		{"scan", "Scan storage usage", pterm.FgGreen},
+		{"schedule", "Run auto daily/weekly via systemd or cron", pterm.FgBlue},
+		{"sessions", "Show or clean workspace sessions", pterm.FgCyan},
+		{"stats", "Usage analytics by model and workflow", pterm.FgGreen},
+		{"trend", "Show storage growth over time", pterm.FgYellow},
 		{"uninstall", "Remove kiro-cleaner from system PATH", pterm.FgMagenta},
+		{"watch", "Watch data directories grow in real time", pterm.FgCyan},
 	}
 	for _, c := range allCommands {
 		name := pterm.NewStyle(c.color, pterm.Bold).Sprintf("%-12s", c.name)
@@ -234,6 +249,7 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
+	os.Exit(exitCode)
 }
 
 func init() {
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/policy"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/sessions"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// maxPolicyRows scan 中最多列出的工作区数量
+const maxPolicyRows = 20
+
+// loadWorkspacePolicies 找出 Kiro 记录的工作区并读取其项目策略文件
+func loadWorkspacePolicies(chatScanner *scanner.ChatScanner) *policy.Set {
+	agentPath, _ := chatScanner.FindKiroAgentPath()
+	kiroPaths, _ := storage.NewStorageDetector().FindKiroPaths()
+	return policy.NewSet(policy.DiscoverRoots(agentPath, kiroPaths))
+}
+
+// warnPolicyErrors 提示无法读取的策略文件；这些工作区按全局配置处理
+func warnPolicyErrors(policies *policy.Set) {
+	for _, ws := range policies.Workspaces() {
+		if ws.Err != nil {
+			termUI.PrintWarning(fmt.Sprintf("Ignoring project policy: %v", ws.Err))
+		}
+	}
+}
+
+// chatPolicyAction 项目策略对单个对话的处理结果
+type chatPolicyAction int
+
+const (
+	chatUseGlobal chatPolicyAction = iota // 没有项目规则，按全局配置处理
+	chatKeep                              // 项目策略要求保留
+	chatPurge                             // 超过项目的保留天数，应清理
+)
+
+// chatPolicy 根据项目策略决定对话的处理方式
+func chatPolicy(p *policy.Policy, modified, now time.Time) chatPolicyAction {
+	switch {
+	case p.Protects(policy.ScopeChats):
+		return chatKeep
+	case p != nil && p.ChatRetentionDays > 0:
+		if modified.After(now.AddDate(0, 0, -p.ChatRetentionDays)) {
+			return chatKeep
+		}
+		return chatPurge
+	}
+	return chatUseGlobal
+}
+
+// sessionPolicy 返回工作区会话目录对应项目的策略
+func sessionPolicy(policies *policy.Set, s sessions.Session) *policy.Policy {
+	return policies.PolicyForPath(sessions.DecodeWorkspacePath(filepath.Base(s.Dir)))
+}
+
+// exportPolicyChats 导出项目策略要求先导出的对话
+// 导出失败时这些对话从清理列表中移除（保留文件），返回剩余的清理项
+func exportPolicyChats(items []cleanItem) ([]cleanItem, string, error) {
+	var files []types.FileInfo
+	for _, item := range items {
+		if item.export {
+			files = append(files, types.FileInfo{Path: item.path, Size: item.size})
+		}
+	}
+	if len(files) == 0 {
+		return items, "", nil
+	}
+
+	mgr := backup.NewBackupManager(&types.BackupConfig{Enabled: true, Path: config.ExportsDir()})
+	exportID, err := mgr.CreateBackup(files)
+	if err == nil {
+		return items, filepath.Join(mgr.GetBackupDir(), exportID+".zip"), nil
+	}
+
+	var kept []cleanItem
+	for _, item := range items {
+		if !item.export {
+			kept = append(kept, item)
+		}
+	}
+	return kept, "", err
+}
+
+// countExports 统计需要先导出的清理项
+func countExports(items []cleanItem) int {
+	n := 0
+	for _, item := range items {
+		if item.export {
+			n++
+		}
+	}
+	return n
+}
+
+// displayWorkspacePolicies 显示每个工作区适用的策略
+func displayWorkspacePolicies(policies *policy.Set) {
+	workspaces := policies.Workspaces()
+	if len(workspaces) == 0 {
+		return
+	}
+
+	termUI.PrintSection("Workspace Policies")
+	for i, ws := range workspaces {
+		if i == maxPolicyRows {
+			fmt.Printf("  %s\n", pterm.NewStyle(pterm.FgGray).Sprintf("... and %d more", len(workspaces)-maxPolicyRows))
+			break
+		}
+		source := pterm.NewStyle(pterm.FgGray).Sprint("global")
+		detail := ""
+		switch {
+		case ws.Err != nil:
+			source = pterm.NewStyle(pterm.FgRed).Sprint("invalid")
+			detail = "using global config"
+		case ws.Policy != nil:
+			source = pterm.NewStyle(pterm.FgYellow).Sprint(filepath.Base(ws.Policy.Path))
+			detail = ws.Policy.Summary()
+		}
+		fmt.Printf("  %s %s  %s\n",
+			pterm.NewStyle(pterm.FgCyan).Sprintf("%-40s", truncateMiddle(ws.Root, 40)),
+			source, detail)
+	}
+	warnPolicyErrors(policies)
+	if len(policies.WithPolicy()) == 0 {
+		termUI.PrintInfo(fmt.Sprintf("Add %s to a project root to set per-project rules", policy.FileNames[0]))
+	}
+}
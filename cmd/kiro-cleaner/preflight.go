@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/preflight"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// backupPreflight --backup 的执行方式
+type backupPreflight struct {
+	files      []types.FileInfo
+	manager    *backup.BackupManager
+	quarantine *preflight.Quarantine // 非 nil 表示空间不足，改为隔离（同卷重命名）
+}
+
+// prepareBackup 估算备份大小并检查备份卷剩余空间
+// 空间不足且未开启 quarantine_fallback 时返回 false，调用方应拒绝清理
+func prepareBackup(items []cleanItem, safety *types.SafetyConfig) (*backupPreflight, bool) {
+	plan := &backupPreflight{
+		manager: backup.NewBackupManager(&types.BackupConfig{Enabled: true}),
+	}
+	for _, item := range items {
+		// 编辑历史和崩溃报告由各自的模块备份/导出
+		if item.viaHistory || item.viaCrashpad {
+			continue
+		}
+		plan.files = append(plan.files, types.FileInfo{Path: item.path, Size: item.size})
+	}
+
+	check, err := preflight.Check(plan.files, plan.manager.GetBackupDir(), safety)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Disk space preflight failed: %v", err))
+		return nil, false
+	}
+	if check.OK {
+		termUI.PrintInfo(fmt.Sprintf("Backup: ~%s to %s (%s free)",
+			storage.FormatSize(check.EstimatedBackup), check.BackupDir, storage.FormatSize(check.FreeBytes)))
+		return plan, true
+	}
+
+	termUI.PrintWarning(fmt.Sprintf("Not enough disk space for a backup: need ~%s + %s minimum free, %s has %s",
+		storage.FormatSize(check.EstimatedBackup), storage.FormatSize(check.MinFree),
+		check.BackupDir, storage.FormatSize(check.FreeBytes)))
+
+	if safety == nil || !safety.QuarantineFallback {
+		termUI.PrintTips([]string{
+			fmt.Sprintf("Free up at least %s on the backup volume", storage.FormatSize(check.Shortfall())),
+			"Lower safety.min_disk_space in the config, or run without --backup",
+			"Set safety.quarantine_fallback to true to move files aside instead",
+		})
+		return nil, false
+	}
+
+	roots, _ := storage.NewStorageDetector().FindKiroPaths()
+	plan.quarantine = preflight.NewQuarantine(roots)
+	if len(roots) > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Files will be moved to %s instead of being backed up and deleted", plan.quarantine.Dir(roots[0])))
+	}
+	return plan, true
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/profile"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// configProfileCmd config profile command
+var configProfileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "List or show cleanup profiles",
+	Long: `Cleanup profiles are named presets for 'clean --profile' and 'scan --profile'.
+Built-in profiles: light, weekly, nuclear. Add or override profiles in the
+"profiles" section of ~/.kiro-cleaner/config.json.`,
+	RunE: runConfigProfileList,
+}
+
+// configProfileListCmd config profile list command
+var configProfileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List built-in and configured profiles",
+	RunE:  runConfigProfileList,
+}
+
+// configProfileShowCmd config profile show command
+var configProfileShowCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show what a profile cleans",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigProfileShow,
+}
+
+var (
+	cleanProfile string
+	scanProfile  string
+)
+
+func init() {
+	configProfileCmd.AddCommand(configProfileListCmd)
+	configProfileCmd.AddCommand(configProfileShowCmd)
+	configCmd.AddCommand(configProfileCmd)
+
+	configProfileCmd.SetHelpFunc(customSubCmdHelpFunc)
+	configProfileListCmd.SetHelpFunc(customSubCmdHelpFunc)
+	configProfileShowCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	cleanCmd.Flags().StringVar(&cleanProfile, "profile", "", "Use a cleanup profile (light, weekly, nuclear or one from config)")
+	scanCmd.Flags().StringVar(&scanProfile, "profile", "", "Show what a cleanup profile would clean")
+}
+
+// profileFileCategories 文件类型对应的预设类别
+var profileFileCategories = map[types.FileType]string{
+	types.TypeTemp:   profile.CategoryTemp,
+	types.TypeLog:    profile.CategoryLogs,
+	types.TypeCache:  profile.CategoryCache,
+	types.TypeIndex:  profile.CategoryIndex,
+	types.TypeBackup: profile.CategoryHistory,
+	types.TypeCrash:  profile.CategoryCrash,
+	types.TypeOrphan: profile.CategoryOrphans,
+}
+
+// loadProfile 按名称加载预设，失败时打印错误和可选名称
+func loadProfile(name string, cfg *config.GlobalConfig) (*profile.Profile, bool) {
+	p, err := profile.Lookup(name, cfg.Profiles)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Invalid profile: %v", err))
+		termUI.PrintInfo("Run 'kiro-cleaner config profile list' to see available profiles")
+		return nil, false
+	}
+	return &p, true
+}
+
+// applyProfile 用预设设置清理选项，显式指定的命令行参数仍然优先
+func applyProfile(cmd *cobra.Command, p *profile.Profile) {
+	flags := cmd.Flags()
+	if !flags.Changed("keep-logs") {
+		keepLogs = !p.Includes(profile.CategoryLogs)
+	}
+	if !flags.Changed("keep-cache") {
+		keepCache = !p.Includes(profile.CategoryCache)
+	}
+	if !flags.Changed("keep-chats") {
+		keepChats = !p.Includes(profile.CategoryChats)
+	}
+	if !flags.Changed("keep-index") {
+		keepIndex = !p.Includes(profile.CategoryIndex)
+	}
+	if !flags.Changed("keep-recent") {
+		keepRecent = p.KeepRecent
+	}
+	if !flags.Changed("history-older-than") && !flags.Changed("history-keep-last") {
+		historyOlderThan = p.MinAgeDays(profile.CategoryHistory)
+	}
+}
+
+// keepOrphans 判断是否保留孤立的快照和差异内容
+// 它们以前按缓存清理，所以没有预设时跟随 --keep-cache；显式的 --keep-cache 总是保留
+func keepOrphans(p *profile.Profile, keepCache, keepCacheSet bool) bool {
+	if keepCache && keepCacheSet {
+		return true
+	}
+	if p != nil {
+		return !p.Includes(profile.CategoryOrphans)
+	}
+	return keepCache
+}
+
+// categoryDays 返回类别的保留天数（--keep-recent 和预设中的 older_than 取较长者）
+func categoryDays(p *profile.Profile, keepRecentDays int, category string) int {
+	days := keepRecentDays
+	if p != nil && p.OlderThan[category] > days {
+		days = p.OlderThan[category]
+	}
+	return days
+}
+
+// categoryCutoffs 计算每个类别的保留截止时间
+func categoryCutoffs(p *profile.Profile, keepRecentDays int, now time.Time) map[string]time.Time {
+	cutoffs := make(map[string]time.Time)
+	for _, c := range profile.Categories {
+		if days := categoryDays(p, keepRecentDays, c); days > 0 {
+			cutoffs[c] = now.AddDate(0, 0, -days)
+		}
+	}
+	return cutoffs
+}
+
+// tooRecent 判断文件是否在类别的保留期内
+func tooRecent(cutoffs map[string]time.Time, category string, modified time.Time) bool {
+	cutoff, ok := cutoffs[category]
+	return ok && modified.After(cutoff)
+}
+
+// runConfigProfileList 列出所有预设
+func runConfigProfileList(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadConfig()
+	termUI.PrintSection("Profiles")
+	for _, p := range profile.List(cfg.Profiles) {
+		source := pterm.NewStyle(pterm.FgGray).Sprint("built-in")
+		if !p.Builtin {
+			source = pterm.NewStyle(pterm.FgYellow).Sprint("config")
+		}
+		if err := p.Validate(); err != nil {
+			source = pterm.NewStyle(pterm.FgRed).Sprint("invalid")
+		}
+		fmt.Printf("  %s %-8s %s\n", pterm.NewStyle(pterm.FgCyan, pterm.Bold).Sprintf("%-10s", p.Name), source, p.Summary())
+	}
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner config profile show <name>' for details",
+		"Use 'kiro-cleaner clean --profile <name> --dry-run' to preview a profile",
+		fmt.Sprintf("Add your own profiles under \"profiles\" in %s", config.ConfigPath()),
+	})
+	return nil
+}
+
+// runConfigProfileShow 显示单个预设
+func runConfigProfileShow(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadConfig()
+	p, ok := loadProfile(args[0], cfg)
+	if !ok {
+		return nil
+	}
+
+	termUI.PrintSection("Profile")
+	source := "built-in"
+	if !p.Builtin {
+		source = "config"
+	}
+	rows := []summaryRow{
+		{"Name", p.Name},
+		{"Source", source},
+		{"Purpose", p.Description},
+		{"Keep", ""},
+	}
+	if p.KeepRecent > 0 {
+		rows[3].value = fmt.Sprintf("files modified in the last %d days", p.KeepRecent)
+	}
+	for _, row := range rows {
+		if row.value == "" {
+			continue
+		}
+		name := pterm.NewStyle(pterm.FgMagenta, pterm.Bold).Sprintf("%-10s", row.name)
+		fmt.Printf("  %s %s\n", name, row.value)
+	}
+
+	fmt.Println()
+	for _, c := range profile.Categories {
+		mark := pterm.NewStyle(pterm.FgGray).Sprint("keep ")
+		detail := ""
+		if p.Includes(c) {
+			mark = pterm.NewStyle(pterm.FgRed, pterm.Bold).Sprint("clean")
+			if days := p.MinAgeDays(c); days > 0 {
+				detail = fmt.Sprintf("older than %d days", days)
+			}
+		}
+		fmt.Printf("  %s  %-8s %s\n", mark, c, detail)
+	}
+
+	var flags []string
+	for _, c := range []string{profile.CategoryLogs, profile.CategoryCache, profile.CategoryChats, profile.CategoryIndex} {
+		if !p.Includes(c) {
+			flags = append(flags, "--keep-"+c)
+		}
+	}
+	tips := []string{fmt.Sprintf("Run 'kiro-cleaner clean --profile %s --dry-run' to preview", p.Name)}
+	if len(flags) > 0 {
+		tips = append(tips, fmt.Sprintf("Roughly the same as: clean %s", strings.Join(flags, " ")))
+	}
+	termUI.PrintTips(tips)
+	return nil
+}
+
+// scanSelection scan --profile 的统计范围
+type scanSelection struct {
+	profile   *profile.Profile
+	chatSize  int64 // 预设会清理的对话大小
+	chatCount int
+}
+
+// newScanSelection 按预设统计会被清理的对话
+func newScanSelection(p *profile.Profile, chatScanner *scanner.ChatScanner) *scanSelection {
+	sel := &scanSelection{profile: p}
+	if !p.Includes(profile.CategoryChats) {
+		return sel
+	}
+	chats, err := chatScanner.FindCleanableConversations(p.MinAgeDays(profile.CategoryChats), 0)
+	if err != nil {
+		return sel
+	}
+	for _, chat := range chats {
+		sel.chatSize += chat.Size
+		sel.chatCount++
+	}
+	return sel
+}
+
+// cleanableSizes 按预设统计各类别可清理的文件大小和数量
+func (sel *scanSelection) cleanableSizes(files []types.FileInfo) (map[string]int64, map[string]int) {
+	sizes := make(map[string]int64)
+	counts := make(map[string]int)
+	cutoffs := categoryCutoffs(sel.profile, 0, time.Now())
+	for _, file := range files {
+		category, ok := profileFileCategories[file.FileType]
+		if !ok || category == profile.CategoryCrash || !sel.profile.Includes(category) {
+			continue
+		}
+		if tooRecent(cutoffs, category, file.Modified) {
+			continue
+		}
+		sizes[category] += file.Size
+		counts[category]++
+	}
+	return sizes, counts
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/profile"
+)
+
+func TestKeepOrphans(t *testing.T) {
+	light := &profile.Profile{Name: "light", Categories: []string{profile.CategoryTemp, profile.CategoryOrphans}}
+	cacheOnly := &profile.Profile{Name: "cache", Categories: []string{profile.CategoryCache}}
+
+	cases := []struct {
+		name         string
+		prof         *profile.Profile
+		keepCache    bool
+		keepCacheSet bool
+		want         bool
+	}{
+		{"clean", nil, false, false, false},
+		{"clean --keep-cache", nil, true, true, true},
+		{"keep_cache in config", nil, true, false, true},
+		{"profile with orphans", light, true, false, false},
+		{"profile with orphans --keep-cache", light, true, true, true},
+		{"profile without orphans", cacheOnly, false, false, true},
+	}
+	for _, c := range cases {
+		if got := keepOrphans(c.prof, c.keepCache, c.keepCacheSet); got != c.want {
+			t.Errorf("%s: keepOrphans = %v, 期望 %v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/preflight"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+)
+
+// quarantineCmd quarantine command
+var quarantineCmd = &cobra.Command{
+	Use:   "quarantine",
+	Short: "List files moved aside instead of deleted",
+	Long: `List quarantine batches, newest first. When a backup does not fit on disk,
+clean moves files into .kiro-cleaner-quarantine next to the Kiro data directory.
+These files still use disk space until they are restored or purged.`,
+	RunE: runQuarantine,
+}
+
+// quarantineRestoreCmd quarantine restore command
+var quarantineRestoreCmd = &cobra.Command{
+	Use:   "restore <batch-id>",
+	Short: "Move the files of a batch back to where they were",
+	Long: `Move the files of a quarantine batch back to their original paths.
+Files whose original path exists again are kept in the quarantine.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runQuarantineRestore,
+}
+
+// quarantinePurgeCmd quarantine purge command
+var quarantinePurgeCmd = &cobra.Command{
+	Use:   "purge [batch-id...]",
+	Short: "Permanently delete quarantine batches",
+	RunE:  runQuarantinePurge,
+}
+
+var quarantinePurgeAll bool
+
+func init() {
+	quarantineCmd.AddCommand(quarantineRestoreCmd)
+	quarantineCmd.AddCommand(quarantinePurgeCmd)
+	rootCmd.AddCommand(quarantineCmd)
+
+	for _, c := range []*cobra.Command{quarantineCmd, quarantineRestoreCmd, quarantinePurgeCmd} {
+		c.SetHelpFunc(customSubCmdHelpFunc)
+	}
+
+	quarantinePurgeCmd.Flags().BoolVar(&quarantinePurgeAll, "all", false, "Purge every batch")
+	quarantinePurgeCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+}
+
+// listQuarantine 列出所有 Kiro 数据目录对应的隔离批次
+func listQuarantine() ([]string, []preflight.QuarantineBatch, error) {
+	roots, _ := storage.NewStorageDetector().FindKiroPaths()
+	batches, err := preflight.ListQuarantine(roots)
+	return roots, batches, err
+}
+
+// findQuarantineBatch 按 ID 查找隔离批次
+func findQuarantineBatch(batches []preflight.QuarantineBatch, id string) (preflight.QuarantineBatch, bool) {
+	for _, b := range batches {
+		if b.ID == id {
+			return b, true
+		}
+	}
+	return preflight.QuarantineBatch{}, false
+}
+
+// printQuarantineSummary 清理后提示隔离目录的位置和占用的空间
+func printQuarantineSummary(q *preflight.Quarantine, moved int64) {
+	termUI.PrintInfo(fmt.Sprintf("%s moved to quarantine, still on disk", storage.FormatSize(moved)))
+	_, batches, err := listQuarantine()
+	if err != nil {
+		termUI.PrintWarning(fmt.Sprintf("Failed to read quarantine: %v", err))
+		return
+	}
+	var total int64
+	for _, b := range batches {
+		total += b.Size
+		if b.ID == q.ID() {
+			termUI.PrintInfo(fmt.Sprintf("Batch %s: %s", b.ID, b.Dir))
+		}
+	}
+	termUI.PrintTips([]string{
+		fmt.Sprintf("Quarantine holds %s in %d batches", storage.FormatSize(total), len(batches)),
+		fmt.Sprintf("Run 'kiro-cleaner quarantine purge %s' to free the space, or 'restore' to undo", q.ID()),
+	})
+}
+
+// runQuarantine 列出隔离批次
+func runQuarantine(cmd *cobra.Command, args []string) error {
+	_, batches, err := listQuarantine()
+	if err != nil {
+		termUI.PrintError(err.Error())
+		return err
+	}
+	if len(batches) == 0 {
+		termUI.PrintSuccess("Quarantine is empty")
+		return nil
+	}
+
+	termUI.PrintSection("Quarantine")
+	var total int64
+	for _, b := range batches {
+		total += b.Size
+		fmt.Printf("  %s %5d files %10s  %s\n",
+			pterm.NewStyle(pterm.FgCyan).Sprint(b.ID),
+			len(b.Entries),
+			storage.FormatSize(b.Size),
+			pterm.NewStyle(pterm.FgGray).Sprint(b.Dir))
+		if verbose {
+			for _, e := range b.Entries {
+				fmt.Printf("      %s\n", pterm.NewStyle(pterm.FgGray).Sprint(e.Original))
+			}
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("─────────────────────────────────────────────")
+	fmt.Printf("  %s %d batches, %s\n",
+		pterm.NewStyle(pterm.FgWhite, pterm.Bold).Sprint("Total"),
+		len(batches),
+		pterm.NewStyle(pterm.FgYellow, pterm.Bold).Sprint(storage.FormatSize(total)))
+
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner quarantine restore <batch-id>' to move files back",
+		"Run 'kiro-cleaner quarantine purge <batch-id>' or '--all' to free the space",
+	})
+	return nil
+}
+
+// runQuarantineRestore 把一个批次的文件移回原位置
+func runQuarantineRestore(cmd *cobra.Command, args []string) error {
+	roots, batches, err := listQuarantine()
+	if err != nil {
+		termUI.PrintError(err.Error())
+		return err
+	}
+	batch, ok := findQuarantineBatch(batches, args[0])
+	if !ok {
+		err := fmt.Errorf("quarantine batch not found: %s", args[0])
+		termUI.PrintError(err.Error())
+		return err
+	}
+
+	auditEntry := startAudit(cmd, args)
+	restored, errs := batch.Restore(roots)
+	for _, e := range restored {
+		auditEntry.AddFile(e.Original, e.Size, "quarantine", audit.ActionRestored)
+	}
+	for _, err := range errs {
+		auditEntry.AddError(err)
+		termUI.PrintWarning(err.Error())
+	}
+	recordAudit(auditEntry)
+
+	termUI.PrintSuccess(fmt.Sprintf("Restored %d of %d files from %s", len(restored), len(batch.Entries), batch.ID))
+	if len(errs) > 0 {
+		return fmt.Errorf("%d files not restored", len(errs))
+	}
+	return nil
+}
+
+// runQuarantinePurge 永久删除隔离批次
+func runQuarantinePurge(cmd *cobra.Command, args []string) error {
+	if quarantinePurgeAll == (len(args) > 0) {
+		err := fmt.Errorf("specify batch IDs or --all")
+		termUI.PrintError(err.Error())
+		return err
+	}
+	_, batches, err := listQuarantine()
+	if err != nil {
+		termUI.PrintError(err.Error())
+		return err
+	}
+
+	targets := batches
+	if !quarantinePurgeAll {
+		targets = nil
+		for _, id := range args {
+			batch, ok := findQuarantineBatch(batches, id)
+			if !ok {
+				err := fmt.Errorf("quarantine batch not found: %s", id)
+				termUI.PrintError(err.Error())
+				return err
+			}
+			targets = append(targets, batch)
+		}
+	}
+	if len(targets) == 0 {
+		termUI.PrintSuccess("Quarantine is empty")
+		return nil
+	}
+
+	var total int64
+	for _, b := range targets {
+		total += b.Size
+	}
+	termUI.PrintSection(fmt.Sprintf("Purge %d batches (%s)", len(targets), storage.FormatSize(total)))
+	for _, b := range targets {
+		fmt.Printf("  %s %s %10s\n", pterm.NewStyle(pterm.FgRed).Sprint("●"), b.ID, storage.FormatSize(b.Size))
+	}
+	if !yes && !config.LoadConfig().SkipConfirm {
+		if !termUI.Confirm("Permanently delete these batches?") {
+			pterm.Info.Println("Cancelled")
+			return nil
+		}
+	}
+
+	auditEntry := startAudit(cmd, args)
+	var purged int
+	var freed int64
+	var errs int
+	for _, b := range targets {
+		if err := b.Purge(); err != nil {
+			errs++
+			auditEntry.AddError(err)
+			auditEntry.AddFile(b.Dir, b.Size, "quarantine", audit.ActionFailed)
+			continue
+		}
+		purged++
+		freed += b.Size
+		auditEntry.AddFile(b.Dir, b.Size, "quarantine", audit.ActionDeleted)
+	}
+	recordAudit(auditEntry)
+	termUI.PrintCleanResult(purged, storage.FormatSize(freed), errs)
+	return nil
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/schedule"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+)
+
+// scheduleCmd schedule command
+var scheduleCmd = &cobra.Command{
+	Use:   "schedule",
+	Short: "Run 'auto' on a schedule with systemd or cron",
+	Long: `Install, remove or inspect a scheduled 'kiro-cleaner auto' run.
+Uses a systemd user timer when systemd is available, otherwise a crontab entry.`,
+	RunE: runScheduleStatus,
+}
+
+// scheduleInstallCmd schedule install command
+var scheduleInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install or replace the scheduled auto-clean",
+	RunE:  runScheduleInstall,
+}
+
+// scheduleRemoveCmd schedule remove command
+var scheduleRemoveCmd = &cobra.Command{
+	Use:   "remove",
+	Short: "Remove the scheduled auto-clean",
+	RunE:  runScheduleRemove,
+}
+
+// scheduleStatusCmd schedule status command
+var scheduleStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the next run and the result of the last run",
+	RunE:  runScheduleStatus,
+}
+
+var (
+	scheduleEvery   string
+	scheduleProfile string
+)
+
+func init() {
+	scheduleCmd.AddCommand(scheduleInstallCmd)
+	scheduleCmd.AddCommand(scheduleRemoveCmd)
+	scheduleCmd.AddCommand(scheduleStatusCmd)
+	rootCmd.AddCommand(scheduleCmd)
+
+	scheduleCmd.SetHelpFunc(customSubCmdHelpFunc)
+	scheduleInstallCmd.SetHelpFunc(customSubCmdHelpFunc)
+	scheduleRemoveCmd.SetHelpFunc(customSubCmdHelpFunc)
+	scheduleStatusCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	scheduleInstallCmd.Flags().StringVar(&scheduleEvery, "every", schedule.CadenceDaily,
+		"How often to run: "+strings.Join(schedule.Cadences, "|"))
+	scheduleInstallCmd.Flags().StringVar(&scheduleProfile, "profile", "", "Thresholds from auto.profiles.<name> (default: the auto section)")
+}
+
+// runScheduleInstall 安装计划任务
+func runScheduleInstall(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadConfig()
+	if _, err := cfg.Auto.AutoProfile(scheduleProfile); err != nil {
+		termUI.PrintError(fmt.Sprintf("Invalid auto profile: %v", err))
+		termUI.PrintInfo(fmt.Sprintf("Available profiles: %s", autoProfileNames(cfg.Auto)))
+		return nil
+	}
+
+	execPath, err := os.Executable()
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to get executable path: %v", err))
+		return err
+	}
+	if resolved, err := filepath.EvalSymlinks(execPath); err == nil {
+		execPath = resolved
+	}
+	if strings.HasPrefix(execPath, os.TempDir()) {
+		termUI.PrintWarning("The binary lives in a temporary directory; run 'kiro-cleaner install' first")
+	}
+
+	spec := schedule.Spec{
+		Binary:  execPath,
+		Args:    []string{"auto"},
+		Cadence: scheduleEvery,
+	}
+	if scheduleProfile != "" {
+		spec.Args = append(spec.Args, "--profile", scheduleProfile)
+	}
+	if err := spec.Validate(); err != nil {
+		termUI.PrintError(err.Error())
+		return nil
+	}
+
+	backend, err := schedule.Detect()
+	if err != nil {
+		termUI.PrintError(err.Error())
+		return nil
+	}
+	if err := backend.Install(spec); err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to install schedule: %v", err))
+		return err
+	}
+
+	termUI.PrintSuccess(fmt.Sprintf("Scheduled '%s' %s via %s", spec.Command(), spec.Cadence, backend.Name()))
+	if sd, ok := backend.(*schedule.Systemd); ok {
+		termUI.PrintInfo(fmt.Sprintf("Units: %s, %s", sd.ServicePath(), sd.TimerPath()))
+	}
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner schedule status' to see the next run",
+		"Thresholds come from the \"auto\" section of the config",
+	})
+	return nil
+}
+
+// runScheduleRemove 删除计划任务
+func runScheduleRemove(cmd *cobra.Command, args []string) error {
+	backend, err := schedule.Detect()
+	if err != nil {
+		termUI.PrintError(err.Error())
+		return nil
+	}
+	status, err := backend.Status()
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to read schedule: %v", err))
+		return err
+	}
+	if !status.Installed {
+		termUI.PrintInfo("No schedule installed")
+		return nil
+	}
+	if err := backend.Remove(); err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to remove schedule: %v", err))
+		return err
+	}
+	termUI.PrintSuccess(fmt.Sprintf("Removed the %s schedule", backend.Name()))
+	return nil
+}
+
+// runScheduleStatus 显示计划任务状态和最近一次运行结果
+func runScheduleStatus(cmd *cobra.Command, args []string) error {
+	// 没有可用后端时仍显示最近一次运行（可能由其他方式调度）
+	status := &schedule.Status{Backend: "none (no systemd or crontab found)"}
+	if backend, err := schedule.Detect(); err == nil {
+		status, err = backend.Status()
+		if err != nil {
+			termUI.PrintError(fmt.Sprintf("Failed to read schedule: %v", err))
+			return err
+		}
+	}
+
+	termUI.PrintSection("Schedule")
+	rows := []summaryRow{{"Backend", status.Backend}}
+	if !status.Installed {
+		rows = append(rows, summaryRow{"Installed", "no"})
+	} else {
+		next := "unknown"
+		if !status.NextRun.IsZero() {
+			next = fmt.Sprintf("%s (in %s)", status.NextRun.Local().Format("2006-01-02 15:04"), formatAge(time.Until(status.NextRun)))
+		}
+		rows = append(rows,
+			summaryRow{"Installed", "yes"},
+			summaryRow{"When", status.Schedule},
+			summaryRow{"Command", status.Command},
+			summaryRow{"Next run", next},
+		)
+	}
+
+	// 最近一次 auto 运行（审计日志中最新的一条）
+	entries, _ := audit.NewLog(config.AuditLogPath()).Query(audit.Filter{Command: "auto"})
+	if len(entries) == 0 {
+		rows = append(rows, summaryRow{"Last run", "never"})
+	} else {
+		last := entries[0]
+		result := last.Result
+		if result == "" {
+			result = "unknown"
+		}
+		if n := last.CountByAction(audit.ActionDeleted); n > 0 {
+			result = fmt.Sprintf("%s, %d files, %s freed", result, n, storage.FormatSize(last.FreedBytes))
+		}
+		if len(last.Errors) > 0 {
+			result = pterm.NewStyle(pterm.FgRed).Sprintf("%s, %d errors", result, len(last.Errors))
+		}
+		rows = append(rows,
+			summaryRow{"Last run", fmt.Sprintf("%s (%s ago)", last.Time.Local().Format("2006-01-02 15:04"), formatAge(time.Since(last.Time)))},
+			summaryRow{"Result", result},
+		)
+	}
+
+	for _, row := range rows {
+		name := pterm.NewStyle(pterm.FgMagenta, pterm.Bold).Sprintf("%-10s", row.name)
+		fmt.Printf("  %s %s\n", name, row.value)
+	}
+
+	tips := []string{"Run 'kiro-cleaner audit --command auto' to see earlier runs"}
+	if !status.Installed {
+		tips = []string{"Run 'kiro-cleaner schedule install --every daily' to clean automatically"}
+	} else if len(entries) > 0 {
+		tips = append(tips, fmt.Sprintf("Run 'kiro-cleaner audit show %s' for the files of the last run", entries[0].ID))
+	}
+	termUI.PrintTips(tips)
+	return nil
+}
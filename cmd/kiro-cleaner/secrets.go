@@ -0,0 +1,213 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/secrets"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/sessions"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+var (
+	scanSecrets bool
+	scanRedact  bool
+)
+
+// maxSecretScanSize 超过该大小的文件不扫描
+const maxSecretScanSize = 64 * 1024 * 1024
+
+func init() {
+	scanCmd.Flags().BoolVar(&scanSecrets, "secrets", false, "Look for API keys, tokens and private keys in chats, sessions and logs")
+	scanCmd.Flags().BoolVar(&scanRedact, "redact", false, "With --secrets: replace the matches in place (originals are backed up)")
+	scanCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation (with --redact)")
+}
+
+// secretFile 要扫描的文件及其结果
+type secretFile struct {
+	path     string
+	source   string // chat, session, log
+	size     int64
+	findings []secrets.Finding
+}
+
+// secretTargets 收集对话、workspace-sessions JSON 和日志文件
+func secretTargets(chatScanner *scanner.ChatScanner) ([]secretFile, int) {
+	var targets []secretFile
+	seen := make(map[string]bool)
+	skipped := 0
+	add := func(path, source string, size int64) {
+		if seen[path] {
+			return
+		}
+		seen[path] = true
+		if size > maxSecretScanSize {
+			skipped++
+			return
+		}
+		targets = append(targets, secretFile{path: path, source: source, size: size})
+	}
+
+	if chats, err := chatScanner.FindConversations(types.ChatRetention{}); err == nil {
+		for _, c := range chats {
+			add(c.Path, "chat", c.Size)
+		}
+	}
+	// 只需要文件列表，不做孤立内容分析
+	fileScanner := scanner.NewFileScanner()
+	fileScanner.SetArtifactAnalysis(false)
+	files, _ := fileScanner.Scan()
+	for _, f := range files {
+		pathLower := strings.ToLower(filepath.ToSlash(f.Path))
+		switch {
+		case strings.Contains(pathLower, "/"+sessions.SessionsDirName+"/") && strings.HasSuffix(pathLower, ".json"):
+			add(f.Path, "session", f.Size)
+		case f.FileType == types.TypeLog || strings.Contains(pathLower, "promptlog"):
+			add(f.Path, "log", f.Size)
+		}
+	}
+	sort.Slice(targets, func(i, j int) bool { return targets[i].path < targets[j].path })
+	return targets, skipped
+}
+
+// runSecretScan scan --secrets：报告敏感信息的位置和类型，不显示内容
+func runSecretScan(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadConfig()
+	detector, err := secrets.NewScanner(cfg.Secrets.Patterns, cfg.Secrets.MinEntropy)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Invalid secrets.patterns in config: %v", err))
+		return err
+	}
+
+	chatScanner := scanner.NewChatScanner()
+	spinner := termUI.Spinner("Scanning for secrets...")
+	targets, skipped := secretTargets(chatScanner)
+	var found []secretFile
+	counts := make(map[string]int)
+	total := 0
+	for _, t := range targets {
+		findings, err := detector.ScanFile(t.path)
+		if err != nil || len(findings) == 0 {
+			continue
+		}
+		t.findings = findings
+		found = append(found, t)
+		for _, f := range findings {
+			counts[f.Type]++
+		}
+		total += len(findings)
+	}
+	spinner.Stop()
+
+	termUI.PrintSuccess(fmt.Sprintf("Scanned %d files", len(targets)))
+	if skipped > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Skipped %d files larger than 64 MB", skipped))
+	}
+	if total == 0 {
+		termUI.PrintSuccess("No secrets found")
+		return nil
+	}
+
+	termUI.PrintSection("Secrets")
+	for _, f := range found {
+		fmt.Printf("  %s %s\n",
+			pterm.NewStyle(pterm.FgGray).Sprintf("%-8s", f.source),
+			truncateMiddle(f.path, 90))
+		for i, s := range f.findings {
+			if i == maxMatches && !verbose {
+				fmt.Printf("             %s\n", pterm.NewStyle(pterm.FgGray).Sprintf("... and %d more (use -v to list all)", len(f.findings)-maxMatches))
+				break
+			}
+			fmt.Printf("             %s %s\n",
+				pterm.NewStyle(pterm.FgYellow).Sprintf("%-14s", s.Type),
+				pterm.NewStyle(pterm.FgGray).Sprintf("line %d, col %d (%d chars)", s.Line, s.Column, s.Length))
+		}
+	}
+	fmt.Println()
+
+	var names []string
+	for t := range counts {
+		names = append(names, t)
+	}
+	sort.Strings(names)
+	var parts []string
+	for _, t := range names {
+		parts = append(parts, fmt.Sprintf("%d %s", counts[t], t))
+	}
+	termUI.PrintWarning(fmt.Sprintf("%d possible secrets in %d files: %s", total, len(found), strings.Join(parts, ", ")))
+
+	if !scanRedact {
+		termUI.PrintTips([]string{
+			"Run 'kiro-cleaner scan --secrets --redact' to replace them with [REDACTED:<type>]",
+			"Add your own detectors under secrets.patterns in ~/.kiro-cleaner/config.json",
+			"Rotate any real credential found here, redacting does not revoke it",
+		})
+		return nil
+	}
+	return redactSecrets(cmd, args, detector, chatScanner, found, total)
+}
+
+// redactSecrets 备份后原地替换敏感信息
+func redactSecrets(cmd *cobra.Command, args []string, detector *secrets.Scanner, chatScanner *scanner.ChatScanner, found []secretFile, total int) error {
+	if !yes && !config.LoadConfig().SkipConfirm {
+		if !termUI.Confirm(fmt.Sprintf("Redact %d secrets in %d files?", total, len(found))) {
+			pterm.Info.Println("Cancelled")
+			return nil
+		}
+	}
+
+	auditEntry := startAudit(cmd, args)
+	var files []types.FileInfo
+	for _, f := range found {
+		files = append(files, types.FileInfo{Path: f.path, Size: f.size})
+	}
+	backupID, err := backup.NewBackupManager(&types.BackupConfig{Enabled: true}).CreateBackup(files)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Backup failed, nothing redacted: %v", err))
+		auditEntry.AddError(err)
+		recordAudit(auditEntry)
+		return err
+	}
+	termUI.PrintSuccess(fmt.Sprintf("Backup created: %s", backupID))
+	auditEntry.BackupID = backupID
+
+	guard := newKiroGuard(chatScanner)
+	redacted, changed, errors := 0, 0, 0
+	for _, f := range found {
+		if err := guard.Check(f.path); err != nil {
+			termUI.PrintWarning(err.Error())
+			auditEntry.AddError(err)
+			errors++
+			continue
+		}
+		n, err := detector.RedactFile(f.path)
+		if err != nil {
+			termUI.PrintWarning(fmt.Sprintf("%s: %v", f.path, err))
+			auditEntry.AddError(err)
+			auditEntry.AddFile(f.path, f.size, "secrets", audit.ActionFailed)
+			errors++
+			continue
+		}
+		redacted += n
+		changed++
+		auditEntry.AddFile(f.path, f.size, "secrets", audit.ActionRewritten)
+	}
+	recordAudit(auditEntry)
+
+	if changed > 0 {
+		termUI.PrintSuccess(fmt.Sprintf("Redacted %d secrets in %d files", redacted, changed))
+	}
+	if errors > 0 {
+		termUI.PrintWarning(fmt.Sprintf("%d files failed (may be locked by Kiro)", errors))
+	}
+	termUI.PrintWarning(fmt.Sprintf("Backup %s still holds the original secrets, delete it once you no longer need it", backupID))
+	return nil
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/policy"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/sessions"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// sessionsCmd sessions command
+var sessionsCmd = &cobra.Command{
+	Use:   "sessions",
+	Short: "Show workspace session files",
+	Long: `Show Kiro workspace sessions (workspace-sessions/<workspace>/<uuid>.json)
+with their size and age.`,
+	RunE: runSessions,
+}
+
+// sessionsCleanCmd sessions clean command
+var sessionsCleanCmd = &cobra.Command{
+	Use:   "clean",
+	Short: "Delete old workspace sessions",
+	Long: `Delete session files older than --older-than days.
+sessions.json is backed up and rewritten atomically so it never refers to missing files.`,
+	RunE: runSessionsClean,
+}
+
+var sessionsOlderThan int
+
+func init() {
+	sessionsCmd.AddCommand(sessionsCleanCmd)
+	rootCmd.AddCommand(sessionsCmd)
+
+	sessionsCmd.SetHelpFunc(customSubCmdHelpFunc)
+	sessionsCleanCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	sessionsCleanCmd.Flags().IntVar(&sessionsOlderThan, "older-than", 30, "Delete sessions inactive for more than N days")
+	sessionsCleanCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview only, no deletion")
+	sessionsCleanCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+}
+
+// newSessionsManager 创建会话管理器
+func newSessionsManager() (*sessions.Manager, error) {
+	agentPath, err := scanner.NewChatScanner().FindKiroAgentPath()
+	if err != nil {
+		return nil, err
+	}
+	mgr := sessions.NewManager(agentPath)
+	mgr.SetBackupManager(backup.NewBackupManager(&types.BackupConfig{Enabled: true}))
+	return mgr, nil
+}
+
+// runSessions 显示会话统计
+func runSessions(cmd *cobra.Command, args []string) error {
+	mgr, err := newSessionsManager()
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Kiro data not found: %v", err))
+		return nil
+	}
+
+	workspaces, err := mgr.Scan()
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to scan sessions: %v", err))
+		return err
+	}
+
+	if len(workspaces) == 0 {
+		termUI.PrintSuccess("No workspace sessions found")
+		return nil
+	}
+
+	termUI.PrintSection("Workspace Sessions")
+
+	var totalSize int64
+	var totalCount int
+	for _, ws := range workspaces {
+		totalSize += ws.TotalSize
+		totalCount += len(ws.Sessions)
+
+		var newest, oldest time.Time
+		missing := 0
+		for _, s := range ws.Sessions {
+			if s.Missing {
+				missing++
+				continue
+			}
+			if newest.IsZero() || s.LastActivity().After(newest) {
+				newest = s.LastActivity()
+			}
+			if oldest.IsZero() || s.LastActivity().Before(oldest) {
+				oldest = s.LastActivity()
+			}
+		}
+
+		name := pterm.NewStyle(pterm.FgCyan, pterm.Bold).Sprint(truncateMiddle(ws.Workspace, 50))
+		fmt.Printf("  %s\n", name)
+		extra := ""
+		if !oldest.IsZero() {
+			extra = fmt.Sprintf("newest %s, oldest %s", formatAge(time.Since(newest)), formatAge(time.Since(oldest)))
+		}
+		if missing > 0 {
+			extra += fmt.Sprintf(", %d missing", missing)
+		}
+		fmt.Printf("    %-14s %10s  %s\n",
+			fmt.Sprintf("%d sessions", len(ws.Sessions)),
+			storage.FormatSize(ws.TotalSize),
+			pterm.NewStyle(pterm.FgGray).Sprint(extra))
+	}
+
+	fmt.Println()
+	fmt.Println("─────────────────────────────────────────────")
+	fmt.Printf("  %s %d sessions, %s\n",
+		pterm.NewStyle(pterm.FgWhite, pterm.Bold).Sprint("Total"),
+		totalCount,
+		pterm.NewStyle(pterm.FgGreen, pterm.Bold).Sprint(storage.FormatSize(totalSize)))
+
+	termUI.PrintTips([]string{
+		"Run 'kiro-cleaner sessions clean --older-than 30' to remove old sessions",
+		"sessions.json is backed up before it is rewritten",
+	})
+	return nil
+}
+
+// runSessionsClean 清理旧会话
+func runSessionsClean(cmd *cobra.Command, args []string) error {
+	mgr, err := newSessionsManager()
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Kiro data not found: %v", err))
+		return nil
+	}
+
+	old, err := mgr.FindOld(sessionsOlderThan)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to scan sessions: %v", err))
+		return err
+	}
+
+	// 跳过项目策略中 never_delete 包含 sessions 的工作区
+	policies := loadWorkspacePolicies(scanner.NewChatScanner())
+	warnPolicyErrors(policies)
+	var allowed []sessions.Session
+	for _, s := range old {
+		if sessionPolicy(policies, s).Protects(policy.ScopeSessions) {
+			continue
+		}
+		allowed = append(allowed, s)
+	}
+	if protected := len(old) - len(allowed); protected > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Kept %d sessions protected by project policies", protected))
+	}
+	old = allowed
+
+	if len(old) == 0 {
+		termUI.PrintSuccess(fmt.Sprintf("No sessions older than %d days", sessionsOlderThan))
+		return nil
+	}
+
+	var totalSize int64
+	missing := 0
+	for _, s := range old {
+		totalSize += s.Size
+		if s.Missing {
+			missing++
+		}
+	}
+
+	termUI.PrintCleanPreview(len(old), storage.FormatSize(totalSize))
+	for _, s := range old {
+		title := s.Title
+		if title == "" {
+			title = s.ID
+		}
+		status := formatAge(s.Age())
+		if s.Missing {
+			status = "missing file"
+		}
+		fmt.Printf("  %s %-40s %10s  %s\n",
+			pterm.NewStyle(pterm.FgRed).Sprint("●"),
+			truncateMiddle(title, 40),
+			storage.FormatSize(s.Size),
+			pterm.NewStyle(pterm.FgGray).Sprint(status))
+	}
+	if missing > 0 {
+		fmt.Println()
+		termUI.PrintInfo(fmt.Sprintf("%d index entries point to missing files and will be removed from sessions.json", missing))
+	}
+
+	if dryRun {
+		termUI.PrintDryRunNotice()
+		return nil
+	}
+
+	if !yes && !config.LoadConfig().SkipConfirm {
+		if !termUI.Confirm("Delete these sessions?") {
+			pterm.Info.Println("Cancelled")
+			return nil
+		}
+	}
+
+	auditEntry := startAudit(cmd, args)
+	result, err := mgr.Delete(old)
+	if err != nil {
+		auditEntry.AddError(err)
+		recordAudit(auditEntry)
+		termUI.PrintError(fmt.Sprintf("Failed to delete sessions: %v", err))
+		return err
+	}
+	rewritten := make(map[string]bool)
+	for _, s := range old {
+		if !s.Missing {
+			auditRemoval(auditEntry, s.Path, s.Size, "session")
+		}
+		if s.Indexed && !rewritten[s.Dir] {
+			rewritten[s.Dir] = true
+			auditEntry.AddFile(filepath.Join(s.Dir, sessions.IndexFileName), 0, "session", audit.ActionRewritten)
+		}
+	}
+	for _, e := range result.Errors {
+		auditEntry.AddError(e)
+	}
+	recordAudit(auditEntry)
+
+	termUI.PrintCleanResult(result.Deleted, storage.FormatSize(result.FreedBytes), len(result.Errors))
+	if result.IndexUpdated > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Rewrote %d sessions.json files (backups in %s)",
+			result.IndexUpdated, backup.NewBackupManager(&types.BackupConfig{}).GetBackupDir()))
+	}
+	if verbose {
+		for _, e := range result.Errors {
+			termUI.PrintWarning(e.Error())
+		}
+	}
+	return nil
+}
+
+// formatAge 格式化时长为简短形式
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	}
+}
+
+// truncateMiddle 截断过长的字符串，保留首尾
+func truncateMiddle(s string, maxLen int) string {
+	r := []rune(s)
+	if len(r) <= maxLen || maxLen < 5 {
+		return s
+	}
+	half := (maxLen - 3) / 2
+	return string(r[:half]) + "..." + string(r[len(r)-(maxLen-3-half):])
+}
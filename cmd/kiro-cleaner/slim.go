@@ -0,0 +1,211 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/pins"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/policy"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/preflight"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/slim"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// chatsSlimCmd chats slim command
+var chatsSlimCmd = &cobra.Command{
+	Use:   "slim [id|search]",
+	Short: "Trim bulky tool output inside conversations, keeping the dialogue",
+	Long: `Rewrite .chat files so that tool messages (file dumps, command output) larger
+than --threshold keep only their first --keep bytes followed by a marker. Human
+and assistant messages are left untouched. Originals are backed up first and
+each file is replaced atomically. Without an argument every conversation is
+slimmed except pinned ones and projects whose policy never deletes chats.`,
+	RunE: runChatsSlim,
+}
+
+var (
+	slimThreshold string
+	slimKeep      int
+	slimOlderThan int
+)
+
+func init() {
+	chatsCmd.AddCommand(chatsSlimCmd)
+	chatsSlimCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	chatsSlimCmd.Flags().StringVar(&slimThreshold, "threshold", "4KB", "Trim tool messages larger than this")
+	chatsSlimCmd.Flags().IntVar(&slimKeep, "keep", slim.DefaultKeep, "Bytes to keep from the start of a trimmed message (0=drop it entirely)")
+	chatsSlimCmd.Flags().IntVar(&slimOlderThan, "older-than", 0, "Only slim conversations not modified for N days")
+	chatsSlimCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Preview only, nothing is rewritten")
+	chatsSlimCmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation")
+}
+
+// slimTargets 选择要裁剪的对话：指定的一个，或除固定和受策略保护外的全部
+func slimTargets(chatScanner *scanner.ChatScanner, args []string) ([]pins.Chat, bool) {
+	chats, err := listChats(chatScanner)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Kiro data not found: %v", err))
+		return nil, false
+	}
+	registry, err := loadPins(chatScanner)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Cannot read pinned conversations: %v", err))
+		return nil, false
+	}
+
+	if len(args) > 0 {
+		chat, ok := resolveChat(strings.Join(args, " "), chats, registry)
+		if !ok {
+			return nil, false
+		}
+		return []pins.Chat{chat}, true
+	}
+
+	policies := loadWorkspacePolicies(chatScanner)
+	cutoff := time.Now().AddDate(0, 0, -slimOlderThan)
+	var targets []pins.Chat
+	skipped := 0
+	for _, c := range chats {
+		if slimOlderThan > 0 && c.ModTime.After(cutoff) {
+			continue
+		}
+		if registry.IsPinned(c) || policies.PolicyForChat(c.Path).Protects(policy.ScopeChats) {
+			skipped++
+			continue
+		}
+		targets = append(targets, c)
+	}
+	if skipped > 0 {
+		termUI.PrintInfo(fmt.Sprintf("Kept %d pinned or policy-protected conversations unchanged", skipped))
+	}
+	return targets, true
+}
+
+// runChatsSlim 裁剪对话中过大的工具输出
+func runChatsSlim(cmd *cobra.Command, args []string) error {
+	threshold, err := preflight.ParseSize(slimThreshold)
+	if err != nil || threshold <= 0 {
+		termUI.PrintError(fmt.Sprintf("Invalid --threshold %q", slimThreshold))
+		return nil
+	}
+	opts := slim.Options{Threshold: int(threshold), Keep: slimKeep}
+
+	chatScanner := scanner.NewChatScanner()
+	targets, ok := slimTargets(chatScanner, args)
+	if !ok {
+		return nil
+	}
+
+	// 先计算每个文件能节省多少，不写入
+	var plan []slim.Result
+	var files []types.FileInfo
+	var before, saved int64
+	titles := make(map[string]pins.Chat)
+	for _, c := range targets {
+		result, err := slim.File(c.Path, opts, false)
+		if err != nil {
+			if verbose {
+				termUI.PrintWarning(fmt.Sprintf("Skipped %s: %v", c.Path, err))
+			}
+			continue
+		}
+		if result.Trimmed == 0 {
+			continue
+		}
+		plan = append(plan, result)
+		files = append(files, types.FileInfo{Path: c.Path, Size: result.Before})
+		titles[c.Path] = c
+		before += result.Before
+		saved += result.Saved()
+	}
+	if len(plan) == 0 {
+		termUI.PrintSuccess(fmt.Sprintf("No tool messages larger than %s", storage.FormatSize(threshold)))
+		return nil
+	}
+
+	termUI.PrintSection("Slim")
+	for _, r := range plan {
+		printSlimResult(titles[r.Path], r)
+	}
+	fmt.Println()
+	termUI.PrintInfo(fmt.Sprintf("%d conversations, %s → %s (saves %s)",
+		len(plan), storage.FormatSize(before), storage.FormatSize(before-saved), storage.FormatSize(saved)))
+
+	if dryRun {
+		termUI.PrintDryRunNotice()
+		return nil
+	}
+	if !yes && !config.LoadConfig().SkipConfirm {
+		if !termUI.Confirm("Trim tool output in these conversations?") {
+			pterm.Info.Println("Cancelled")
+			return nil
+		}
+	}
+
+	// 备份原文件，备份失败时不改写
+	auditEntry := startAudit(cmd, args)
+	backupID, err := backup.NewBackupManager(&types.BackupConfig{Enabled: true}).CreateBackup(files)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Backup failed, nothing rewritten: %v", err))
+		auditEntry.AddError(err)
+		recordAudit(auditEntry)
+		return err
+	}
+	termUI.PrintSuccess(fmt.Sprintf("Backup created: %s", backupID))
+	auditEntry.BackupID = backupID
+
+	guard := newKiroGuard(chatScanner)
+	slimmed := 0
+	var freed int64
+	var errors int
+	for _, r := range plan {
+		if err := guard.Check(r.Path); err != nil {
+			termUI.PrintWarning(err.Error())
+			auditEntry.AddError(err)
+			errors++
+			continue
+		}
+		result, err := slim.File(r.Path, opts, true)
+		if err != nil {
+			termUI.PrintWarning(fmt.Sprintf("%s: %v", r.Path, err))
+			auditEntry.AddError(err)
+			auditEntry.AddFile(r.Path, 0, "slim", audit.ActionFailed)
+			errors++
+			continue
+		}
+		slimmed++
+		freed += result.Saved()
+		auditEntry.AddFile(r.Path, result.Saved(), "slim", audit.ActionRewritten)
+	}
+	recordAudit(auditEntry)
+
+	if slimmed > 0 {
+		termUI.PrintSuccess(fmt.Sprintf("Slimmed %d conversations, freed %s", slimmed, storage.FormatSize(freed)))
+	}
+	if errors > 0 {
+		termUI.PrintWarning(fmt.Sprintf("%d conversations failed", errors))
+	}
+	return nil
+}
+
+// printSlimResult 打印一个对话的裁剪结果
+func printSlimResult(c pins.Chat, r slim.Result) {
+	title := c.Title
+	if title == "" {
+		title = c.ShortID()
+	}
+	fmt.Printf("  %s  %-40s %10s → %-10s %s\n",
+		pterm.NewStyle(pterm.FgCyan).Sprintf("%-8s", c.ShortID()),
+		truncateMiddle(title, 40),
+		storage.FormatSize(r.Before),
+		storage.FormatSize(r.After),
+		pterm.NewStyle(pterm.FgGreen).Sprintf("-%s (%d tool messages)", storage.FormatSize(r.Saved()), r.Trimmed))
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/tokens"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/trend"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/usage"
+)
+
+// statsCmd stats command
+var statsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Usage analytics from conversation metadata",
+}
+
+// statsChatsCmd stats chats command
+var statsChatsCmd = &cobra.Command{
+	Use:   "chats",
+	Short: "Group conversations by model, provider and workflow",
+	Long: `Group conversations by the model, provider and workflow recorded in each .chat
+file and show counts, messages, storage, median session length and recent
+activity, largest groups first.`,
+	RunE: runStatsChats,
+}
+
+var (
+	statsBy     string
+	statsDays   int
+	statsWeekly bool
+	statsTop    int
+)
+
+func init() {
+	statsCmd.AddCommand(statsChatsCmd)
+	rootCmd.AddCommand(statsCmd)
+	statsCmd.SetHelpFunc(customSubCmdHelpFunc)
+	statsChatsCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	statsChatsCmd.Flags().StringVar(&statsBy, "by", "", "Only group by model, provider or workflow (default: all three)")
+	statsChatsCmd.Flags().IntVar(&statsDays, "days", 30, "Activity window in days")
+	statsChatsCmd.Flags().BoolVar(&statsWeekly, "weekly", false, "Show activity per week instead of per day")
+	statsChatsCmd.Flags().IntVar(&statsTop, "top", 10, "Show the N largest groups per dimension (0=all)")
+}
+
+// statsDimensionTitles 各维度的标题
+var statsDimensionTitles = map[string]string{
+	usage.ByModel:    "By Model",
+	usage.ByProvider: "By Provider",
+	usage.ByWorkflow: "By Workflow",
+}
+
+// runStatsChats 按模型、提供者和工作流统计对话
+func runStatsChats(cmd *cobra.Command, args []string) error {
+	dimensions := usage.Dimensions
+	if statsBy != "" {
+		if _, ok := statsDimensionTitles[statsBy]; !ok {
+			termUI.PrintError(fmt.Sprintf("Unknown --by %q, use model, provider or workflow", statsBy))
+			return nil
+		}
+		dimensions = []string{statsBy}
+	}
+	if statsDays <= 0 {
+		statsDays = 30
+	}
+
+	chatScanner := scanner.NewChatScanner()
+	spinner := termUI.Spinner("Reading conversations...")
+	chats, err := chatScanner.ParseConversations()
+	spinner.Stop()
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Kiro data not found: %v", err))
+		return nil
+	}
+	if len(chats) == 0 {
+		termUI.PrintSuccess("No conversations found")
+		return nil
+	}
+
+	opts := usage.Options{Buckets: statsDays, Now: time.Now()}
+	period := fmt.Sprintf("last %d days", statsDays)
+	if statsWeekly {
+		opts.Weekly = true
+		opts.Buckets = (statsDays + 6) / 7
+		period = fmt.Sprintf("last %d weeks", opts.Buckets)
+	}
+	report := usage.Build(chats, opts)
+
+	total := report.Total
+	termUI.PrintSection("Conversations")
+	rows := []summaryRow{
+		{"Chats", fmt.Sprintf("%d", total.Conversations)},
+		{"Messages", fmt.Sprintf("%d (%d human, %d assistant, %d tool)",
+			total.Messages, total.HumanMessages, total.BotMessages, total.ToolMessages)},
+		{"Storage", storage.FormatSize(total.Size)},
+		{"Tokens", "~" + tokens.Format(total.Tokens)},
+		{"Median", formatSessionLength(total.MedianDuration)},
+		{"Activity", fmt.Sprintf("%s  %s",
+			pterm.NewStyle(pterm.FgCyan).Sprint(trend.Sparkline(total.Activity)),
+			pterm.NewStyle(pterm.FgGray).Sprint(period))},
+	}
+	for _, row := range rows {
+		fmt.Printf("  %s %s\n", pterm.NewStyle(pterm.FgCyan, pterm.Bold).Sprintf("%-10s", row.name), row.value)
+	}
+	fmt.Println()
+
+	for _, dim := range dimensions {
+		printUsageGroups(statsDimensionTitles[dim], report.Groups[dim], total.Size)
+	}
+	termUI.PrintTips([]string{
+		"Conversations without metadata are grouped as 'unknown'",
+		"Run 'kiro-cleaner chats slim' to trim tool output in the largest groups",
+	})
+	return nil
+}
+
+// printUsageGroups 打印一个维度的分组
+func printUsageGroups(title string, groups []usage.Group, totalSize int64) {
+	termUI.PrintSection(title)
+	fmt.Printf("  %s\n", pterm.NewStyle(pterm.FgGray).Sprintf("%-28s %6s %9s %10s %8s %6s %8s  %s",
+		"NAME", "CHATS", "MESSAGES", "SIZE", "TOKENS", "SHARE", "MEDIAN", "ACTIVITY"))
+
+	shown := groups
+	if statsTop > 0 && len(shown) > statsTop {
+		shown = shown[:statsTop]
+	}
+	for _, g := range shown {
+		share := 0.0
+		if totalSize > 0 {
+			share = float64(g.Size) * 100 / float64(totalSize)
+		}
+		fmt.Printf("  %s %6d %9d %10s %8s %5.0f%% %8s  %s\n",
+			pterm.NewStyle(pterm.FgCyan, pterm.Bold).Sprintf("%-28s", truncateMiddle(g.Name, 28)),
+			g.Conversations,
+			g.Messages,
+			storage.FormatSize(g.Size),
+			tokens.Format(g.Tokens),
+			share,
+			formatSessionLength(g.MedianDuration),
+			pterm.NewStyle(pterm.FgCyan).Sprint(trend.Sparkline(g.Activity)))
+	}
+	if len(shown) < len(groups) {
+		var rest int64
+		for _, g := range groups[len(shown):] {
+			rest += g.Size
+		}
+		fmt.Printf("  %s\n", pterm.NewStyle(pterm.FgGray).Sprintf("... %d more (%s), use --top 0 to list all",
+			len(groups)-len(shown), storage.FormatSize(rest)))
+	}
+	fmt.Println()
+}
+
+// formatSessionLength 格式化对话时长，没有时长数据时显示 "-"
+func formatSessionLength(d time.Duration) string {
+	switch {
+	case d <= 0:
+		return "-"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	}
+	return formatAge(d)
+}
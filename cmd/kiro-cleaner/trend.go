@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/audit"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/preflight"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/trend"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// trendCmd trend command
+var trendCmd = &cobra.Command{
+	Use:   "trend",
+	Short: "Show storage growth over time",
+	Long: `Show how each storage category grew, based on the snapshot every 'scan' records
+in ~/.kiro-cleaner/trend.jsonl, and estimate when the total will cross a threshold.`,
+	RunE: runTrend,
+}
+
+var (
+	trendDays       int
+	trendWeekly     bool
+	trendThreshold  string
+	trendWorkspaces int
+)
+
+func init() {
+	rootCmd.AddCommand(trendCmd)
+	trendCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	trendCmd.Flags().IntVar(&trendDays, "days", 30, "Show the last N days")
+	trendCmd.Flags().BoolVar(&trendWeekly, "weekly", false, "One point per week instead of per day")
+	trendCmd.Flags().StringVar(&trendThreshold, "threshold", "", "Forecast when the total reaches this size (default: trend_threshold)")
+	trendCmd.Flags().IntVar(&trendWorkspaces, "workspaces", 5, "Show the N fastest-growing workspaces (0 = none)")
+}
+
+// trendCategoryColors 各类别的显示颜色，与 scan 的存储概览一致
+var trendCategoryColors = map[string]pterm.Color{
+	trend.CategoryConversations: pterm.FgCyan,
+	trend.CategoryLogs:          pterm.FgYellow,
+	trend.CategoryCache:         pterm.FgBlue,
+	trend.CategoryIndex:         pterm.FgGreen,
+	trend.CategoryHistory:       pterm.FgMagenta,
+	trend.CategoryCrash:         pterm.FgLightRed,
+	trend.CategoryTemp:          pterm.FgRed,
+	trend.CategoryOther:         pterm.FgGray,
+}
+
+// buildSnapshot 根据扫描结果生成存储快照
+func buildSnapshot(stats *types.StorageStats, convStats *types.ConversationStats, files []types.FileInfo) trend.Snapshot {
+	typeSizes := make(map[types.FileType]int64)
+	for _, file := range files {
+		typeSizes[file.FileType] += file.Size
+	}
+
+	categories := map[string]int64{
+		trend.CategoryConversations: convStats.TotalSize,
+		trend.CategoryLogs:          stats.LogSize,
+		trend.CategoryCache:         stats.CacheSize + typeSizes[types.TypeOrphan], // 孤立产物以前按缓存统计，保持趋势连续
+		trend.CategoryIndex:         typeSizes[types.TypeIndex],
+		trend.CategoryHistory:       typeSizes[types.TypeBackup],
+		trend.CategoryCrash:         typeSizes[types.TypeCrash],
+		trend.CategoryTemp:          stats.TempSize,
+	}
+	total := stats.TotalSize + convStats.TotalSize
+	var classified int64
+	for _, size := range categories {
+		classified += size
+	}
+	if other := total - classified; other > 0 {
+		categories[trend.CategoryOther] = other
+	}
+
+	workspaces := make(map[string]int64)
+	for _, ws := range convStats.WorkspaceBreakdown {
+		name := ws.Path
+		if name == "" {
+			name = ws.WorkspaceID
+		}
+		workspaces[name] += ws.TotalSize
+	}
+
+	return trend.Snapshot{
+		Time:          time.Now(),
+		Total:         total,
+		Categories:    categories,
+		Conversations: convStats.TotalConversations,
+		Workspaces:    workspaces,
+	}
+}
+
+// recordSnapshot 保存本次扫描的快照，失败时只在详细模式下提示
+func recordSnapshot(stats *types.StorageStats, convStats *types.ConversationStats, files []types.FileInfo) {
+	err := trend.NewStore(config.TrendPath()).Append(buildSnapshot(stats, convStats, files))
+	if err != nil && verbose {
+		termUI.PrintWarning(fmt.Sprintf("Failed to record storage snapshot: %v", err))
+	}
+}
+
+// lastCleanup 返回审计日志中最近一次实际删除文件的时间
+func lastCleanup() time.Time {
+	entries, err := audit.NewLog(config.AuditLogPath()).Entries()
+	if err != nil {
+		return time.Time{}
+	}
+	for _, e := range entries {
+		if e.CountByAction(audit.ActionDeleted)+e.CountByAction(audit.ActionQuarantined) > 0 {
+			return e.Time
+		}
+	}
+	return time.Time{}
+}
+
+// formatDelta 格式化带符号的大小变化
+func formatDelta(delta int64) string {
+	if delta < 0 {
+		return "-" + storage.FormatSize(-delta)
+	}
+	return "+" + storage.FormatSize(delta)
+}
+
+// runTrend 显示存储增长趋势
+func runTrend(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadConfig()
+	thresholdStr := trendThreshold
+	if thresholdStr == "" {
+		thresholdStr = cfg.TrendThreshold
+	}
+	threshold, err := preflight.ParseSize(thresholdStr)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Invalid threshold: %v", err))
+		return nil
+	}
+
+	var since time.Time
+	if trendDays > 0 {
+		since = time.Now().AddDate(0, 0, -trendDays)
+	}
+	snaps, err := trend.NewStore(config.TrendPath()).Load(since)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Failed to read snapshots: %v", err))
+		return err
+	}
+
+	period := 24 * time.Hour
+	unit := "day"
+	if trendWeekly {
+		period = 7 * 24 * time.Hour
+		unit = "week"
+	}
+	snaps = trend.Bucket(snaps, period)
+	if len(snaps) < 2 {
+		termUI.PrintWarning("Not enough snapshots yet to show a trend")
+		termUI.PrintInfo("Every 'kiro-cleaner scan' records one; run it on a few different days")
+		return nil
+	}
+
+	first, last := snaps[0], snaps[len(snaps)-1]
+	termUI.PrintSection("Storage Trend")
+	fmt.Println(pterm.NewStyle(pterm.FgGray).Sprintf("  %d %ss, %s → %s",
+		len(snaps), unit, first.Time.Format("2006-01-02"), last.Time.Format("2006-01-02")))
+
+	printRow := func(name string, color pterm.Color, points []trend.Point) {
+		change := trend.Change(points)
+		changeColor := pterm.FgGray
+		if change > 0 {
+			changeColor = pterm.FgRed
+		} else if change < 0 {
+			changeColor = pterm.FgGreen
+		}
+		fmt.Printf("  %s %s %10s %10s  %s\n",
+			pterm.NewStyle(color, pterm.Bold).Sprintf("%-14s", name),
+			pterm.NewStyle(color).Sprint(trend.Sparkline(trend.Values(points))),
+			storage.FormatSize(points[0].Value),
+			storage.FormatSize(points[len(points)-1].Value),
+			pterm.NewStyle(changeColor).Sprintf("%s (%s/day)", formatDelta(change), formatDelta(int64(trend.DailyRate(points)))))
+	}
+
+	for _, category := range trend.Categories {
+		points := trend.Series(snaps, category)
+		var peak int64
+		for _, p := range points {
+			if p.Value > peak {
+				peak = p.Value
+			}
+		}
+		if peak == 0 {
+			continue
+		}
+		printRow(category, trendCategoryColors[category], points)
+	}
+	fmt.Println("─────────────────────────────────────────────")
+	totalPoints := trend.Series(snaps, "total")
+	printRow("total", pterm.FgWhite, totalPoints)
+	fmt.Printf("  %s %d → %d\n",
+		pterm.NewStyle(pterm.FgCyan, pterm.Bold).Sprintf("%-14s", "chats"),
+		first.Conversations, last.Conversations)
+
+	// 按当前增长速度估算何时达到阈值
+	if threshold > 0 {
+		fmt.Println()
+		eta, ok := trend.ETA(totalPoints, threshold)
+		switch {
+		case ok && last.Total >= threshold:
+			termUI.PrintWarning(fmt.Sprintf("Total is already above %s", storage.FormatSize(threshold)))
+		case ok:
+			termUI.PrintInfo(fmt.Sprintf("At this rate the total reaches %s around %s (in %s)",
+				storage.FormatSize(threshold), eta.Format("2006-01-02"), formatAge(time.Until(eta))))
+		default:
+			termUI.PrintSuccess(fmt.Sprintf("Total is not growing toward %s", storage.FormatSize(threshold)))
+		}
+	}
+
+	if trendWorkspaces > 0 {
+		printWorkspaceGrowth(snaps, trendWorkspaces)
+	}
+
+	tips := []string{"Use --weekly for a longer view, --threshold to change the forecast"}
+	if lc := lastCleanup(); !lc.IsZero() {
+		tips = append(tips, fmt.Sprintf("Last cleanup: %s ago ('kiro-cleaner audit' shows what it removed)", formatAge(time.Since(lc))))
+	}
+	termUI.PrintTips(tips)
+	return nil
+}
+
+// printWorkspaceGrowth 显示增长最快的工作区
+func printWorkspaceGrowth(snaps []trend.Snapshot, limit int) {
+	names := make(map[string]bool)
+	for _, snap := range snaps {
+		for name := range snap.Workspaces {
+			names[name] = true
+		}
+	}
+
+	type growth struct {
+		name   string
+		points []trend.Point
+		change int64
+	}
+	var rows []growth
+	for name := range names {
+		points := trend.WorkspaceSeries(snaps, name)
+		if change := trend.Change(points); change > 0 {
+			rows = append(rows, growth{name: name, points: points, change: change})
+		}
+	}
+	if len(rows) == 0 {
+		return
+	}
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].change > rows[j].change
+	})
+	if len(rows) > limit {
+		rows = rows[:limit]
+	}
+
+	termUI.PrintSection("Fastest-Growing Workspaces")
+	for _, row := range rows {
+		fmt.Printf("  %-40s %s %10s  %s\n",
+			truncateMiddle(row.name, 40),
+			pterm.NewStyle(pterm.FgCyan).Sprint(trend.Sparkline(trend.Values(row.points))),
+			storage.FormatSize(row.points[len(row.points)-1].Value),
+			pterm.NewStyle(pterm.FgRed).Sprint(formatDelta(row.change)))
+	}
+}
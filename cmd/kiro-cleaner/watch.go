@@ -0,0 +1,292 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pterm/pterm"
+	"github.com/spf13/cobra"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/preflight"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/trend"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/watch"
+)
+
+// watchCmd watch command
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Watch Kiro's data directories grow in real time",
+	Long: `Watch writes under the Kiro data directories and show live per-category sizes
+and the fastest-growing directories. Stop with Ctrl+C.
+
+With limits set (watch.limits in the config or --limit), a category that passes
+its limit runs watch.action (for example "auto"), at most once per cooldown.`,
+	RunE: runWatch,
+}
+
+var (
+	watchInterval int
+	watchWindow   int
+	watchTop      int
+	watchLimits   map[string]string
+	watchAction   string
+)
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+	watchCmd.SetHelpFunc(customSubCmdHelpFunc)
+
+	watchCmd.Flags().IntVar(&watchInterval, "interval", 2, "Refresh the table every N seconds")
+	watchCmd.Flags().IntVar(&watchWindow, "window", 60, "Rank directories by growth over the last N seconds")
+	watchCmd.Flags().IntVar(&watchTop, "top", 10, "Show the N fastest-growing directories")
+	watchCmd.Flags().StringToStringVar(&watchLimits, "limit", nil, "Category size limit, e.g. --limit logs=500MB (overrides watch.limits)")
+	watchCmd.Flags().StringVar(&watchAction, "action", "", "Command to run when a limit is passed, e.g. \"auto\" (overrides watch.action)")
+}
+
+// watchLimitsString 格式化类别上限，用于配置表
+func watchLimitsString(limits map[string]string) string {
+	if len(limits) == 0 {
+		return "none"
+	}
+	var parts []string
+	for category, size := range limits {
+		parts = append(parts, category+"="+size)
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, ", ")
+}
+
+// parseWatchLimits 合并配置和命令行中的类别上限
+func parseWatchLimits(cfg map[string]string, flags map[string]string) (map[string]int64, error) {
+	merged := make(map[string]string)
+	for k, v := range cfg {
+		merged[k] = v
+	}
+	for k, v := range flags {
+		merged[k] = v
+	}
+
+	known := make(map[string]bool)
+	for _, c := range trend.Categories {
+		known[c] = true
+	}
+	limits := make(map[string]int64)
+	for category, value := range merged {
+		if !known[category] {
+			return nil, fmt.Errorf("unknown category %q (one of %s)", category, strings.Join(trend.Categories, ", "))
+		}
+		size, err := preflight.ParseSize(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", category, err)
+		}
+		limits[category] = size
+	}
+	return limits, nil
+}
+
+// watchActionRunner 执行超限动作，同一时间只运行一个
+type watchActionRunner struct {
+	mu       sync.Mutex
+	args     []string
+	cooldown time.Duration
+	running  bool
+	lastRun  time.Time
+	status   string
+}
+
+// trigger 冷却时间已过且没有正在运行的动作时，在后台执行
+func (r *watchActionRunner) trigger(categories []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.running || (!r.lastRun.IsZero() && time.Since(r.lastRun) < r.cooldown) {
+		return
+	}
+	r.running = true
+	r.lastRun = time.Now()
+	r.status = fmt.Sprintf("running '%s' (%s over limit)", strings.Join(r.args, " "), strings.Join(categories, ", "))
+
+	go func() {
+		code := 0
+		exe, err := os.Executable()
+		if err == nil {
+			err = exec.Command(exe, r.args...).Run()
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			code = exitErr.ExitCode()
+			err = nil
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.running = false
+		if err != nil {
+			r.status = fmt.Sprintf("'%s' failed: %v", strings.Join(r.args, " "), err)
+			return
+		}
+		r.status = fmt.Sprintf("ran '%s' at %s (exit %d)", strings.Join(r.args, " "), r.lastRun.Format("15:04:05"), code)
+	}()
+}
+
+// statusLine 返回最近一次动作的状态
+func (r *watchActionRunner) statusLine() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.status
+}
+
+// runWatch 实时监控 Kiro 数据目录
+func runWatch(cmd *cobra.Command, args []string) error {
+	cfg := config.LoadConfig()
+	limits, err := parseWatchLimits(cfg.Watch.Limits, watchLimits)
+	if err != nil {
+		termUI.PrintError(fmt.Sprintf("Invalid watch limit: %v", err))
+		return nil
+	}
+	action := cfg.Watch.Action
+	if cmd.Flags().Changed("action") {
+		action = watchAction
+	}
+	var runner *watchActionRunner
+	if fields := strings.Fields(action); len(fields) > 0 {
+		runner = &watchActionRunner{
+			args:     fields,
+			cooldown: time.Duration(cfg.Watch.CooldownMinutes) * time.Minute,
+		}
+	}
+
+	roots, err := storage.NewStorageDetector().FindKiroPaths()
+	if err != nil || len(roots) == 0 {
+		termUI.PrintWarning("No Kiro data directories found")
+		return nil
+	}
+
+	spinner := termUI.Spinner("Setting up watches...")
+	tracker := watch.NewTracker(time.Duration(watchWindow) * time.Second)
+	watcher, err := watch.NewWatcher(roots, tracker)
+	if err != nil {
+		spinner.Fail(fmt.Sprintf("Failed to watch: %v", err))
+		return nil
+	}
+	defer watcher.Close()
+	dirs, failed := watcher.Dirs()
+	spinner.Success(fmt.Sprintf("Watching %d directories under %d roots", dirs, len(roots)))
+	if failed > 0 {
+		termUI.PrintWarning(fmt.Sprintf("Could not watch %d directories: %v", failed, watcher.LastError()))
+		termUI.PrintInfo("Raise fs.inotify.max_user_watches to watch everything")
+	}
+
+	stop := make(chan struct{})
+	go watcher.Run(stop)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	area, _ := pterm.DefaultArea.Start()
+	ticker := time.NewTicker(time.Duration(max(watchInterval, 1)) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		exceeded := tracker.Exceeded(limits)
+		if runner != nil && len(exceeded) > 0 {
+			runner.trigger(exceeded)
+		}
+		area.Update(renderWatch(tracker, limits, exceeded, runner))
+
+		select {
+		case <-signals:
+			close(stop)
+			area.Stop()
+			printWatchSummary(tracker)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// renderWatch 生成实时表格
+func renderWatch(tracker *watch.Tracker, limits map[string]int64, exceeded []string, runner *watchActionRunner) string {
+	var b strings.Builder
+	now := time.Now()
+	gray := pterm.NewStyle(pterm.FgGray)
+
+	fmt.Fprintf(&b, "\n%s\n\n", gray.Sprintf("Watching for %s, Ctrl+C to stop", now.Sub(tracker.Started()).Round(time.Second)))
+
+	over := make(map[string]bool)
+	for _, c := range exceeded {
+		over[c] = true
+	}
+	totals := tracker.Totals()
+	growth := tracker.Growth()
+	header := pterm.NewStyle(pterm.FgWhite, pterm.Bold)
+	fmt.Fprintf(&b, "  %s\n", header.Sprintf("%-14s %10s %10s %10s", "Category", "Size", "Growth", "Limit"))
+	for _, category := range trend.Categories {
+		if totals[category] == 0 && growth[category] == 0 {
+			continue
+		}
+		limit := "-"
+		if l := limits[category]; l > 0 {
+			limit = storage.FormatSize(l)
+		}
+		color := trendCategoryColors[category]
+		if over[category] {
+			color = pterm.FgRed
+		}
+		fmt.Fprintf(&b, "  %s %10s %10s %10s\n",
+			pterm.NewStyle(color).Sprintf("%-14s", category),
+			storage.FormatSize(totals[category]),
+			formatDelta(growth[category]),
+			limit)
+	}
+
+	fmt.Fprintf(&b, "\n  %s\n", header.Sprintf("Fastest growing (last %s)", tracker.Window()))
+	top := tracker.Top(watchTop, now)
+	if len(top) == 0 {
+		fmt.Fprintf(&b, "  %s\n", gray.Sprint("No growth yet"))
+	}
+	for _, d := range top {
+		fmt.Fprintf(&b, "  %10s %12s  %-14s %s\n",
+			formatDelta(d.Recent),
+			storage.FormatSize(int64(d.Rate(tracker.Window())))+"/s",
+			d.Category,
+			truncateMiddle(d.Dir, 60))
+	}
+
+	if len(exceeded) > 0 {
+		fmt.Fprintln(&b)
+		msg := fmt.Sprintf("Over limit: %s", strings.Join(exceeded, ", "))
+		if runner == nil {
+			msg += " (set watch.action or --action to clean automatically)"
+		}
+		fmt.Fprintf(&b, "  %s\n", pterm.NewStyle(pterm.FgRed, pterm.Bold).Sprint(msg))
+	}
+	if runner != nil {
+		if status := runner.statusLine(); status != "" {
+			fmt.Fprintf(&b, "  %s\n", gray.Sprint(status))
+		}
+	}
+	return b.String()
+}
+
+// printWatchSummary 退出时显示监控期间的增长
+func printWatchSummary(tracker *watch.Tracker) {
+	termUI.PrintSection("Watch Summary")
+	growth := tracker.Growth()
+	var total int64
+	for _, category := range trend.Categories {
+		if growth[category] == 0 {
+			continue
+		}
+		total += growth[category]
+		fmt.Printf("  %s %10s\n", pterm.NewStyle(trendCategoryColors[category]).Sprintf("%-14s", category), formatDelta(growth[category]))
+	}
+	fmt.Printf("  %-14s %10s in %s\n", "total", formatDelta(total), time.Since(tracker.Started()).Round(time.Second))
+}
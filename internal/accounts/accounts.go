@@ -0,0 +1,161 @@
+package accounts
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// PasswdPath 系统用户数据库
+const PasswdPath = "/etc/passwd"
+
+// MinUID 普通用户的最小 UID（低于它的是系统账户，root 除外）
+const MinUID = 1000
+
+// nobodyUID nobody 账户的 UID
+const nobodyUID = 65534
+
+// User /etc/passwd 中的一个账户
+type User struct {
+	Name  string `json:"name"`
+	UID   int    `json:"uid"`
+	GID   int    `json:"gid"`
+	Home  string `json:"home"`
+	Shell string `json:"shell"`
+}
+
+// ParsePasswd 解析 passwd 格式的内容，跳过注释和格式不正确的行
+func ParsePasswd(r io.Reader) ([]User, error) {
+	var users []User
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ":")
+		if len(fields) != 7 {
+			continue
+		}
+		uid, err := strconv.Atoi(fields[2])
+		if err != nil {
+			continue
+		}
+		gid, err := strconv.Atoi(fields[3])
+		if err != nil {
+			continue
+		}
+		users = append(users, User{Name: fields[0], UID: uid, GID: gid, Home: fields[5], Shell: fields[6]})
+	}
+	return users, sc.Err()
+}
+
+// ReadPasswd 读取 passwd 文件
+func ReadPasswd(path string) ([]User, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取用户列表失败: %v", err)
+	}
+	defer f.Close()
+	return ParsePasswd(f)
+}
+
+// LoginUsers 返回可能使用 Kiro 的账户：root 和普通用户，排除不能登录的账户和没有主目录的账户
+func LoginUsers(users []User) []User {
+	var list []User
+	for _, u := range users {
+		if u.UID != 0 && (u.UID < MinUID || u.UID == nobodyUID) {
+			continue
+		}
+		if strings.HasSuffix(u.Shell, "/nologin") || strings.HasSuffix(u.Shell, "/false") {
+			continue
+		}
+		if u.Home == "" || u.Home == "/" {
+			continue
+		}
+		if info, err := os.Stat(u.Home); err != nil || !info.IsDir() {
+			continue
+		}
+		list = append(list, u)
+	}
+	return list
+}
+
+// Allowed 判断账户是否在允许列表中（按用户名精确匹配，空列表不允许任何人）
+func Allowed(name string, allow []string) bool {
+	for _, a := range allow {
+		if strings.TrimSpace(a) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// sandboxEnv 切换账户时清除的环境变量，它们指向当前用户（root）的目录
+var sandboxEnv = []string{"XDG_CONFIG_HOME", "XDG_DATA_HOME", "XDG_CACHE_HOME", "KIRO_USER_DATA_DIR", "KIRO_PORTABLE"}
+
+// Activate 把 HOME 指向该账户的主目录，使路径发现和 ~/.kiro-cleaner 都落在该账户下
+// 返回的函数恢复原来的环境
+func (u User) Activate() (restore func()) {
+	saved := make(map[string]*string)
+	for _, key := range append([]string{"HOME"}, sandboxEnv...) {
+		if v, ok := os.LookupEnv(key); ok {
+			saved[key] = &v
+		} else {
+			saved[key] = nil
+		}
+	}
+	os.Setenv("HOME", u.Home)
+	for _, key := range sandboxEnv {
+		os.Unsetenv(key)
+	}
+	return func() {
+		for key, v := range saved {
+			if v == nil {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, *v)
+			}
+		}
+	}
+}
+
+// Environ 返回以该账户身份运行子进程时的环境：HOME、USER、LOGNAME 指向该账户，
+// 清除指向当前用户目录的变量和 sudo 留下的变量，其余（PATH、语言、终端等）保留
+func (u User) Environ(base []string) []string {
+	drop := map[string]bool{"HOME": true, "USER": true, "LOGNAME": true}
+	for _, key := range sandboxEnv {
+		drop[key] = true
+	}
+	var env []string
+	for _, kv := range base {
+		key := kv
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			key = kv[:i]
+		}
+		if drop[key] || strings.HasPrefix(key, "SUDO_") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return append(env, "HOME="+u.Home, "USER="+u.Name, "LOGNAME="+u.Name)
+}
+
+// Owns 判断路径是否属于该账户
+// 符号链接按解析后的目标判断：链接本身可能属于该账户，指向的却是其他账户的数据
+func (u User) Owns(path string) bool {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return false
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return false
+	}
+	uid, _, ok := owner(info)
+	return ok && uid == u.UID
+}
@@ -0,0 +1,132 @@
+package accounts
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestParsePasswd(t *testing.T) {
+	data := `# comment
+root:x:0:0:root:/root:/bin/bash
+daemon:x:1:1:daemon:/usr/sbin:/usr/sbin/nologin
+alice:x:1000:1000:Alice,,,:/home/alice:/bin/zsh
+broken:x:abc:1000::/home/broken:/bin/sh
+short:x:1001
+`
+	users, err := ParsePasswd(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("ParsePasswd 失败: %v", err)
+	}
+	if len(users) != 3 {
+		t.Fatalf("应解析出 3 个账户，得到 %d: %+v", len(users), users)
+	}
+	alice := users[2]
+	if alice.Name != "alice" || alice.UID != 1000 || alice.GID != 1000 || alice.Home != "/home/alice" || alice.Shell != "/bin/zsh" {
+		t.Errorf("alice 解析错误: %+v", alice)
+	}
+}
+
+func TestLoginUsers(t *testing.T) {
+	home := t.TempDir()
+	users := []User{
+		{Name: "root", UID: 0, Home: home, Shell: "/bin/bash"},
+		{Name: "daemon", UID: 1, Home: home, Shell: "/bin/sh"},
+		{Name: "alice", UID: 1000, Home: home, Shell: "/bin/bash"},
+		{Name: "svc", UID: 1001, Home: home, Shell: "/usr/sbin/nologin"},
+		{Name: "gone", UID: 1002, Home: filepath.Join(home, "missing"), Shell: "/bin/bash"},
+		{Name: "nobody", UID: 65534, Home: home, Shell: "/bin/sh"},
+	}
+	var names []string
+	for _, u := range LoginUsers(users) {
+		names = append(names, u.Name)
+	}
+	if got := strings.Join(names, ","); got != "root,alice" {
+		t.Errorf("LoginUsers = %s，期望 root,alice", got)
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	allow := []string{"alice", " bob "}
+	if !Allowed("alice", allow) || !Allowed("bob", allow) {
+		t.Error("列表中的用户应被允许")
+	}
+	if Allowed("carol", allow) || Allowed("ali", allow) {
+		t.Error("不在列表中的用户不应被允许")
+	}
+	if Allowed("alice", nil) {
+		t.Error("空列表不应允许任何用户")
+	}
+}
+
+func TestActivate(t *testing.T) {
+	t.Setenv("HOME", "/root")
+	t.Setenv("XDG_CONFIG_HOME", "/root/.config")
+	os.Unsetenv("KIRO_PORTABLE")
+
+	restore := User{Name: "alice", Home: "/home/alice"}.Activate()
+	if os.Getenv("HOME") != "/home/alice" {
+		t.Errorf("HOME = %s", os.Getenv("HOME"))
+	}
+	if _, ok := os.LookupEnv("XDG_CONFIG_HOME"); ok {
+		t.Error("XDG_CONFIG_HOME 应被清除")
+	}
+	os.Setenv("KIRO_PORTABLE", "/tmp/x")
+	restore()
+
+	if os.Getenv("HOME") != "/root" || os.Getenv("XDG_CONFIG_HOME") != "/root/.config" {
+		t.Error("恢复后环境应与之前相同")
+	}
+	if _, ok := os.LookupEnv("KIRO_PORTABLE"); ok {
+		t.Error("原来不存在的变量恢复后应被删除")
+	}
+}
+
+func TestEnviron(t *testing.T) {
+	base := []string{"PATH=/usr/bin", "HOME=/root", "USER=root", "SUDO_USER=admin", "XDG_CONFIG_HOME=/root/.config", "KIRO_PORTABLE=/opt/kiro", "LANG=C.UTF-8"}
+	env := User{Name: "alice", Home: "/home/alice"}.Environ(base)
+	got := strings.Join(env, " ")
+	want := "PATH=/usr/bin LANG=C.UTF-8 HOME=/home/alice USER=alice LOGNAME=alice"
+	if got != want {
+		t.Errorf("Environ = %s，期望 %s", got, want)
+	}
+}
+
+func TestOwns(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 UID")
+	}
+	u := User{UID: os.Getuid(), GID: os.Getgid()}
+	dir := t.TempDir()
+
+	if !u.Owns(dir) {
+		t.Error("当前用户应拥有自己创建的目录")
+	}
+	if (User{UID: u.UID + 1}).Owns(dir) {
+		t.Error("其他 UID 不应拥有该目录")
+	}
+	if u.Owns(filepath.Join(dir, "missing")) {
+		t.Error("不存在的路径不属于任何账户")
+	}
+
+	// 链接属于当前用户，目标属于其他账户
+	target := filepath.Join(t.TempDir(), "other")
+	if err := os.Mkdir(target, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chown(target, u.UID+1, u.GID); err != nil {
+		t.Skipf("无法修改属主: %v", err)
+	}
+	link := filepath.Join(dir, "link")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	if u.Owns(link) {
+		t.Error("应按符号链接的目标判断属主")
+	}
+	if !(User{UID: u.UID + 1}).Owns(link) {
+		t.Error("目标的属主应拥有该链接")
+	}
+}
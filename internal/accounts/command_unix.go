@@ -0,0 +1,25 @@
+//go:build !windows
+
+package accounts
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// Command 创建以该账户身份运行的命令：切换 UID/GID（不保留 root 的附加组），
+// 环境变量见 Environ，工作目录为该账户的主目录
+func (u User) Command(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Env = u.Environ(os.Environ())
+	cmd.Dir = u.Home
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Credential: &syscall.Credential{
+			Uid:    uint32(u.UID),
+			Gid:    uint32(u.GID),
+			Groups: []uint32{uint32(u.GID)},
+		},
+	}
+	return cmd
+}
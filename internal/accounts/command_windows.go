@@ -0,0 +1,16 @@
+//go:build windows
+
+package accounts
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Command Windows 无法按 UID 切换账户，只设置环境变量和工作目录
+func (u User) Command(name string, args ...string) *exec.Cmd {
+	cmd := exec.Command(name, args...)
+	cmd.Env = u.Environ(os.Environ())
+	cmd.Dir = u.Home
+	return cmd
+}
@@ -0,0 +1,17 @@
+//go:build !windows
+
+package accounts
+
+import (
+	"os"
+	"syscall"
+)
+
+// owner 返回文件的属主
+func owner(info os.FileInfo) (uid, gid int, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, 0, false
+	}
+	return int(st.Uid), int(st.Gid), true
+}
@@ -0,0 +1,10 @@
+//go:build windows
+
+package accounts
+
+import "os"
+
+// owner Windows 没有 UID，无法判断属主
+func owner(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
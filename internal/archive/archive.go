@@ -0,0 +1,383 @@
+package archive
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// IndexFileName 每个工作区归档目录中的索引文件名
+const IndexFileName = "index.json"
+
+// Entry 归档中的一个对话
+type Entry struct {
+	ExecutionID  string    `json:"execution_id,omitempty"`
+	Title        string    `json:"title,omitempty"` // 第一条用户消息
+	Model        string    `json:"model,omitempty"`
+	StartTime    time.Time `json:"start_time,omitempty"`
+	EndTime      time.Time `json:"end_time,omitempty"`
+	Workspace    string    `json:"workspace"`     // kiro.kiroagent 下的工作区目录名
+	Name         string    `json:"name"`          // .chat 文件名（也是压缩包中的条目名）
+	Archive      string    `json:"archive"`       // 所在压缩包文件名
+	OriginalPath string    `json:"original_path"` // 归档前的路径
+	Size         int64     `json:"size"`          // 原始大小
+	ArchivedAt   time.Time `json:"archived_at"`
+}
+
+// ID 返回用于显示和匹配的短ID（executionId 前8位，缺失时用文件名）
+func (e Entry) ID() string {
+	id := e.ExecutionID
+	if id == "" {
+		id = strings.TrimSuffix(e.Name, ".chat")
+	}
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return id
+}
+
+// Store 对话归档目录，每个工作区一个子目录，包含若干压缩包和一个索引
+type Store struct {
+	dir string
+}
+
+// NewStore 创建归档目录
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Dir 返回归档目录
+func (s *Store) Dir() string {
+	return s.dir
+}
+
+// Archive 将同一工作区的对话文件压缩到一个新的压缩包并写入索引
+// 原文件不会被删除，调用方应在成功后自行删除
+func (s *Store) Archive(workspace string, paths []string, now time.Time) ([]Entry, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+	wsDir := filepath.Join(s.dir, workspace)
+	if err := os.MkdirAll(wsDir, 0755); err != nil {
+		return nil, fmt.Errorf("创建归档目录失败: %v", err)
+	}
+
+	name := fmt.Sprintf("chats_%s.zip", now.Format("20060102_150405"))
+	for i := 2; fileExists(filepath.Join(wsDir, name)); i++ {
+		name = fmt.Sprintf("chats_%s_%d.zip", now.Format("20060102_150405"), i)
+	}
+	archivePath := filepath.Join(wsDir, name)
+
+	entries, err := writeZip(archivePath, paths)
+	if err != nil {
+		os.Remove(archivePath)
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].Workspace = workspace
+		entries[i].Archive = name
+		entries[i].ArchivedAt = now
+	}
+
+	index, err := s.readIndex(workspace)
+	if err != nil {
+		os.Remove(archivePath)
+		return nil, err
+	}
+	if err := s.writeIndex(workspace, append(index, entries...)); err != nil {
+		os.Remove(archivePath)
+		return nil, err
+	}
+	return entries, nil
+}
+
+// writeZip 将文件写入压缩包并读取每个对话的元数据
+func writeZip(archivePath string, paths []string) ([]Entry, error) {
+	f, err := os.Create(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("创建压缩包失败: %v", err)
+	}
+	defer f.Close()
+
+	zw := zip.NewWriter(f)
+	var entries []Entry
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			zw.Close()
+			return nil, fmt.Errorf("读取对话 %s 失败: %v", path, err)
+		}
+		name := filepath.Base(path)
+		if seen[name] {
+			zw.Close()
+			return nil, fmt.Errorf("同一工作区中有重名的对话: %s", name)
+		}
+		seen[name] = true
+
+		info, _ := os.Stat(path)
+		header := &zip.FileHeader{Name: name, Method: zip.Deflate}
+		if info != nil {
+			header.Modified = info.ModTime()
+		}
+		w, err := zw.CreateHeader(header)
+		if err != nil {
+			zw.Close()
+			return nil, fmt.Errorf("写入压缩包失败: %v", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			zw.Close()
+			return nil, fmt.Errorf("写入压缩包失败: %v", err)
+		}
+
+		entry := describe(data)
+		entry.Name = name
+		entry.OriginalPath = path
+		entry.Size = int64(len(data))
+		entries = append(entries, entry)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("写入压缩包失败: %v", err)
+	}
+	if err := f.Sync(); err != nil {
+		return nil, fmt.Errorf("写入压缩包失败: %v", err)
+	}
+	return entries, nil
+}
+
+// describe 从对话内容中读取 executionId、标题、模型和时间
+func describe(data []byte) Entry {
+	var chatFile types.ChatFile
+	if err := json.Unmarshal(data, &chatFile); err != nil {
+		return Entry{}
+	}
+	entry := Entry{
+		ExecutionID: chatFile.ExecutionID,
+		Model:       chatFile.Metadata.ModelID,
+	}
+	if chatFile.Metadata.StartTime > 0 {
+		entry.StartTime = time.UnixMilli(chatFile.Metadata.StartTime)
+	}
+	if chatFile.Metadata.EndTime > 0 {
+		entry.EndTime = time.UnixMilli(chatFile.Metadata.EndTime)
+	}
+	for _, msg := range chatFile.Chat {
+		if msg.Role == "human" && strings.TrimSpace(msg.Content) != "" {
+			entry.Title = strings.Join(strings.Fields(msg.Content), " ")
+			break
+		}
+	}
+	return entry
+}
+
+// List 返回所有工作区的归档对话，最新归档的在前
+func (s *Store) List() ([]Entry, error) {
+	dirs, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取归档目录失败: %v", err)
+	}
+	var all []Entry
+	for _, d := range dirs {
+		if !d.IsDir() {
+			continue
+		}
+		index, err := s.readIndex(d.Name())
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, index...)
+	}
+	sort.SliceStable(all, func(i, j int) bool {
+		if !all[i].ArchivedAt.Equal(all[j].ArchivedAt) {
+			return all[i].ArchivedAt.After(all[j].ArchivedAt)
+		}
+		return all[i].EndTime.After(all[j].EndTime)
+	})
+	return all, nil
+}
+
+// Extract 将归档的对话解压到 destDir，并从索引中移除
+// 目标文件已存在时拒绝覆盖；压缩包中的对话全部取出后删除压缩包
+func (s *Store) Extract(e Entry, destDir string) (string, error) {
+	if err := e.validate(); err != nil {
+		return "", err
+	}
+	dest := filepath.Join(destDir, e.Name)
+	if err := safety.CheckExtractTarget(destDir, dest); err != nil {
+		return "", err
+	}
+	if fileExists(dest) {
+		return "", fmt.Errorf("目标文件已存在: %s", dest)
+	}
+
+	archivePath := filepath.Join(s.dir, e.Workspace, e.Archive)
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("打开压缩包失败: %v", err)
+	}
+	defer zr.Close()
+
+	var file *zip.File
+	for _, f := range zr.File {
+		if f.Name == e.Name {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return "", fmt.Errorf("压缩包 %s 中没有 %s", e.Archive, e.Name)
+	}
+	if err := extractFile(file, dest); err != nil {
+		return "", err
+	}
+
+	index, err := s.readIndex(e.Workspace)
+	if err != nil {
+		return dest, err
+	}
+	var kept []Entry
+	remaining := 0
+	for _, entry := range index {
+		if entry.Archive == e.Archive && entry.Name == e.Name {
+			continue
+		}
+		if entry.Archive == e.Archive {
+			remaining++
+		}
+		kept = append(kept, entry)
+	}
+	if err := s.writeIndex(e.Workspace, kept); err != nil {
+		return dest, err
+	}
+	if remaining == 0 {
+		zr.Close()
+		os.Remove(archivePath)
+	}
+	return dest, nil
+}
+
+// validate 检查索引中的名称：index.json 可能被改写，名称只能是单个文件名，不能带路径
+func (e Entry) validate() error {
+	if !plainName(e.Name) || filepath.Ext(e.Name) != ".chat" {
+		return fmt.Errorf("归档索引中的对话名无效: %q", e.Name)
+	}
+	if !plainName(e.Workspace) || !plainName(e.Archive) {
+		return fmt.Errorf("归档索引中的路径无效: %q/%q", e.Workspace, e.Archive)
+	}
+	return nil
+}
+
+// plainName 判断 name 是否为不含目录的普通文件名
+func plainName(name string) bool {
+	return name != "" && name != "." && name != ".." &&
+		!strings.ContainsAny(name, `/\`) && filepath.Base(name) == name
+}
+
+// extractFile 将压缩包中的条目写入 dest（先写临时文件再重命名）
+func extractFile(file *zip.File, dest string) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("读取压缩包失败: %v", err)
+	}
+	defer rc.Close()
+
+	tmp := dest + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("创建文件失败: %v", err)
+	}
+	if _, err := io.Copy(out, rc); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("解压失败: %v", err)
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("解压失败: %v", err)
+	}
+	if !file.Modified.IsZero() {
+		os.Chtimes(tmp, file.Modified, file.Modified)
+	}
+	if err := os.Rename(tmp, dest); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("解压失败: %v", err)
+	}
+	return nil
+}
+
+// Lookup 按 executionId（或前缀）、文件名或标题查找归档的对话
+func Lookup(ref string, entries []Entry) []Entry {
+	var byID []Entry
+	for _, e := range entries {
+		name := strings.TrimSuffix(e.Name, ".chat")
+		if e.ExecutionID == ref || name == ref {
+			return []Entry{e}
+		}
+		if len(ref) >= 4 && (strings.HasPrefix(e.ExecutionID, ref) || strings.HasPrefix(name, ref)) {
+			byID = append(byID, e)
+		}
+	}
+	if len(byID) > 0 {
+		return byID
+	}
+
+	var found []Entry
+	query := strings.ToLower(ref)
+	for _, e := range entries {
+		if strings.Contains(strings.ToLower(e.Title), query) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+func (s *Store) readIndex(workspace string) ([]Entry, error) {
+	data, err := os.ReadFile(filepath.Join(s.dir, workspace, IndexFileName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取归档索引失败: %v", err)
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("解析归档索引 %s 失败: %v", workspace, err)
+	}
+	return entries, nil
+}
+
+func (s *Store) writeIndex(workspace string, entries []Entry) error {
+	path := filepath.Join(s.dir, workspace, IndexFileName)
+	if entries == nil {
+		entries = []Entry{}
+	}
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := utils.WriteFileAtomic(path, data, 0644); err != nil {
+		return fmt.Errorf("写入归档索引失败: %v", err)
+	}
+	return nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
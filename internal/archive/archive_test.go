@@ -0,0 +1,146 @@
+package archive
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeChat(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArchiveListExtract(t *testing.T) {
+	base := t.TempDir()
+	wsDir := filepath.Join(base, "agent", "ws1")
+	a := filepath.Join(wsDir, "a.chat")
+	b := filepath.Join(wsDir, "b.chat")
+	writeChat(t, a, `{"executionId":"aaaa1111-x","chat":[{"role":"human","content":"Add  dark mode"}],"metadata":{"modelId":"claude","startTime":1700000000000,"endTime":1700000600000}}`)
+	writeChat(t, b, `{"executionId":"bbbb2222-y","chat":[{"role":"human","content":"Write release notes"}]}`)
+
+	store := NewStore(filepath.Join(base, "archive"))
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries, err := store.Archive("ws1", []string{a, b}, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("应归档 2 个对话，实际 %d", len(entries))
+	}
+	e := entries[0]
+	if e.ExecutionID != "aaaa1111-x" || e.Title != "Add dark mode" || e.Model != "claude" || e.ID() != "aaaa1111" {
+		t.Errorf("索引信息不正确: %+v", e)
+	}
+	if !e.EndTime.Equal(time.UnixMilli(1700000600000)) || e.Archive != "chats_20240301_120000.zip" {
+		t.Errorf("时间或压缩包名不正确: %+v", e)
+	}
+
+	// 原文件由调用方删除
+	os.Remove(a)
+	os.Remove(b)
+
+	listed, err := store.List()
+	if err != nil || len(listed) != 2 {
+		t.Fatalf("List = %v, %v", listed, err)
+	}
+	found := Lookup("release", listed)
+	if len(found) != 1 || found[0].Name != "b.chat" {
+		t.Fatalf("按标题查找失败: %v", found)
+	}
+
+	dest, err := store.Extract(found[0], wsDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil || len(data) != int(found[0].Size) {
+		t.Fatalf("解压的文件不正确: %v", err)
+	}
+	listed, _ = store.List()
+	if len(listed) != 1 || listed[0].Name != "a.chat" {
+		t.Errorf("解压后应从索引中移除: %v", listed)
+	}
+
+	// 目标已存在时拒绝覆盖
+	writeChat(t, a, `{}`)
+	if _, err := store.Extract(listed[0], wsDir); err == nil {
+		t.Error("目标文件存在时应拒绝解压")
+	}
+	os.Remove(a)
+	if _, err := store.Extract(listed[0], wsDir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(store.Dir(), "ws1", e.Archive)); !os.IsNotExist(err) {
+		t.Error("全部取出后应删除压缩包")
+	}
+}
+
+func TestExtractRejectsUnsafeIndex(t *testing.T) {
+	base := t.TempDir()
+	wsDir := filepath.Join(base, "agent", "ws1")
+	a := filepath.Join(wsDir, "a.chat")
+	writeChat(t, a, `{"executionId":"aaaa1111-x","chat":[]}`)
+
+	store := NewStore(filepath.Join(base, "archive"))
+	entries, err := store.Archive("ws1", []string{a}, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Remove(a)
+
+	for _, mutate := range []func(*Entry){
+		func(e *Entry) { e.Name = "../../escape.chat" },
+		func(e *Entry) { e.Name = "notes.txt" },
+		func(e *Entry) { e.Workspace = "../ws1" },
+		func(e *Entry) { e.Archive = "../ws1/" + e.Archive },
+	} {
+		e := entries[0]
+		mutate(&e)
+		if _, err := store.Extract(e, wsDir); err == nil {
+			t.Errorf("应拒绝索引 %+v", e)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(base, "escape.chat")); !os.IsNotExist(err) {
+		t.Error("不应写到目标目录之外")
+	}
+
+	// 目标是符号链接时拒绝解压
+	if err := os.Symlink(filepath.Join(base, "escape.chat"), a); err != nil {
+		t.Skip("无法创建符号链接")
+	}
+	if _, err := store.Extract(entries[0], wsDir); err == nil {
+		t.Error("应拒绝解压到符号链接")
+	}
+	os.Remove(a)
+	if _, err := store.Extract(entries[0], wsDir); err != nil {
+		t.Errorf("正常的索引应能解压: %v", err)
+	}
+}
+
+func TestArchiveSecondRunUsesNewFile(t *testing.T) {
+	base := t.TempDir()
+	store := NewStore(filepath.Join(base, "archive"))
+	now := time.Now()
+	for i, name := range []string{"one.chat", "two.chat"} {
+		path := filepath.Join(base, "ws", name)
+		writeChat(t, path, `{"chat":[]}`)
+		entries, err := store.Archive("ws", []string{path}, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 && entries[0].Archive == "chats_"+now.Format("20060102_150405")+".zip" {
+			t.Error("同一秒内的第二次归档应使用新的压缩包")
+		}
+	}
+	listed, _ := store.List()
+	if len(listed) != 2 || listed[0].ID() == "" {
+		t.Errorf("List = %v", listed)
+	}
+}
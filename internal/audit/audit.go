@@ -0,0 +1,252 @@
+package audit
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 文件操作类型
+const (
+	ActionDeleted     = "deleted"     // 已删除
+	ActionQuarantined = "quarantined" // 已移入隔离目录
+	ActionRewritten   = "rewritten"   // 已改写（编辑历史索引、裁剪过的对话）
+	ActionArchived    = "archived"    // 已压缩到归档目录后删除
+	ActionRestored    = "restored"    // 已从隔离目录或归档恢复
+	ActionFailed      = "failed"      // 操作失败，文件保留
+)
+
+// FileRecord 一次运行中受影响的文件
+type FileRecord struct {
+	Path   string `json:"path"`
+	Size   int64  `json:"size"`
+	Reason string `json:"reason,omitempty"` // 清理原因（log、cache、chat、history ...）
+	Action string `json:"action"`
+}
+
+// Entry 一次清理、恢复或优化运行的审计记录
+type Entry struct {
+	ID               string            `json:"id"`
+	Time             time.Time         `json:"time"`
+	Command          string            `json:"command"`
+	Args             []string          `json:"args,omitempty"`
+	Flags            map[string]string `json:"flags,omitempty"` // 显式指定的命令行参数
+	ConfigHash       string            `json:"config_hash,omitempty"`
+	Version          string            `json:"version"`
+	Files            []FileRecord      `json:"files"`
+	FreedBytes       int64             `json:"freed_bytes"`
+	QuarantinedBytes int64             `json:"quarantined_bytes,omitempty"` // 移入隔离目录的字节数，仍占用磁盘，不计入 FreedBytes
+	BackupID         string            `json:"backup_id,omitempty"`
+	Errors           []string          `json:"errors,omitempty"`
+	Result           string            `json:"result,omitempty"` // 无人值守运行的结果（如 auto 的 "cleaned"）
+	DurationMS       int64             `json:"duration_ms"`
+}
+
+// NewEntry 创建一条审计记录，开始计时
+func NewEntry(command string) *Entry {
+	now := time.Now()
+	return &Entry{
+		ID:      newID(now),
+		Time:    now,
+		Command: command,
+		Flags:   make(map[string]string),
+	}
+}
+
+// AddFile 记录一个受影响的文件
+func (e *Entry) AddFile(path string, size int64, reason, action string) {
+	e.Files = append(e.Files, FileRecord{Path: path, Size: size, Reason: reason, Action: action})
+	switch action {
+	case ActionDeleted:
+		e.FreedBytes += size
+	case ActionQuarantined:
+		e.QuarantinedBytes += size
+	}
+}
+
+// AddError 记录一个错误
+func (e *Entry) AddError(err error) {
+	if err != nil {
+		e.Errors = append(e.Errors, err.Error())
+	}
+}
+
+// Finish 结束计时
+func (e *Entry) Finish() {
+	e.DurationMS = time.Since(e.Time).Milliseconds()
+}
+
+// Duration 返回运行耗时
+func (e Entry) Duration() time.Duration {
+	return time.Duration(e.DurationMS) * time.Millisecond
+}
+
+// CountByAction 按操作类型统计文件数
+func (e Entry) CountByAction(action string) int {
+	n := 0
+	for _, f := range e.Files {
+		if f.Action == action {
+			n++
+		}
+	}
+	return n
+}
+
+// Filter 审计记录过滤条件
+type Filter struct {
+	Command    string    // 命令前缀，如 "clean"、"sessions clean"
+	Since      time.Time // 只返回此时间之后的记录
+	Path       string    // 只返回涉及包含该子串的路径的记录
+	ErrorsOnly bool      // 只返回有错误的记录
+}
+
+// Match 判断记录是否满足过滤条件
+func (f Filter) Match(e Entry) bool {
+	if f.Command != "" && e.Command != f.Command && !strings.HasPrefix(e.Command, f.Command+" ") {
+		return false
+	}
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+	if f.ErrorsOnly && len(e.Errors) == 0 {
+		return false
+	}
+	if f.Path != "" && len(e.MatchingFiles(f.Path)) == 0 {
+		return false
+	}
+	return true
+}
+
+// MatchingFiles 返回路径包含指定子串的文件记录
+func (e Entry) MatchingFiles(substr string) []FileRecord {
+	var matched []FileRecord
+	for _, f := range e.Files {
+		if strings.Contains(f.Path, substr) {
+			matched = append(matched, f)
+		}
+	}
+	return matched
+}
+
+// Log 追加写入的 JSONL 审计日志
+type Log struct {
+	path string
+}
+
+// NewLog 创建审计日志
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path 返回日志文件路径
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append 追加一条记录（每条记录一行，写入后同步到磁盘）
+func (l *Log) Append(e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("序列化审计记录失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("创建审计日志目录失败: %v", err)
+	}
+	f, err := os.OpenFile(l.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("打开审计日志失败: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入审计日志失败: %v", err)
+	}
+	return f.Sync()
+}
+
+// Entries 读取全部记录，按时间从新到旧排序，跳过无法解析的行
+func (l *Log) Entries() ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取审计日志失败: %v", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal([]byte(line), &e); err != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return entries, fmt.Errorf("读取审计日志失败: %v", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+	return entries, nil
+}
+
+// Query 返回满足过滤条件的记录
+func (l *Log) Query(filter Filter) ([]Entry, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var matched []Entry
+	for _, e := range entries {
+		if filter.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	return matched, nil
+}
+
+// Find 按ID（或ID前缀）查找记录
+func (l *Log) Find(id string) (*Entry, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+	var found *Entry
+	for i := range entries {
+		if entries[i].ID == id {
+			return &entries[i], nil
+		}
+		if strings.HasPrefix(entries[i].ID, id) {
+			if found != nil {
+				return nil, fmt.Errorf("审计记录ID前缀不唯一: %s", id)
+			}
+			found = &entries[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("未找到审计记录: %s", id)
+	}
+	return found, nil
+}
+
+// newID 生成按时间排序的记录ID
+func newID(t time.Time) string {
+	buf := make([]byte, 3)
+	rand.Read(buf)
+	return t.Format("20060102-150405") + "-" + hex.EncodeToString(buf)
+}
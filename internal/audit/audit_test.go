@@ -0,0 +1,123 @@
+package audit
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLog_AppendAndEntries(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "nested", "audit.jsonl"))
+
+	first := NewEntry("clean")
+	first.Time = time.Now().Add(-time.Hour)
+	first.AddFile("/kiro/logs/main.log", 100, "log", ActionDeleted)
+	first.AddFile("/kiro/Cache/a", 50, "cache", ActionFailed)
+	first.AddFile("/kiro/logs/old.log", 30, "log", ActionQuarantined)
+	first.AddError(errors.New("permission denied"))
+	first.Finish()
+
+	second := NewEntry("sessions clean")
+	second.AddFile("/kiro/workspace-sessions/ws/1.json", 10, "session", ActionDeleted)
+	second.Finish()
+
+	for _, e := range []*Entry{first, second} {
+		if err := log.Append(e); err != nil {
+			t.Fatalf("写入审计日志失败: %v", err)
+		}
+	}
+
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("读取审计日志失败: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("应该有 2 条记录，实际 %d", len(entries))
+	}
+	if entries[0].ID != second.ID {
+		t.Error("记录应按时间从新到旧排序")
+	}
+	if entries[1].FreedBytes != 100 {
+		t.Errorf("失败和隔离的文件不应计入释放空间: %d", entries[1].FreedBytes)
+	}
+	if entries[1].QuarantinedBytes != 30 {
+		t.Errorf("隔离的文件应单独统计: %d", entries[1].QuarantinedBytes)
+	}
+	if entries[1].CountByAction(ActionFailed) != 1 || len(entries[1].Errors) != 1 {
+		t.Error("应保留失败文件和错误信息")
+	}
+}
+
+func TestLog_SkipsMalformedLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	log := NewLog(path)
+	log.Append(NewEntry("clean"))
+
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString("{truncated\n\n")
+	f.Close()
+	log.Append(NewEntry("history prune"))
+
+	entries, err := log.Entries()
+	if err != nil {
+		t.Fatalf("读取审计日志失败: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Errorf("应跳过损坏的行，实际 %d 条记录", len(entries))
+	}
+}
+
+func TestLog_MissingFile(t *testing.T) {
+	entries, err := NewLog(filepath.Join(t.TempDir(), "none.jsonl")).Entries()
+	if err != nil || len(entries) != 0 {
+		t.Errorf("日志不存在时应返回空列表: %v, %v", entries, err)
+	}
+}
+
+func TestFilter_Match(t *testing.T) {
+	e := *NewEntry("sessions clean")
+	e.AddFile("/kiro/workspace-sessions/ws/abc.json", 10, "session", ActionDeleted)
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   bool
+	}{
+		{"空条件", Filter{}, true},
+		{"命令完全匹配", Filter{Command: "sessions clean"}, true},
+		{"命令前缀", Filter{Command: "sessions"}, true},
+		{"命令不匹配", Filter{Command: "clean"}, false},
+		{"时间之后", Filter{Since: time.Now().Add(-time.Hour)}, true},
+		{"时间之前", Filter{Since: time.Now().Add(time.Hour)}, false},
+		{"路径匹配", Filter{Path: "abc.json"}, true},
+		{"路径不匹配", Filter{Path: "chats"}, false},
+		{"只看错误", Filter{ErrorsOnly: true}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.filter.Match(e); got != tt.want {
+			t.Errorf("%s: Match() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLog_Find(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+	a := NewEntry("clean")
+	a.ID = "20250101-100000-aaaaaa"
+	b := NewEntry("clean")
+	b.ID = "20250101-100000-bbbbbb"
+	log.Append(a)
+	log.Append(b)
+
+	if found, err := log.Find("20250101-100000-b"); err != nil || found.ID != b.ID {
+		t.Errorf("应按前缀找到记录: %v, %v", found, err)
+	}
+	if _, err := log.Find("20250101"); err == nil {
+		t.Error("前缀不唯一时应返回错误")
+	}
+	if _, err := log.Find("nope"); err == nil {
+		t.Error("不存在的ID应返回错误")
+	}
+}
@@ -0,0 +1,124 @@
+package auto
+
+import (
+	"fmt"
+	"time"
+)
+
+// 退出码：让 cron / systemd 区分运行结果
+const (
+	ExitNothingToDo = 0 // 所有阈值都未超出
+	ExitFailed      = 1 // 扫描或清理失败
+	ExitCleaned     = 2 // 清理了超出阈值的类别
+	ExitKiroRunning = 3 // Kiro 正在运行（或占用了所有待清理文件），未执行清理
+)
+
+// ResultName 返回退出码对应的结果名称，记录在审计日志中
+func ResultName(code int) string {
+	switch code {
+	case ExitNothingToDo:
+		return "nothing to do"
+	case ExitCleaned:
+		return "cleaned"
+	case ExitKiroRunning:
+		return "kiro running"
+	default:
+		return "failed"
+	}
+}
+
+// 可自动清理的类别
+const (
+	CategoryCache = "cache"
+	CategoryLogs  = "logs"
+	CategoryIndex = "index"
+	CategoryTemp  = "temp"
+	CategoryChats = "chats"
+)
+
+// categoryOrder 类别的显示和清理顺序
+var categoryOrder = []string{CategoryTemp, CategoryLogs, CategoryCache, CategoryIndex, CategoryChats}
+
+// TotalCategories 总大小超限时清理的类别（可自动重建的数据，不包括对话）
+var TotalCategories = []string{CategoryTemp, CategoryLogs, CategoryCache}
+
+// Thresholds 自动清理阈值（0 表示不检查）
+type Thresholds struct {
+	MaxTotal   int64
+	MaxCache   int64
+	MaxLogs    int64
+	MaxIndex   int64
+	MaxTemp    int64
+	ChatMaxAge time.Duration // 超过该时长的对话会被清理
+}
+
+// IsEmpty 判断是否没有设置任何阈值
+func (t Thresholds) IsEmpty() bool {
+	return t.MaxTotal == 0 && t.MaxCache == 0 && t.MaxLogs == 0 &&
+		t.MaxIndex == 0 && t.MaxTemp == 0 && t.ChatMaxAge == 0
+}
+
+// Usage 当前存储占用
+type Usage struct {
+	Total        int64
+	Sizes        map[string]int64 // 类别 -> 字节数
+	OldChats     int              // 超过 ChatMaxAge 的对话数
+	OldChatBytes int64            // 超过 ChatMaxAge 的对话大小
+}
+
+// Trigger 一个被超出的阈值
+type Trigger struct {
+	Name       string   // 阈值名称（max_total、max_cache ...）
+	Value      int64    // 当前值
+	Limit      int64    // 阈值
+	Categories []string // 需要清理的类别
+}
+
+// String 返回触发原因描述
+func (t Trigger) String() string {
+	if t.Name == "chat_max_age" {
+		return fmt.Sprintf("%s: %d conversations", t.Name, t.Value)
+	}
+	return fmt.Sprintf("%s: %d > %d bytes", t.Name, t.Value, t.Limit)
+}
+
+// Evaluate 比较占用和阈值，返回被超出的阈值
+func Evaluate(usage Usage, t Thresholds) []Trigger {
+	var triggers []Trigger
+	check := func(name string, value, limit int64, categories ...string) {
+		if limit > 0 && value > limit {
+			triggers = append(triggers, Trigger{Name: name, Value: value, Limit: limit, Categories: categories})
+		}
+	}
+
+	check("max_total", usage.Total, t.MaxTotal, TotalCategories...)
+	check("max_cache", usage.Sizes[CategoryCache], t.MaxCache, CategoryCache)
+	check("max_logs", usage.Sizes[CategoryLogs], t.MaxLogs, CategoryLogs)
+	check("max_index", usage.Sizes[CategoryIndex], t.MaxIndex, CategoryIndex)
+	check("max_temp", usage.Sizes[CategoryTemp], t.MaxTemp, CategoryTemp)
+	if t.ChatMaxAge > 0 && usage.OldChats > 0 {
+		triggers = append(triggers, Trigger{
+			Name:       "chat_max_age",
+			Value:      int64(usage.OldChats),
+			Categories: []string{CategoryChats},
+		})
+	}
+	return triggers
+}
+
+// Categories 返回触发的阈值需要清理的类别（去重，按固定顺序）
+func Categories(triggers []Trigger) []string {
+	selected := make(map[string]bool)
+	for _, t := range triggers {
+		for _, c := range t.Categories {
+			selected[c] = true
+		}
+	}
+	var categories []string
+	for _, c := range categoryOrder {
+		if selected[c] {
+			categories = append(categories, c)
+		}
+	}
+	return categories
+}
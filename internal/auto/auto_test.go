@@ -0,0 +1,79 @@
+package auto
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEvaluate(t *testing.T) {
+	thresholds := Thresholds{
+		MaxTotal: 2000,
+		MaxCache: 500,
+		MaxLogs:  200,
+	}
+
+	usage := Usage{Total: 1000, Sizes: map[string]int64{CategoryCache: 100, CategoryLogs: 100}}
+	if triggers := Evaluate(usage, thresholds); len(triggers) != 0 {
+		t.Errorf("未超出阈值时不应触发: %v", triggers)
+	}
+
+	usage = Usage{Total: 1000, Sizes: map[string]int64{CategoryCache: 600, CategoryLogs: 100}}
+	triggers := Evaluate(usage, thresholds)
+	if len(triggers) != 1 || triggers[0].Name != "max_cache" {
+		t.Fatalf("应只触发 max_cache: %v", triggers)
+	}
+	if got := Categories(triggers); !reflect.DeepEqual(got, []string{CategoryCache}) {
+		t.Errorf("只应清理缓存: %v", got)
+	}
+
+	usage = Usage{Total: 3000, Sizes: map[string]int64{CategoryCache: 600}}
+	triggers = Evaluate(usage, thresholds)
+	if got := Categories(triggers); !reflect.DeepEqual(got, []string{CategoryTemp, CategoryLogs, CategoryCache}) {
+		t.Errorf("总大小超限应清理可重建的类别（去重）: %v", got)
+	}
+}
+
+func TestEvaluate_ChatMaxAge(t *testing.T) {
+	thresholds := Thresholds{ChatMaxAge: 60 * 24 * time.Hour}
+
+	if triggers := Evaluate(Usage{}, thresholds); len(triggers) != 0 {
+		t.Errorf("没有旧对话时不应触发: %v", triggers)
+	}
+
+	triggers := Evaluate(Usage{OldChats: 3, OldChatBytes: 300}, thresholds)
+	if got := Categories(triggers); !reflect.DeepEqual(got, []string{CategoryChats}) {
+		t.Errorf("应只清理对话: %v", got)
+	}
+
+	// 未设置对话阈值时，总大小超限也不清理对话
+	triggers = Evaluate(Usage{Total: 10, OldChats: 3}, Thresholds{MaxTotal: 1})
+	for _, c := range Categories(triggers) {
+		if c == CategoryChats {
+			t.Error("总大小超限不应清理对话")
+		}
+	}
+}
+
+func TestThresholds_IsEmpty(t *testing.T) {
+	if !(Thresholds{}).IsEmpty() {
+		t.Error("零值应为空")
+	}
+	if (Thresholds{MaxIndex: 1}).IsEmpty() {
+		t.Error("设置了阈值时不应为空")
+	}
+}
+
+func TestResultName(t *testing.T) {
+	cases := map[int]string{
+		ExitNothingToDo: "nothing to do",
+		ExitCleaned:     "cleaned",
+		ExitKiroRunning: "kiro running",
+		ExitFailed:      "failed",
+	}
+	for code, want := range cases {
+		if got := ResultName(code); got != want {
+			t.Errorf("ResultName(%d) = %q, 期望 %q", code, got, want)
+		}
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
 	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
 )
 
@@ -118,7 +120,18 @@ func (bm *BackupManager) Restore(backupID string) error {
 	
 	// 提取文件
 	for _, file := range zipFile.File {
-		filePath := filepath.Join(bm.backupDir, file.Name)
+		// 拒绝绝对路径和 .. 逃逸的条目（zip-slip）
+		filePath, err := safety.SafeJoin(bm.backupDir, file.Name)
+		if err != nil {
+			return fmt.Errorf("备份文件包含不安全的条目: %v", err)
+		}
+		// 不还原符号链接条目
+		if safety.IsSymlink(file.FileInfo()) {
+			continue
+		}
+		if err := safety.CheckExtractTarget(bm.backupDir, filePath); err != nil {
+			return fmt.Errorf("备份文件包含不安全的条目: %v", err)
+		}
 		
 		if file.FileInfo().IsDir() {
 			// 创建目录
@@ -244,6 +257,49 @@ func (bm *BackupManager) CleanupOldBackups() error {
 	return nil
 }
 
+// BackupFile 备份单个文件（原样复制，不压缩）
+// 用于改写索引文件（如 sessions.json）前保留原始版本，返回备份文件路径
+func (bm *BackupManager) BackupFile(filePath string) (string, error) {
+	timestamp := time.Now().Format("20060102_150405")
+
+	// 以原始绝对路径作为相对路径，避免不同目录下的同名文件互相覆盖
+	absPath, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("解析路径失败: %v", err)
+	}
+	relPath := strings.TrimPrefix(absPath, filepath.VolumeName(absPath))
+	relPath = strings.TrimLeft(relPath, string(filepath.Separator))
+
+	destPath := filepath.Join(bm.backupDir, "files", timestamp, relPath)
+	if err := os.MkdirAll(filepath.Dir(destPath), 0755); err != nil {
+		return "", fmt.Errorf("创建备份目录失败: %v", err)
+	}
+
+	src, err := os.Open(absPath)
+	if err != nil {
+		return "", fmt.Errorf("打开文件失败: %v", err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(destPath)
+	if err != nil {
+		return "", fmt.Errorf("创建备份文件失败: %v", err)
+	}
+
+	// 关闭失败时数据可能没有写入，备份不完整，不能报告成功
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(destPath)
+		return "", fmt.Errorf("复制文件失败: %v", err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(destPath)
+		return "", fmt.Errorf("写入备份文件失败: %v", err)
+	}
+
+	return destPath, nil
+}
+
 // GetBackupDir 获取备份目录
 func (bm *BackupManager) GetBackupDir() string {
 	return bm.backupDir
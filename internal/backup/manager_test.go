@@ -0,0 +1,55 @@
+package backup
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// writeZip 写入包含指定条目的备份文件
+func writeZip(t *testing.T, path string, entries map[string]string) {
+	t.Helper()
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("创建备份文件失败: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range entries {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("写入条目失败: %v", err)
+		}
+		w.Write([]byte(content))
+	}
+	zw.Close()
+	f.Close()
+}
+
+func TestRestore_RejectsZipSlip(t *testing.T) {
+	base := t.TempDir()
+	backupDir := filepath.Join(base, "backups")
+	os.MkdirAll(backupDir, 0755)
+	bm := NewBackupManager(&types.BackupConfig{Enabled: true, Path: backupDir})
+
+	for i, name := range []string{"../evil.txt", "ok/../../evil.txt", "/tmp/evil.txt", `..\evil.txt`} {
+		id := "slip" + string(rune('a'+i))
+		writeZip(t, filepath.Join(backupDir, id+".zip"), map[string]string{name: "evil"})
+		if err := bm.Restore(id); err == nil {
+			t.Errorf("包含条目 %q 的备份应拒绝还原", name)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(base, "evil.txt")); !os.IsNotExist(err) {
+		t.Error("不应在备份目录之外写入文件")
+	}
+
+	writeZip(t, filepath.Join(backupDir, "good.zip"), map[string]string{"logs/main.log": "ok"})
+	if err := bm.Restore("good"); err != nil {
+		t.Fatalf("正常备份应可以还原: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(backupDir, "logs", "main.log")); string(data) != "ok" {
+		t.Error("还原的文件内容错误")
+	}
+}
@@ -7,8 +7,14 @@ import (
 	"time"
 
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/database"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/pins"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/preflight"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/scanner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/slim"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/ui"
 	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
 )
@@ -22,6 +28,10 @@ type CleanupEngine struct {
 	progress   *ui.ProgressDisplay
 	rules      []types.CleanupRule
 	safety     *SafetyChecker
+	quarantine *preflight.Quarantine
+	guard      *safety.Guard
+	pins       *pins.Registry
+	pinsErr    error
 }
 
 // SafetyChecker 安全检查器
@@ -43,6 +53,57 @@ func NewCleanupEngine(scanner scanner.Scanner, dbManager *database.DatabaseManag
 	}
 }
 
+// SetSafetyConfig 设置安全配置（最小磁盘空间、空间不足时隔离等）
+func (ce *CleanupEngine) SetSafetyConfig(config *types.SafetyConfig) {
+	ce.safety = &SafetyChecker{config: config}
+}
+
+// SetAllowedRoots 设置允许删除的根目录（默认为检测到的 Kiro 数据目录）
+func (ce *CleanupEngine) SetAllowedRoots(roots ...string) {
+	ce.guard = safety.NewGuard(roots...)
+}
+
+// CheckDiskSpace 检查备份卷是否有足够空间
+func (sc *SafetyChecker) CheckDiskSpace(targets []types.FileInfo, backupDir string) (*preflight.Result, error) {
+	return preflight.Check(targets, backupDir, sc.config)
+}
+
+// SetPins 设置固定的对话，这些文件不会匹配任何清理规则
+func (ce *CleanupEngine) SetPins(registry *pins.Registry) {
+	ce.pins = registry
+	ce.pinsErr = nil
+}
+
+// loadPins 未设置固定列表时从默认位置读取，读取失败时记录错误
+func (ce *CleanupEngine) loadPins() {
+	if ce.pins == nil {
+		ce.pins, ce.pinsErr = pins.Load(config.PinsPath())
+	}
+}
+
+// followPins 让固定项跟随被 Kiro 移动的对话（按 executionId 在扫描到的对话中查找），返回保存失败的错误
+func (ce *CleanupEngine) followPins(targets []types.FileInfo) error {
+	if ce.pinsErr != nil || len(ce.pins.Missing()) == 0 {
+		return nil
+	}
+	var chats []pins.Chat
+	for _, target := range targets {
+		if filepath.Ext(target.Path) != ".chat" {
+			continue
+		}
+		if chat, err := pins.ReadChat(target.Path); err == nil {
+			chats = append(chats, chat)
+		}
+	}
+	if ce.pins.Follow(chats) == 0 {
+		return nil
+	}
+	if err := ce.pins.Save(); err != nil {
+		return fmt.Errorf("更新固定对话路径失败: %v", err)
+	}
+	return nil
+}
+
 // SetRules 设置清理规则
 func (ce *CleanupEngine) SetRules(rules []types.CleanupRule) error {
 	ce.rules = rules
@@ -59,6 +120,11 @@ func (ce *CleanupEngine) Preview(targets []types.FileInfo) (*types.CleanupPrevie
 		Recommendations: []string{},
 	}
 	
+	ce.loadPins()
+	if err := ce.followPins(targets); err != nil {
+		preview.Warnings = append(preview.Warnings, err.Error())
+	}
+	
 	// 评估每个目标文件
 	for i, target := range targets {
 		action := ce.evaluateFile(target)
@@ -76,6 +142,11 @@ func (ce *CleanupEngine) Preview(targets []types.FileInfo) (*types.CleanupPrevie
 		}
 	}
 	
+	if ce.pinsErr != nil {
+		preview.Warnings = append(preview.Warnings,
+			fmt.Sprintf("%v，已跳过所有对话文件", ce.pinsErr))
+	}
+	
 	// 生成建议
 	preview.Recommendations = ce.generateRecommendations(preview)
 	
@@ -84,6 +155,15 @@ func (ce *CleanupEngine) Preview(targets []types.FileInfo) (*types.CleanupPrevie
 
 // evaluateFile 评估文件
 func (ce *CleanupEngine) evaluateFile(file types.FileInfo) *types.CleanupAction {
+	// 固定的对话永远不清理；固定列表读取失败时无法确认，对话一律不清理
+	ce.loadPins()
+	if ce.pins.Contains(file.Path) {
+		return nil
+	}
+	if ce.pinsErr != nil && filepath.Ext(file.Path) == ".chat" {
+		return nil
+	}
+	
 	// 检查是否匹配任何清理规则
 	for _, rule := range ce.rules {
 		if !rule.Enabled {
@@ -91,13 +171,19 @@ func (ce *CleanupEngine) evaluateFile(file types.FileInfo) *types.CleanupAction
 		}
 		
 		if ce.matchesRule(file, rule) {
-			return &types.CleanupAction{
+			action := &types.CleanupAction{
 				Type:    "delete",
 				Target:  file,
 				Rule:    rule.Name,
 				Reason:  rule.Description,
 				Size:    file.Size,
 			}
+			// 规则指定的动作（如 slim 只裁剪对话中的工具输出）
+			if len(rule.Actions) > 0 && rule.Actions[0].Type != "" {
+				action.Type = rule.Actions[0].Type
+				action.Params = rule.Actions[0].Params
+			}
+			return action
 		}
 	}
 	
@@ -137,6 +223,22 @@ func (ce *CleanupEngine) matchesCondition(file types.FileInfo, condition types.C
 		case "<":
 			return file.Size < condition.Value.(int64)
 		}
+	case "conversation_tokens":
+		// 对话的估算 token 数，只对 .chat 文件生效
+		limit, ok := numberValue(condition.Value)
+		if !ok || filepath.Ext(file.Path) != ".chat" {
+			return false
+		}
+		info, err := scanner.NewChatParser().ParseChatFile(file.Path)
+		if err != nil {
+			return false
+		}
+		switch condition.Operator {
+		case ">":
+			return int64(info.Tokens) > limit
+		case "<":
+			return int64(info.Tokens) < limit
+		}
 	case "file_name":
 		switch condition.Operator {
 		case "contains":
@@ -223,6 +325,8 @@ func (ce *CleanupEngine) isSafeToDelete(file types.FileInfo) bool {
 		return time.Since(file.Modified) > 7*24*time.Hour
 	case types.TypeCache:
 		return true // 缓存文件可以安全删除
+	case types.TypeOrphan:
+		return true // 没有被引用的快照和差异内容可以安全删除
 	case types.TypeDatabase:
 		// 数据库文件需要特殊处理
 		// .chat 文件可以在用户确认后删除
@@ -306,8 +410,40 @@ func (ce *CleanupEngine) Execute(targets []types.FileInfo, dryRun bool) (*types.
 		return result, nil
 	}
 	
+	// 备份前检查磁盘空间，不足时拒绝或改为隔离
+	withBackup := len(preview.Actions) > 0 && ce.backupMgr != nil
+	if withBackup {
+		check, err := ce.safety.CheckDiskSpace(targets, ce.backupMgr.GetBackupDir())
+		if err != nil {
+			ce.prompter.Warning(fmt.Sprintf("磁盘空间预检失败: %v", err))
+		} else if !check.OK {
+			message := fmt.Sprintf("备份需要约 %s 并保留 %s 剩余空间，但 %s 只有 %s 可用",
+				storage.FormatSize(check.EstimatedBackup), storage.FormatSize(check.MinFree),
+				check.BackupDir, storage.FormatSize(check.FreeBytes))
+			if !ce.safety.config.QuarantineFallback {
+				result.Success = false
+				result.Errors = append(result.Errors, types.CleanupError{
+					Code:        "insufficient_disk_space",
+					Message:     message,
+					Timestamp:   time.Now(),
+					Recoverable: true,
+				})
+				ce.prompter.Error(message)
+				return result, fmt.Errorf("磁盘空间不足: %s", message)
+			}
+			ce.prompter.Warning(message + "，改为隔离文件")
+			// 只有删除改为隔离；裁剪仍需逐个备份，备份失败时跳过
+			for i := range preview.Actions {
+				if preview.Actions[i].Type == "delete" {
+					preview.Actions[i].Type = "quarantine"
+				}
+			}
+			withBackup = false
+		}
+	}
+	
 	// 备份
-	if len(preview.Actions) > 0 && ce.backupMgr != nil {
+	if withBackup {
 		backupID, err := ce.backupMgr.CreateBackup(targets)
 		if err != nil {
 			ce.prompter.Warning(fmt.Sprintf("创建备份失败: %v", err))
@@ -324,7 +460,8 @@ func (ce *CleanupEngine) Execute(targets []types.FileInfo, dryRun bool) (*types.
 	for i, action := range preview.Actions {
 		ce.progress.SetCurrent(int64(i + 1))
 		
-		if err := ce.executeAction(action); err != nil {
+		freed, err := ce.executeAction(action)
+		if err != nil {
 			result.Success = false
 			result.Errors = append(result.Errors, types.CleanupError{
 				Code:        "action_failed",
@@ -337,7 +474,10 @@ func (ce *CleanupEngine) Execute(targets []types.FileInfo, dryRun bool) (*types.
 		}
 		
 		result.ActionsTaken = append(result.ActionsTaken, action)
-		result.BytesFreed += action.Size
+		if action.Type == "quarantine" {
+			result.BytesQuarantined += action.Size
+		}
+		result.BytesFreed += freed
 	}
 	
 	ce.progress.Finish()
@@ -346,14 +486,84 @@ func (ce *CleanupEngine) Execute(targets []types.FileInfo, dryRun bool) (*types.
 	return result, nil
 }
 
-// executeAction 执行单个操作
-func (ce *CleanupEngine) executeAction(action types.CleanupAction) error {
+// executeAction 执行单个操作，返回释放的字节数
+func (ce *CleanupEngine) executeAction(action types.CleanupAction) (int64, error) {
 	switch action.Type {
 	case "delete":
-		return ce.deleteFile(action.Target)
+		return action.Size, ce.deleteFile(action.Target)
+	case "quarantine":
+		if ce.quarantine == nil {
+			roots, _ := storage.NewStorageDetector().FindKiroPaths()
+			ce.quarantine = preflight.NewQuarantine(roots)
+		}
+		// 隔离的文件仍在磁盘上，不算释放的空间
+		_, err := ce.quarantine.Move(action.Target.Path)
+		return 0, err
+	case "slim":
+		return ce.slimFile(action)
 	default:
-		return fmt.Errorf("不支持的操作类型: %s", action.Type)
+		return 0, fmt.Errorf("不支持的操作类型: %s", action.Type)
+	}
+}
+
+// slimFile 裁剪对话文件中过大的工具输出，保留对话本身
+// 参数 threshold/keep 未设置时使用默认值；改写前先单独备份该文件，无法备份时不裁剪
+func (ce *CleanupEngine) slimFile(action types.CleanupAction) (int64, error) {
+	if filepath.Ext(action.Target.Path) != ".chat" {
+		return 0, fmt.Errorf("slim 只能用于 .chat 文件: %s", action.Target.Path)
+	}
+	if ce.guard == nil {
+		roots, _ := storage.NewStorageDetector().FindKiroPaths()
+		ce.guard = safety.NewGuard(roots...)
+	}
+	if err := ce.guard.Check(action.Target.Path); err != nil {
+		return 0, err
+	}
+	opts := slim.Options{
+		Threshold: intParam(action.Params, "threshold", slim.DefaultThreshold),
+		Keep:      intParam(action.Params, "keep", slim.DefaultKeep),
+	}
+	preview, err := slim.File(action.Target.Path, opts, false)
+	if err != nil || preview.Trimmed == 0 {
+		return 0, err
+	}
+	if ce.backupMgr == nil {
+		return 0, fmt.Errorf("未配置备份，跳过裁剪: %s", action.Target.Path)
+	}
+	if _, err := ce.backupMgr.BackupFile(action.Target.Path); err != nil {
+		return 0, fmt.Errorf("备份失败，跳过裁剪: %v", err)
+	}
+	result, err := slim.File(action.Target.Path, opts, true)
+	if err != nil {
+		return 0, err
+	}
+	return result.Saved(), nil
+}
+
+// intParam 读取动作参数中的整数（JSON 解码后为 float64）
+func intParam(params map[string]interface{}, key string, def int) int {
+	switch v := params[key].(type) {
+	case int:
+		return v
+	case int64:
+		return int(v)
+	case float64:
+		return int(v)
 	}
+	return def
+}
+
+// numberValue 读取条件中的数值（JSON 解码后为 float64）
+func numberValue(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int:
+		return int64(n), true
+	case int64:
+		return n, true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
 }
 
 // deleteFile 删除文件
@@ -363,8 +573,12 @@ func (ce *CleanupEngine) deleteFile(file types.FileInfo) error {
 		return fmt.Errorf("文件不存在: %s", file.Path)
 	}
 	
-	// 删除文件
-	return os.Remove(file.Path)
+	// 删除文件（经过路径守卫，拒绝 Kiro 数据目录之外的路径）
+	if ce.guard == nil {
+		roots, _ := storage.NewStorageDetector().FindKiroPaths()
+		ce.guard = safety.NewGuard(roots...)
+	}
+	return ce.guard.Remove(file.Path)
 }
 
 // Rollback 回滚操作
@@ -1,22 +1,126 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/profile"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
 )
 
 // GlobalConfig 全局配置
 type GlobalConfig struct {
 	// 清理选项（true=保留，false=清理）
-	KeepLogs   bool `json:"keep_logs"`   // 保留日志
-	KeepCache  bool `json:"keep_cache"`  // 保留缓存
-	KeepChats  bool `json:"keep_chats"`  // 保留对话
-	KeepIndex  bool `json:"keep_index"`  // 保留索引
-	KeepRecent int  `json:"keep_recent"` // 保留最近N天的文件（0=不保留）
+	KeepLogs      bool `json:"keep_logs"`       // 保留日志
+	KeepCache     bool `json:"keep_cache"`      // 保留缓存
+	KeepChats     bool `json:"keep_chats"`      // 保留对话
+	KeepIndex     bool `json:"keep_index"`      // 保留索引
+	KeepRecent    int  `json:"keep_recent"`     // 保留最近N天的文件（0=不保留）
+	KeepLastChats int  `json:"keep_last_chats"` // 每个工作区保留最新的N个对话（0=不限）
+	
+	// 崩溃报告保留策略
+	CrashKeepDays int  `json:"crash_keep_days"` // 崩溃报告保留天数（0=不按时间）
+	CrashKeepLast int  `json:"crash_keep_last"` // 始终保留最新的N个崩溃报告
+	CrashExport   bool `json:"crash_export"`    // 删除前导出崩溃报告
 	
 	// 行为选项
 	SkipConfirm bool `json:"skip_confirm"` // 跳过确认提示（等同于 -y/-f）
+	KillTimeout int  `json:"kill_timeout"` // 停止 Kiro 时等待正常退出的秒数，超时后强制结束
+	
+	// 存储趋势
+	TrendThreshold string `json:"trend_threshold"` // trend 命令估算何时达到的总大小（如 "10GB"）
+	
+	// 额外的 Kiro 用户数据目录（便携版、自定义 --user-data-dir 等）
+	KiroPaths []string `json:"kiro_paths,omitempty"`
+	
+	// 安全选项（备份前的磁盘空间预检等）
+	Safety types.SafetyConfig `json:"safety"`
+	
+	// auto 命令的清理阈值
+	Auto AutoConfig `json:"auto"`
+	
+	// 命名的清理预设（clean/scan --profile），同名时覆盖内置预设
+	Profiles map[string]profile.Profile `json:"profiles,omitempty"`
+	
+	// watch 命令的类别上限和超限动作
+	Watch WatchConfig `json:"watch"`
+	
+	// scan --secrets 的检测配置
+	Secrets SecretsConfig `json:"secrets"`
+	
+	// --all-users 管理员模式
+	Admin AdminConfig `json:"admin"`
+}
+
+// AdminConfig 管理员模式（以 root 处理所有用户）的配置
+type AdminConfig struct {
+	AllowUsers []string `json:"allow_users,omitempty"` // 允许处理的用户名，不在列表中的用户一律跳过
+}
+
+// SecretsConfig 敏感信息扫描配置
+type SecretsConfig struct {
+	Patterns   map[string]string `json:"patterns,omitempty"` // 自定义检测：类型名 -> 正则（有捕获组时只替换第一个捕获组）
+	MinEntropy float64           `json:"min_entropy"`        // 高熵字符串阈值（每字符比特数，0=不检测）
+}
+
+// WatchConfig watch 命令的配置
+type WatchConfig struct {
+	Limits          map[string]string `json:"limits,omitempty"` // 类别 -> 大小上限（如 "logs": "500MB"）
+	Action          string            `json:"action"`           // 超限时执行的子命令（如 "auto"），为空只提示
+	CooldownMinutes int               `json:"cooldown_minutes"` // 两次执行动作的最短间隔
+}
+
+// AutoConfig auto 命令的阈值配置（大小为空表示不检查）
+type AutoConfig struct {
+	MaxTotal          string `json:"max_total"`           // 总大小超过时清理日志、缓存和临时文件
+	MaxCache          string `json:"max_cache"`           // 缓存超过时清理缓存
+	MaxLogs           string `json:"max_logs"`            // 日志超过时清理日志
+	MaxIndex          string `json:"max_index"`           // 索引超过时清理索引
+	MaxTemp           string `json:"max_temp"`            // 临时文件超过时清理临时文件
+	ChatMaxAgeDays    int    `json:"chat_max_age_days"`   // 清理超过N天的对话（0=不清理）
+	AllowWhileRunning bool   `json:"allow_while_running"` // Kiro 运行时也清理（跳过被打开的文件）
+
+	// 命名的阈值组合，auto --profile 选择（未设置的字段沿用上面的值）
+	Profiles map[string]AutoConfig `json:"profiles,omitempty"`
+}
+
+// AutoProfile 返回指定名称的阈值配置，空名称或 "default" 返回顶层配置
+func (a AutoConfig) AutoProfile(name string) (AutoConfig, error) {
+	if name == "" || name == "default" {
+		return a, nil
+	}
+	p, ok := a.Profiles[name]
+	if !ok {
+		return a, fmt.Errorf("未找到 auto 配置组: %s", name)
+	}
+	merged := a
+	merged.Profiles = nil
+	if p.MaxTotal != "" {
+		merged.MaxTotal = p.MaxTotal
+	}
+	if p.MaxCache != "" {
+		merged.MaxCache = p.MaxCache
+	}
+	if p.MaxLogs != "" {
+		merged.MaxLogs = p.MaxLogs
+	}
+	if p.MaxIndex != "" {
+		merged.MaxIndex = p.MaxIndex
+	}
+	if p.MaxTemp != "" {
+		merged.MaxTemp = p.MaxTemp
+	}
+	if p.ChatMaxAgeDays != 0 {
+		merged.ChatMaxAgeDays = p.ChatMaxAgeDays
+	}
+	if p.AllowWhileRunning {
+		merged.AllowWhileRunning = true
+	}
+	return merged, nil
 }
 
 // DefaultConfig 默认配置（全部清理）
@@ -27,7 +131,27 @@ func DefaultConfig() *GlobalConfig {
 		KeepChats:   false,
 		KeepIndex:   false,
 		KeepRecent:  0,
+		CrashKeepDays: 30,
+		CrashKeepLast: 3,
+		CrashExport:   true,
 		SkipConfirm: false,
+		KillTimeout: 10,
+		TrendThreshold: "10GB",
+		Safety: types.SafetyConfig{
+			MinDiskSpace:       "100MB",
+			QuarantineFallback: false,
+		},
+		Auto: AutoConfig{
+			MaxTotal: "2GB",
+			MaxCache: "500MB",
+			MaxLogs:  "200MB",
+		},
+		Watch: WatchConfig{
+			CooldownMinutes: 10,
+		},
+		Secrets: SecretsConfig{
+			MinEntropy: 4.5,
+		},
 	}
 }
 
@@ -37,6 +161,36 @@ func ConfigDir() string {
 	return filepath.Join(home, ".kiro-cleaner")
 }
 
+// CrashReportsDir 获取崩溃报告导出目录
+func CrashReportsDir() string {
+	return filepath.Join(ConfigDir(), "crash-reports")
+}
+
+// ExportsDir 获取项目策略要求的删除前导出目录
+func ExportsDir() string {
+	return filepath.Join(ConfigDir(), "exports")
+}
+
+// ArchiveDir 获取对话归档目录
+func ArchiveDir() string {
+	return filepath.Join(ConfigDir(), "archive")
+}
+
+// PinsPath 获取固定对话列表路径
+func PinsPath() string {
+	return filepath.Join(ConfigDir(), "pins.json")
+}
+
+// AuditLogPath 获取审计日志路径
+func AuditLogPath() string {
+	return filepath.Join(ConfigDir(), "audit.jsonl")
+}
+
+// TrendPath 获取存储快照文件路径
+func TrendPath() string {
+	return filepath.Join(ConfigDir(), "trend.jsonl")
+}
+
 // ConfigPath 获取配置文件路径
 func ConfigPath() string {
 	return filepath.Join(ConfigDir(), "config.json")
@@ -70,6 +224,16 @@ func SaveConfig(cfg *GlobalConfig) error {
 	return os.WriteFile(ConfigPath(), data, 0644)
 }
 
+// Hash 返回配置内容的短哈希，用于在审计日志中区分不同配置
+func (c *GlobalConfig) Hash() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])[:12]
+}
+
 // EnsureConfigExists 确保配置文件存在，不存在则创建默认配置
 func EnsureConfigExists() error {
 	if _, err := os.Stat(ConfigPath()); os.IsNotExist(err) {
@@ -0,0 +1,226 @@
+package crashpad
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
+)
+
+// DirName Crashpad 数据库目录名
+const DirName = "Crashpad"
+
+// 报告状态（Crashpad 数据库中的子目录）
+const (
+	StateNew       = "new"
+	StatePending   = "pending"
+	StateCompleted = "completed"
+)
+
+// reportStates 扫描的报告状态目录
+var reportStates = []string{StateNew, StatePending, StateCompleted}
+
+// Report 崩溃报告
+type Report struct {
+	ID        string    // 报告ID（minidump 文件名，不含扩展名）
+	State     string    // new / pending / completed
+	Path      string    // minidump 文件路径
+	Files     []string  // 报告相关的所有文件和目录（minidump、.meta、附件）
+	Size      int64     // 报告总大小
+	Timestamp time.Time // 崩溃时间（minidump 头部时间，缺失时使用修改时间）
+	Dump      *DumpInfo // minidump 解析结果，解析失败时为 nil
+	ParseErr  error     // minidump 解析错误
+}
+
+// Age 返回报告距今的时长
+func (r Report) Age() time.Duration {
+	return time.Since(r.Timestamp)
+}
+
+// Retention 崩溃报告保留策略
+type Retention struct {
+	MaxAge   time.Duration // 超过该时长的报告可删除（0=不按时间）
+	KeepLast int           // 至少保留最新的 N 个报告
+}
+
+// DeleteResult 删除结果
+type DeleteResult struct {
+	Deleted    int
+	FreedBytes int64
+	Errors     []error
+}
+
+// Manager Crashpad 报告管理器
+type Manager struct {
+	root string
+}
+
+// Root 返回 Kiro 数据目录下的 Crashpad 目录
+func Root(kiroPath string) string {
+	return filepath.Join(kiroPath, DirName)
+}
+
+// NewManager 创建 Crashpad 报告管理器
+func NewManager(root string) *Manager {
+	return &Manager{root: root}
+}
+
+// BasePath 返回 Crashpad 目录
+func (m *Manager) BasePath() string {
+	return m.root
+}
+
+// Contains 判断路径是否位于 Crashpad 目录内
+func (m *Manager) Contains(path string) bool {
+	rel, err := filepath.Rel(m.root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// Scan 列出所有崩溃报告，按时间从新到旧排序
+func (m *Manager) Scan() ([]Report, error) {
+	if _, err := os.Stat(m.root); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	var reports []Report
+	for _, state := range reportStates {
+		stateDir := filepath.Join(m.root, state)
+		entries, err := os.ReadDir(stateDir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || !strings.EqualFold(filepath.Ext(entry.Name()), ".dmp") {
+				continue
+			}
+			reports = append(reports, m.loadReport(state, filepath.Join(stateDir, entry.Name())))
+		}
+	}
+
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].Timestamp.After(reports[j].Timestamp)
+	})
+	return reports, nil
+}
+
+// Find 按ID（或ID前缀）查找报告
+func (m *Manager) Find(id string) (*Report, error) {
+	reports, err := m.Scan()
+	if err != nil {
+		return nil, err
+	}
+	var found *Report
+	for i := range reports {
+		if reports[i].ID == id {
+			return &reports[i], nil
+		}
+		if strings.HasPrefix(reports[i].ID, id) {
+			if found != nil {
+				return nil, fmt.Errorf("报告ID前缀不唯一: %s", id)
+			}
+			found = &reports[i]
+		}
+	}
+	if found == nil {
+		return nil, fmt.Errorf("未找到崩溃报告: %s", id)
+	}
+	return found, nil
+}
+
+// loadReport 读取单个报告的文件信息和 minidump 头部
+func (m *Manager) loadReport(state, dumpPath string) Report {
+	id := strings.TrimSuffix(filepath.Base(dumpPath), filepath.Ext(dumpPath))
+	report := Report{
+		ID:    id,
+		State: state,
+		Path:  dumpPath,
+	}
+
+	candidates := []string{
+		dumpPath,
+		strings.TrimSuffix(dumpPath, filepath.Ext(dumpPath)) + ".meta",
+		filepath.Join(m.root, "attachments", id),
+	}
+	for _, path := range candidates {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		report.Files = append(report.Files, path)
+		if info.IsDir() {
+			report.Size += dirSize(path)
+		} else {
+			report.Size += info.Size()
+		}
+		if path == dumpPath {
+			report.Timestamp = info.ModTime()
+		}
+	}
+
+	report.Dump, report.ParseErr = ParseMinidump(dumpPath)
+	if report.Dump != nil && !report.Dump.Timestamp.IsZero() {
+		report.Timestamp = report.Dump.Timestamp
+	}
+	return report
+}
+
+// Plan 根据保留策略选出可删除的报告
+// 最新的 KeepLast 个报告始终保留，其余报告超过 MaxAge 才会删除
+func Plan(reports []Report, retention Retention) []Report {
+	sorted := make([]Report, len(reports))
+	copy(sorted, reports)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.After(sorted[j].Timestamp)
+	})
+
+	var expired []Report
+	for i, r := range sorted {
+		if i < retention.KeepLast {
+			continue
+		}
+		if retention.MaxAge > 0 && r.Age() < retention.MaxAge {
+			continue
+		}
+		expired = append(expired, r)
+	}
+	return expired
+}
+
+// Delete 删除报告及其 .meta 和附件
+func (m *Manager) Delete(reports []Report) *DeleteResult {
+	result := &DeleteResult{}
+	guard := safety.NewGuard(m.root)
+	for _, r := range reports {
+		var failed bool
+		for _, path := range r.Files {
+			if err := guard.RemoveAll(path); err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("删除 %s 失败: %v", path, err))
+				failed = true
+			}
+		}
+		if !failed {
+			result.Deleted++
+			result.FreedBytes += r.Size
+		}
+	}
+	return result
+}
+
+// dirSize 计算目录大小
+func dirSize(path string) int64 {
+	var size int64
+	filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size
+}
@@ -0,0 +1,186 @@
+package crashpad
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+)
+
+// buildMinidump 构造包含模块列表、异常流和 Crashpad 注释的最小 minidump
+func buildMinidump(t *testing.T, ts time.Time, ptype, module string, base, excAddr uint64) []byte {
+	t.Helper()
+	le := binary.LittleEndian
+	buf := make([]byte, 2048)
+
+	// 头部
+	le.PutUint32(buf[0:], minidumpSignature)
+	le.PutUint32(buf[8:], 3)   // 流数量
+	le.PutUint32(buf[12:], 32) // 流目录位置
+	le.PutUint32(buf[20:], uint32(ts.Unix()))
+
+	// 流目录
+	putDir := func(i int, typ, size, rva uint32) {
+		off := 32 + i*directoryEntrySize
+		le.PutUint32(buf[off:], typ)
+		le.PutUint32(buf[off+4:], size)
+		le.PutUint32(buf[off+8:], rva)
+	}
+	putDir(0, streamModuleList, 4+moduleEntrySize, 100)
+	putDir(1, streamException, 168, 400)
+	putDir(2, streamCrashpadInfo, 52, 700)
+
+	// 模块列表：1 个模块，名称位于 1000
+	le.PutUint32(buf[100:], 1)
+	le.PutUint64(buf[104:], base)
+	le.PutUint32(buf[112:], 0x10000)
+	le.PutUint32(buf[124:], 1000)
+	name := utf16.Encode([]rune(module))
+	le.PutUint32(buf[1000:], uint32(len(name)*2))
+	for i, u := range name {
+		le.PutUint16(buf[1004+i*2:], u)
+	}
+
+	// 异常流
+	le.PutUint32(buf[408:], 0xC0000005)
+	le.PutUint64(buf[424:], excAddr)
+
+	// Crashpad 信息：simple_annotations 字典位于 1400
+	le.PutUint32(buf[736:], 12)
+	le.PutUint32(buf[740:], 1400)
+	le.PutUint32(buf[1400:], 1)
+	le.PutUint32(buf[1404:], 1500)
+	le.PutUint32(buf[1408:], 1600)
+	le.PutUint32(buf[1500:], 5)
+	copy(buf[1504:], "ptype")
+	le.PutUint32(buf[1600:], uint32(len(ptype)))
+	copy(buf[1604:], ptype)
+
+	return buf
+}
+
+// createReport 在 Crashpad 目录中创建一个报告
+func createReport(t *testing.T, root, state, id string, ts time.Time) {
+	t.Helper()
+	dir := filepath.Join(root, state)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	data := buildMinidump(t, ts, "renderer", `C:\Program Files\Kiro\Kiro.exe`, 0x400000, 0x401234)
+	if err := os.WriteFile(filepath.Join(dir, id+".dmp"), data, 0644); err != nil {
+		t.Fatalf("写入 minidump 失败: %v", err)
+	}
+	os.WriteFile(filepath.Join(dir, id+".meta"), []byte("meta"), 0644)
+}
+
+func TestParseMinidump(t *testing.T) {
+	ts := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	data := buildMinidump(t, ts, "gpu-process", "/opt/Kiro/libGLESv2.so", 0x7f0000, 0x7f0100)
+
+	info, err := parseMinidump(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if !info.Timestamp.Equal(ts) {
+		t.Errorf("时间错误: %v", info.Timestamp)
+	}
+	if info.ProcessType != "gpu-process" {
+		t.Errorf("进程类型错误: %q", info.ProcessType)
+	}
+	if info.CrashModule != "libGLESv2.so" {
+		t.Errorf("崩溃模块错误: %q", info.CrashModule)
+	}
+	if info.ExceptionCode != 0xC0000005 {
+		t.Errorf("异常代码错误: 0x%x", info.ExceptionCode)
+	}
+}
+
+func TestParseMinidump_Invalid(t *testing.T) {
+	if _, err := parseMinidump(bytes.NewReader([]byte("not a minidump at all, definitely"))); err == nil {
+		t.Error("非 minidump 数据应该返回错误")
+	}
+	if _, err := parseMinidump(bytes.NewReader([]byte("MDMP"))); err == nil {
+		t.Error("截断的数据应该返回错误")
+	}
+}
+
+func TestManager_ScanAndPlan(t *testing.T) {
+	root := t.TempDir()
+	now := time.Now()
+	createReport(t, root, StateCompleted, "aaaa", now.AddDate(0, 0, -1))
+	createReport(t, root, StateCompleted, "bbbb", now.AddDate(0, 0, -40))
+	createReport(t, root, StatePending, "cccc", now.AddDate(0, 0, -60))
+	os.MkdirAll(filepath.Join(root, "attachments", "cccc"), 0755)
+	os.WriteFile(filepath.Join(root, "attachments", "cccc", "log.txt"), []byte("attachment"), 0644)
+
+	mgr := NewManager(root)
+	reports, err := mgr.Scan()
+	if err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if len(reports) != 3 {
+		t.Fatalf("应该有 3 个报告，实际 %d", len(reports))
+	}
+	if reports[0].ID != "aaaa" || reports[2].ID != "cccc" {
+		t.Errorf("报告应按时间从新到旧排序: %s, %s", reports[0].ID, reports[2].ID)
+	}
+	if reports[2].State != StatePending || len(reports[2].Files) != 3 {
+		t.Errorf("pending 报告应包含 dmp、meta 和附件: %+v", reports[2].Files)
+	}
+	if reports[0].Dump == nil || reports[0].Dump.ProcessType != "renderer" {
+		t.Errorf("应该解析出进程类型")
+	}
+
+	expired := Plan(reports, Retention{MaxAge: 30 * 24 * time.Hour, KeepLast: 2})
+	if len(expired) != 1 || expired[0].ID != "cccc" {
+		t.Fatalf("应只删除超出保留数量且过期的 cccc: %+v", expired)
+	}
+
+	result := mgr.Delete(expired)
+	if len(result.Errors) > 0 || result.Deleted != 1 {
+		t.Fatalf("删除失败: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(root, "attachments", "cccc")); !os.IsNotExist(err) {
+		t.Error("附件目录应该被删除")
+	}
+	if _, err := os.Stat(filepath.Join(root, StateCompleted, "bbbb.dmp")); err != nil {
+		t.Error("保留的报告不应被删除")
+	}
+}
+
+func TestExport(t *testing.T) {
+	root := t.TempDir()
+	createReport(t, root, StateCompleted, "aaaa", time.Now())
+	reports, _ := NewManager(root).Scan()
+
+	bundle, err := Export(reports, t.TempDir())
+	if err != nil {
+		t.Fatalf("导出失败: %v", err)
+	}
+
+	zr, err := zip.OpenReader(bundle)
+	if err != nil {
+		t.Fatalf("打开导出文件失败: %v", err)
+	}
+	defer zr.Close()
+
+	names := make(map[string]bool)
+	for _, f := range zr.File {
+		names[f.Name] = true
+	}
+	for _, want := range []string{"summary.txt", "completed/aaaa/aaaa.dmp", "completed/aaaa/aaaa.meta"} {
+		if !names[want] {
+			t.Errorf("导出文件缺少 %s", want)
+		}
+	}
+
+	summary := Summary(reports)
+	if !strings.Contains(summary, "renderer") || !strings.Contains(summary, "Kiro.exe") {
+		t.Errorf("摘要应包含进程类型和崩溃模块:\n%s", summary)
+	}
+}
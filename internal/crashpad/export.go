@@ -0,0 +1,141 @@
+package crashpad
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
+)
+
+// Export 将报告打包为 zip（含 summary.txt），返回生成的文件路径
+func Export(reports []Report, destDir string) (string, error) {
+	if len(reports) == 0 {
+		return "", fmt.Errorf("没有需要导出的崩溃报告")
+	}
+	if err := os.MkdirAll(destDir, 0755); err != nil {
+		return "", fmt.Errorf("创建导出目录失败: %v", err)
+	}
+
+	bundlePath := filepath.Join(destDir, fmt.Sprintf("kiro-crash-%s.zip", time.Now().Format("20060102_150405")))
+	out, err := os.Create(bundlePath)
+	if err != nil {
+		return "", fmt.Errorf("创建导出文件失败: %v", err)
+	}
+
+	zw := zip.NewWriter(out)
+	writeErr := writeBundle(zw, reports)
+	if err := zw.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if err := out.Close(); err != nil && writeErr == nil {
+		writeErr = err
+	}
+	if writeErr != nil {
+		os.Remove(bundlePath)
+		return "", fmt.Errorf("写入导出文件失败: %v", writeErr)
+	}
+	return bundlePath, nil
+}
+
+// writeBundle 写入报告文件和摘要
+func writeBundle(zw *zip.Writer, reports []Report) error {
+	summary, err := zw.Create("summary.txt")
+	if err != nil {
+		return err
+	}
+	if _, err := io.WriteString(summary, Summary(reports)); err != nil {
+		return err
+	}
+
+	for _, r := range reports {
+		for _, path := range r.Files {
+			info, err := os.Stat(path)
+			if err != nil {
+				return err
+			}
+			prefix := filepath.ToSlash(filepath.Join(r.State, r.ID))
+			if !info.IsDir() {
+				if err := addFile(zw, path, prefix+"/"+filepath.Base(path)); err != nil {
+					return err
+				}
+				continue
+			}
+			err = filepath.Walk(path, func(p string, fi os.FileInfo, err error) error {
+				if err != nil || fi.IsDir() || safety.IsSymlink(fi) {
+					return err
+				}
+				rel, err := filepath.Rel(path, p)
+				if err != nil {
+					return err
+				}
+				return addFile(zw, p, prefix+"/attachments/"+filepath.ToSlash(rel))
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addFile 将单个文件写入 zip
+func addFile(zw *zip.Writer, src, name string) error {
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// Summary 生成报告摘要文本，用于提交问题
+func Summary(reports []Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Kiro crash reports (%d)\n", len(reports))
+	fmt.Fprintf(&b, "Exported: %s\n\n", time.Now().Format(time.RFC3339))
+
+	for _, r := range reports {
+		fmt.Fprintf(&b, "Report:    %s\n", r.ID)
+		fmt.Fprintf(&b, "State:     %s\n", r.State)
+		fmt.Fprintf(&b, "Time:      %s\n", r.Timestamp.Format(time.RFC3339))
+		fmt.Fprintf(&b, "Size:      %d bytes\n", r.Size)
+		if r.Dump == nil {
+			if r.ParseErr != nil {
+				fmt.Fprintf(&b, "Minidump:  %v\n", r.ParseErr)
+			}
+			b.WriteString("\n")
+			continue
+		}
+		if r.Dump.ProcessType != "" {
+			fmt.Fprintf(&b, "Process:   %s\n", r.Dump.ProcessType)
+		}
+		if r.Dump.ExceptionCode != 0 {
+			fmt.Fprintf(&b, "Exception: 0x%08x at 0x%x\n", r.Dump.ExceptionCode, r.Dump.ExceptionAddress)
+		}
+		if r.Dump.CrashModule != "" {
+			fmt.Fprintf(&b, "Module:    %s\n", r.Dump.CrashModule)
+		}
+		keys := make([]string, 0, len(r.Dump.Annotations))
+		for k := range r.Dump.Annotations {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(&b, "  %s = %s\n", k, r.Dump.Annotations[k])
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
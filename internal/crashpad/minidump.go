@@ -0,0 +1,229 @@
+package crashpad
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf16"
+)
+
+// minidump 格式常量
+const (
+	minidumpSignature     = 0x504d444d // "MDMP"
+	streamModuleList      = 4
+	streamException       = 6
+	streamCrashpadInfo    = 0x43500001
+	minidumpHeaderSize    = 32
+	directoryEntrySize    = 12
+	moduleEntrySize       = 108
+	maxAnnotationCount    = 1024
+	maxMinidumpStringSize = 64 * 1024
+)
+
+// Module 崩溃时加载的模块
+type Module struct {
+	Name string
+	Base uint64
+	Size uint32
+}
+
+// DumpInfo minidump 头部及关键流中的信息
+type DumpInfo struct {
+	Timestamp        time.Time         // 崩溃时间
+	ProcessType      string            // 进程类型（browser、renderer、gpu-process 等）
+	ExceptionCode    uint32            // 异常代码
+	ExceptionAddress uint64            // 异常地址
+	CrashModule      string            // 异常地址所在模块
+	Modules          []Module          // 模块列表
+	Annotations      map[string]string // Crashpad 简单注释（ptype、ver、prod 等）
+}
+
+// ParseMinidump 读取 minidump 头部与流目录，提取进程类型和崩溃模块
+func ParseMinidump(path string) (*DumpInfo, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开 minidump 失败: %v", err)
+	}
+	defer f.Close()
+	return parseMinidump(f)
+}
+
+// parseMinidump 从 ReaderAt 解析 minidump
+func parseMinidump(r io.ReaderAt) (*DumpInfo, error) {
+	header := make([]byte, minidumpHeaderSize)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		return nil, fmt.Errorf("读取 minidump 头部失败: %v", err)
+	}
+	le := binary.LittleEndian
+	if le.Uint32(header[0:]) != minidumpSignature {
+		return nil, fmt.Errorf("不是有效的 minidump 文件")
+	}
+
+	streamCount := le.Uint32(header[8:])
+	dirRva := le.Uint32(header[12:])
+	info := &DumpInfo{
+		Annotations: make(map[string]string),
+	}
+	if ts := le.Uint32(header[20:]); ts > 0 {
+		info.Timestamp = time.Unix(int64(ts), 0)
+	}
+	if streamCount > 4096 {
+		return nil, fmt.Errorf("minidump 流数量异常: %d", streamCount)
+	}
+
+	dir := make([]byte, int(streamCount)*directoryEntrySize)
+	if _, err := r.ReadAt(dir, int64(dirRva)); err != nil {
+		return nil, fmt.Errorf("读取流目录失败: %v", err)
+	}
+
+	for i := 0; i < int(streamCount); i++ {
+		entry := dir[i*directoryEntrySize:]
+		streamType := le.Uint32(entry[0:])
+		dataSize := le.Uint32(entry[4:])
+		rva := le.Uint32(entry[8:])
+
+		switch streamType {
+		case streamModuleList:
+			info.Modules = readModuleList(r, rva, dataSize)
+		case streamException:
+			buf := make([]byte, 32)
+			if _, err := r.ReadAt(buf, int64(rva)); err == nil {
+				info.ExceptionCode = le.Uint32(buf[8:])
+				info.ExceptionAddress = le.Uint64(buf[24:])
+			}
+		case streamCrashpadInfo:
+			readCrashpadAnnotations(r, rva, info.Annotations)
+		}
+	}
+
+	info.ProcessType = info.Annotations["ptype"]
+	if info.ExceptionAddress != 0 {
+		for _, m := range info.Modules {
+			if info.ExceptionAddress >= m.Base && info.ExceptionAddress < m.Base+uint64(m.Size) {
+				info.CrashModule = m.Name
+				break
+			}
+		}
+	}
+	return info, nil
+}
+
+// readModuleList 读取模块列表流
+func readModuleList(r io.ReaderAt, rva, size uint32) []Module {
+	le := binary.LittleEndian
+	countBuf := make([]byte, 4)
+	if _, err := r.ReadAt(countBuf, int64(rva)); err != nil {
+		return nil
+	}
+	count := le.Uint32(countBuf)
+	if size > 4 && uint64(count)*moduleEntrySize > uint64(size-4) {
+		count = (size - 4) / moduleEntrySize
+	}
+
+	var modules []Module
+	entry := make([]byte, moduleEntrySize)
+	for i := uint32(0); i < count; i++ {
+		if _, err := r.ReadAt(entry, int64(rva)+4+int64(i)*moduleEntrySize); err != nil {
+			break
+		}
+		name, _ := readMinidumpString(r, le.Uint32(entry[20:]))
+		modules = append(modules, Module{
+			Name: moduleBaseName(name),
+			Base: le.Uint64(entry[0:]),
+			Size: le.Uint32(entry[8:]),
+		})
+	}
+	return modules
+}
+
+// readCrashpadAnnotations 读取 Crashpad 信息流中的简单注释
+func readCrashpadAnnotations(r io.ReaderAt, rva uint32, out map[string]string) {
+	le := binary.LittleEndian
+	// version(4) + report_id(16) + client_id(16) + simple_annotations 位置(8)
+	buf := make([]byte, 44)
+	if _, err := r.ReadAt(buf, int64(rva)); err != nil {
+		return
+	}
+	dictRva := le.Uint32(buf[40:])
+	if dictRva == 0 {
+		return
+	}
+
+	countBuf := make([]byte, 4)
+	if _, err := r.ReadAt(countBuf, int64(dictRva)); err != nil {
+		return
+	}
+	count := le.Uint32(countBuf)
+	if count > maxAnnotationCount {
+		return
+	}
+
+	entry := make([]byte, 8)
+	for i := uint32(0); i < count; i++ {
+		if _, err := r.ReadAt(entry, int64(dictRva)+4+int64(i)*8); err != nil {
+			return
+		}
+		key, err := readUTF8String(r, le.Uint32(entry[0:]))
+		if err != nil {
+			continue
+		}
+		value, err := readUTF8String(r, le.Uint32(entry[4:]))
+		if err != nil {
+			continue
+		}
+		out[key] = value
+	}
+}
+
+// readMinidumpString 读取 UTF-16LE 编码的 MINIDUMP_STRING
+func readMinidumpString(r io.ReaderAt, rva uint32) (string, error) {
+	data, err := readLengthPrefixed(r, rva)
+	if err != nil {
+		return "", err
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(data[i*2:])
+	}
+	return string(utf16.Decode(units)), nil
+}
+
+// readUTF8String 读取 Crashpad 的 UTF-8 字符串
+func readUTF8String(r io.ReaderAt, rva uint32) (string, error) {
+	data, err := readLengthPrefixed(r, rva)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// readLengthPrefixed 读取带 32 位长度前缀的数据
+func readLengthPrefixed(r io.ReaderAt, rva uint32) ([]byte, error) {
+	if rva == 0 {
+		return nil, fmt.Errorf("无效的字符串位置")
+	}
+	lenBuf := make([]byte, 4)
+	if _, err := r.ReadAt(lenBuf, int64(rva)); err != nil {
+		return nil, err
+	}
+	length := binary.LittleEndian.Uint32(lenBuf)
+	if length > maxMinidumpStringSize {
+		return nil, fmt.Errorf("字符串长度异常: %d", length)
+	}
+	data := make([]byte, length)
+	if _, err := r.ReadAt(data, int64(rva)+4); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// moduleBaseName 取模块路径中的文件名（兼容 Windows 路径）
+func moduleBaseName(name string) string {
+	if i := strings.LastIndexAny(name, `/\`); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
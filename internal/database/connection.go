@@ -6,6 +6,7 @@ import (
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/tokens"
 	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
 )
 
@@ -79,7 +80,7 @@ func (cdao *ConversationDAO) GetAll() ([]types.Conversation, error) {
 		SELECT 
 			id, title, created_at, updated_at, 
 			(SELECT COUNT(*) FROM messages WHERE conversation_id = conversations.id) as message_count,
-			(SELECT COUNT(*) FROM messages WHERE conversation_id = conversations.id AND role = 'user') as user_message_count
+			(SELECT COALESCE(SUM(tokens), 0) FROM messages WHERE conversation_id = conversations.id) as token_count
 		FROM conversations 
 		ORDER BY updated_at DESC
 	`)
@@ -100,7 +101,7 @@ func (cdao *ConversationDAO) GetAll() ([]types.Conversation, error) {
 			&createdAt,
 			&updatedAt,
 			&conv.MessageCount,
-			&conv.TokenCount, // 暂时设为0，后续可以计算
+			&conv.TokenCount,
 		)
 		if err != nil {
 			return nil, err
@@ -122,9 +123,39 @@ func (cdao *ConversationDAO) GetAll() ([]types.Conversation, error) {
 		conversations = append(conversations, conv)
 	}
 	
+	rows.Close()
+	
+	// 数据库没有记录 token 数的对话按消息内容估算
+	for i := range conversations {
+		if conversations[i].TokenCount == 0 && conversations[i].MessageCount > 0 {
+			if n, err := cdao.estimateTokens(conversations[i].ID); err == nil {
+				conversations[i].TokenCount = n
+			}
+		}
+	}
+	
 	return conversations, nil
 }
 
+// estimateTokens 估算对话所有消息的 token 数
+func (cdao *ConversationDAO) estimateTokens(id int64) (int, error) {
+	rows, err := cdao.db.Query(`SELECT content FROM messages WHERE conversation_id = ?`, id)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	
+	total := 0
+	for rows.Next() {
+		var content sql.NullString
+		if err := rows.Scan(&content); err != nil {
+			return 0, err
+		}
+		total += tokens.Estimate(content.String)
+	}
+	return total, rows.Err()
+}
+
 // GetByID 根据ID获取对话
 func (cdao *ConversationDAO) GetByID(id int64) (*types.Conversation, error) {
 	row := cdao.db.QueryRow(`
@@ -261,6 +292,10 @@ func (mdao *MessageDAO) GetByConversationID(conversationID int64) ([]types.Messa
 		if timestamp.Valid {
 			msg.Timestamp = timestamp.Time
 		}
+		// 数据库没有记录 token 数时使用估算值
+		if msg.Tokens == 0 {
+			msg.Tokens = tokens.Estimate(msg.Content)
+		}
 		
 		messages = append(messages, msg)
 	}
@@ -0,0 +1,198 @@
+package dedupe
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// 保留策略
+const (
+	KeepLongest = "longest" // 保留消息最多的副本（相同时取最新）
+	KeepNewest  = "newest"  // 保留最后活动的副本（相同时取消息最多），消息更多的副本不会被删除
+)
+
+// 重复副本相对于保留副本的关系
+const (
+	KindExact  = "exact"  // 消息序列完全相同
+	KindPrefix = "prefix" // 是保留副本的严格前缀
+)
+
+// Chat 一个对话文件的消息序列指纹
+type Chat struct {
+	Path      string
+	Workspace string // 所在的工作区目录，只在同一工作区内比较
+	Size      int64
+	Updated   time.Time // 元数据中的结束时间，缺失时为文件修改时间
+	Messages  int
+	Prefixes  []string // 第 i 项为前 i+1 条消息的累计哈希
+}
+
+// Hash 返回整个消息序列的哈希，没有消息时为空
+func (c Chat) Hash() string {
+	if len(c.Prefixes) == 0 {
+		return ""
+	}
+	return c.Prefixes[len(c.Prefixes)-1]
+}
+
+// Duplicate 可以删除的重复副本
+type Duplicate struct {
+	Chat
+	Kind string
+}
+
+// Group 一组重复的对话：保留一个，其余可删除
+type Group struct {
+	Keep       Chat
+	Duplicates []Duplicate
+}
+
+// Reclaimable 返回删除重复副本能释放的字节数
+func (g Group) Reclaimable() int64 {
+	var total int64
+	for _, d := range g.Duplicates {
+		total += d.Size
+	}
+	return total
+}
+
+// Fingerprint 计算消息序列各前缀的累计哈希
+// 角色不区分大小写，内容去掉首尾空白并把连续空白视为一个空格
+func Fingerprint(messages []types.ChatMessage) []string {
+	prefixes := make([]string, 0, len(messages))
+	var prev []byte
+	for _, msg := range messages {
+		h := sha256.New()
+		h.Write(prev)
+		h.Write([]byte(strings.ToLower(strings.TrimSpace(msg.Role))))
+		h.Write([]byte{0})
+		h.Write([]byte(strings.Join(strings.Fields(msg.Content), " ")))
+		prev = h.Sum(nil)
+		prefixes = append(prefixes, hex.EncodeToString(prev))
+	}
+	return prefixes
+}
+
+// ReadChat 读取 .chat 文件并计算指纹
+func ReadChat(path string) (Chat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Chat{}, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Chat{}, fmt.Errorf("读取文件失败: %v", err)
+	}
+	var chatFile types.ChatFile
+	if err := json.Unmarshal(data, &chatFile); err != nil {
+		return Chat{}, fmt.Errorf("解析 JSON 失败: %v", err)
+	}
+
+	updated := info.ModTime()
+	if chatFile.Metadata.EndTime > 0 {
+		updated = time.UnixMilli(chatFile.Metadata.EndTime)
+	}
+	return Chat{
+		Path:      path,
+		Workspace: filepath.Dir(path),
+		Size:      info.Size(),
+		Updated:   updated,
+		Messages:  len(chatFile.Chat),
+		Prefixes:  Fingerprint(chatFile.Chat),
+	}, nil
+}
+
+// Find 在每个工作区内找出完全相同或互为前缀的对话，按可释放空间降序返回
+// 没有消息的对话不参与比较
+func Find(chats []Chat, keep string) []Group {
+	sorted := make([]Chat, 0, len(chats))
+	for _, c := range chats {
+		if len(c.Prefixes) > 0 {
+			sorted = append(sorted, c)
+		}
+	}
+	// 长的先处理，短的对话才能归入包含它的那一组
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Messages != sorted[j].Messages {
+			return sorted[i].Messages > sorted[j].Messages
+		}
+		if !sorted[i].Updated.Equal(sorted[j].Updated) {
+			return sorted[i].Updated.After(sorted[j].Updated)
+		}
+		return sorted[i].Path < sorted[j].Path
+	})
+
+	var clusters [][]Chat
+	index := make(map[string]int) // 工作区 + 前缀哈希 -> 所在的组
+	for _, c := range sorted {
+		if i, ok := index[c.Workspace+"\x00"+c.Hash()]; ok {
+			clusters[i] = append(clusters[i], c)
+			continue
+		}
+		clusters = append(clusters, []Chat{c})
+		for _, prefix := range c.Prefixes {
+			key := c.Workspace + "\x00" + prefix
+			if _, ok := index[key]; !ok {
+				index[key] = len(clusters) - 1
+			}
+		}
+	}
+
+	// 同一组的对话都是最长对话的前缀；比保留副本更长的对话不删除，在剩下的对话中继续分组
+	var groups []Group
+	for _, members := range clusters {
+		for len(members) >= 2 {
+			kept := pick(members, keep)
+			group := Group{Keep: members[kept]}
+			var longer []Chat
+			for i, c := range members {
+				switch {
+				case i == kept:
+				case c.Messages > group.Keep.Messages:
+					longer = append(longer, c)
+				default:
+					group.Duplicates = append(group.Duplicates, Duplicate{Chat: c, Kind: kind(c, group.Keep)})
+				}
+			}
+			if len(group.Duplicates) > 0 {
+				groups = append(groups, group)
+			}
+			members = longer
+		}
+	}
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Reclaimable() > groups[j].Reclaimable()
+	})
+	return groups
+}
+
+// pick 按保留策略选出要保留的副本（members 已按消息数和时间降序）
+func pick(members []Chat, keep string) int {
+	if keep != KeepNewest {
+		return 0
+	}
+	best := 0
+	for i, c := range members {
+		if c.Updated.After(members[best].Updated) {
+			best = i
+		}
+	}
+	return best
+}
+
+// kind 返回重复副本与保留副本的关系，重复副本必然是保留副本的前缀
+func kind(c, kept Chat) string {
+	if c.Messages == kept.Messages {
+		return KindExact
+	}
+	return KindPrefix
+}
@@ -0,0 +1,125 @@
+package dedupe
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+func chat(workspace, name string, size int64, updated time.Time, contents ...string) Chat {
+	var messages []types.ChatMessage
+	for i, content := range contents {
+		role := "human"
+		if i%2 == 1 {
+			role = "bot"
+		}
+		messages = append(messages, types.ChatMessage{Role: role, Content: content})
+	}
+	return Chat{
+		Path:      filepath.Join(workspace, name),
+		Workspace: workspace,
+		Size:      size,
+		Updated:   updated,
+		Messages:  len(messages),
+		Prefixes:  Fingerprint(messages),
+	}
+}
+
+func TestFingerprintNormalisesWhitespace(t *testing.T) {
+	a := Fingerprint([]types.ChatMessage{{Role: "human", Content: "fix  the\r\nbug "}})
+	b := Fingerprint([]types.ChatMessage{{Role: "Human", Content: "fix the bug"}})
+	c := Fingerprint([]types.ChatMessage{{Role: "bot", Content: "fix the bug"}})
+	if a[0] != b[0] {
+		t.Error("空白和角色大小写不同的消息应视为相同")
+	}
+	if a[0] == c[0] {
+		t.Error("角色不同的消息不应视为相同")
+	}
+}
+
+func TestFindExactAndPrefix(t *testing.T) {
+	now := time.Now()
+	chats := []Chat{
+		chat("ws1", "full.chat", 300, now.Add(-2*time.Hour), "q", "a", "q2", "a2"),
+		chat("ws1", "retry.chat", 300, now.Add(-time.Hour), "q", "a", "q2", "a2"),
+		chat("ws1", "partial.chat", 100, now, "q", "a"),
+		chat("ws1", "other.chat", 100, now, "q", "different"),
+		chat("ws2", "copy.chat", 300, now, "q", "a", "q2", "a2"), // 其他工作区不比较
+		chat("ws1", "empty.chat", 10, now),
+	}
+
+	groups := Find(chats, KeepLongest)
+	if len(groups) != 1 {
+		t.Fatalf("应找到 1 组重复，实际 %d: %+v", len(groups), groups)
+	}
+	g := groups[0]
+	if filepath.Base(g.Keep.Path) != "retry.chat" {
+		t.Errorf("消息数相同时应保留最新的副本，实际保留 %s", g.Keep.Path)
+	}
+	kinds := map[string]string{}
+	for _, d := range g.Duplicates {
+		kinds[filepath.Base(d.Path)] = d.Kind
+	}
+	if len(kinds) != 2 || kinds["full.chat"] != KindExact || kinds["partial.chat"] != KindPrefix {
+		t.Errorf("重复副本不正确: %v", kinds)
+	}
+	if g.Reclaimable() != 400 {
+		t.Errorf("可释放空间应为 400，实际 %d", g.Reclaimable())
+	}
+
+	// 按最新保留时 partial.chat 保留，更长的副本不删除，只在它们之间去重
+	newest := Find(chats, KeepNewest)
+	if len(newest) != 1 {
+		t.Fatalf("按最新保留时应找到 1 组重复，实际 %d: %+v", len(newest), newest)
+	}
+	if filepath.Base(newest[0].Keep.Path) != "retry.chat" || len(newest[0].Duplicates) != 1 ||
+		filepath.Base(newest[0].Duplicates[0].Path) != "full.chat" {
+		t.Errorf("按最新保留时分组不正确: %+v", newest[0])
+	}
+}
+
+func TestFindNewestNeverDeletesLonger(t *testing.T) {
+	now := time.Now()
+	chats := []Chat{
+		chat("ws1", "long.chat", 300, now.Add(-time.Hour), "q", "a", "q2", "a2"),
+		chat("ws1", "short.chat", 100, now, "q", "a"),
+		chat("ws1", "old-short.chat", 100, now.Add(-2*time.Hour), "q", "a"),
+	}
+	groups := Find(chats, KeepNewest)
+	if len(groups) != 1 || filepath.Base(groups[0].Keep.Path) != "short.chat" {
+		t.Fatalf("应保留最新的 short.chat: %+v", groups)
+	}
+	for _, d := range groups[0].Duplicates {
+		if d.Messages > groups[0].Keep.Messages {
+			t.Errorf("消息更多的 %s 不应被删除", d.Path)
+		}
+	}
+	if len(groups[0].Duplicates) != 1 || groups[0].Duplicates[0].Kind != KindExact {
+		t.Errorf("只有 old-short.chat 应被删除: %+v", groups[0].Duplicates)
+	}
+}
+
+func TestReadChat(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.chat")
+	data, _ := json.Marshal(types.ChatFile{
+		ExecutionID: "abc",
+		Chat:        []types.ChatMessage{{Role: "human", Content: "hi"}, {Role: "bot", Content: "hello"}},
+		Metadata:    types.ChatMetadata{EndTime: 1700000000000},
+	})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := ReadChat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Workspace != dir || c.Messages != 2 || len(c.Prefixes) != 2 || c.Updated.UnixMilli() != 1700000000000 {
+		t.Errorf("ReadChat 结果不正确: %+v", c)
+	}
+}
@@ -0,0 +1,449 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+)
+
+// EntriesFileName 历史索引文件名
+const EntriesFileName = "entries.json"
+
+// Root 返回 Kiro 数据目录下的文件编辑历史目录
+func Root(kiroPath string) string {
+	return filepath.Join(kiroPath, "User", "History")
+}
+
+// Version 单个历史版本
+type Version struct {
+	ID        string    `json:"id"`        // 版本文件名
+	Path      string    `json:"path"`      // 版本文件路径
+	Size      int64     `json:"size"`      // 文件大小
+	Timestamp time.Time `json:"timestamp"` // 版本时间
+	Missing   bool      `json:"missing"`   // 索引中存在但版本文件缺失
+}
+
+// FileHistory 单个文件的编辑历史（User/History/<hash>/）
+type FileHistory struct {
+	Dir           string    `json:"dir"`            // 历史目录
+	Resource      string    `json:"resource"`       // 原始资源 URI
+	SourcePath    string    `json:"source_path"`    // 原始文件路径（从 URI 解析）
+	SourceExists  bool      `json:"source_exists"`  // 原始文件是否仍存在
+	Versions      []Version `json:"versions"`       // 历史版本（新的在前）
+	HasIndex      bool      `json:"has_index"`      // 是否存在 entries.json
+	IndexSize     int64     `json:"index_size"`     // entries.json 大小
+	TotalSize     int64     `json:"total_size"`     // 目录总大小
+	LatestVersion time.Time `json:"latest_version"` // 最新版本时间
+}
+
+// Policy 历史清理策略，多条规则之间为"或"关系：任一规则命中即删除该版本
+type Policy struct {
+	KeepLast    int           `json:"keep_last"`    // 每个文件保留最近 N 个版本（0=不限制）
+	MaxAge      time.Duration `json:"max_age"`      // 删除早于该时长的版本（0=不限制）
+	DropMissing bool          `json:"drop_missing"` // 删除原始文件已不存在的历史
+	DropAll     bool          `json:"drop_all"`     // 删除全部历史
+}
+
+// IsEmpty 策略是否未设置任何规则
+func (p Policy) IsEmpty() bool {
+	return p.KeepLast <= 0 && p.MaxAge <= 0 && !p.DropMissing && !p.DropAll
+}
+
+// PruneAction 单个历史目录的清理计划
+type PruneAction struct {
+	History   FileHistory       `json:"history"`
+	Drop      []Version         `json:"drop"`       // 将删除的版本
+	Reasons   map[string]string `json:"reasons"`    // 版本ID -> 删除原因
+	RemoveDir bool              `json:"remove_dir"` // 是否删除整个目录
+	Dangling  int               `json:"dangling"`   // entries.json 中版本文件已缺失、将移除的条目数
+	Bytes     int64             `json:"bytes"`      // 将释放的字节数
+}
+
+// PruneResult 清理结果
+type PruneResult struct {
+	VersionsRemoved int      `json:"versions_removed"` // 删除的版本数
+	DirsRemoved     int      `json:"dirs_removed"`     // 删除的目录数
+	IndexesUpdated  int      `json:"indexes_updated"`  // 改写的 entries.json 数
+	FreedBytes      int64    `json:"freed_bytes"`      // 释放的字节数
+	IndexBackups    []string `json:"index_backups"`    // entries.json 备份路径
+	Errors          []error  `json:"-"`                // 错误列表
+}
+
+// Pruner 文件编辑历史清理器
+type Pruner struct {
+	root      string
+	backupMgr *backup.BackupManager
+	now       func() time.Time
+}
+
+// NewPruner 创建历史清理器，root 为 User/History 目录
+func NewPruner(root string) *Pruner {
+	return &Pruner{
+		root: root,
+		now:  time.Now,
+	}
+}
+
+// SetBackupManager 设置用于备份 entries.json 的备份管理器
+func (p *Pruner) SetBackupManager(bm *backup.BackupManager) {
+	p.backupMgr = bm
+}
+
+// Contains 判断路径是否位于历史目录内
+func (p *Pruner) Contains(path string) bool {
+	return strings.HasPrefix(path, p.root+string(filepath.Separator))
+}
+
+// Scan 扫描所有文件历史
+func (p *Pruner) Scan() ([]FileHistory, error) {
+	entries, err := os.ReadDir(p.root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取历史目录失败: %v", err)
+	}
+
+	var histories []FileHistory
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		h, err := scanDir(filepath.Join(p.root, entry.Name()))
+		if err != nil {
+			// 跳过无法解析的目录
+			continue
+		}
+		histories = append(histories, *h)
+	}
+	return histories, nil
+}
+
+// entriesFile entries.json 结构，保留未知字段以便原样写回
+type entriesFile struct {
+	raw     map[string]json.RawMessage
+	entries []map[string]json.RawMessage
+}
+
+// readEntries 读取 entries.json
+func readEntries(path string) (*entriesFile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	ef := &entriesFile{}
+	if err := json.Unmarshal(data, &ef.raw); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", path, err)
+	}
+	if raw, ok := ef.raw["entries"]; ok {
+		if err := json.Unmarshal(raw, &ef.entries); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %v", path, err)
+		}
+	}
+	return ef, nil
+}
+
+// marshal 序列化 entries.json
+func (ef *entriesFile) marshal(entries []map[string]json.RawMessage) ([]byte, error) {
+	if entries == nil {
+		entries = []map[string]json.RawMessage{}
+	}
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	ef.raw["entries"] = raw
+	return json.Marshal(ef.raw)
+}
+
+// rawString 读取字符串字段
+func rawString(m map[string]json.RawMessage, key string) string {
+	var s string
+	if raw, ok := m[key]; ok {
+		json.Unmarshal(raw, &s)
+	}
+	return s
+}
+
+// rawInt 读取整数字段
+func rawInt(m map[string]json.RawMessage, key string) int64 {
+	var n int64
+	if raw, ok := m[key]; ok {
+		json.Unmarshal(raw, &n)
+	}
+	return n
+}
+
+// scanDir 扫描单个历史目录
+func scanDir(dir string) (*FileHistory, error) {
+	h := &FileHistory{Dir: dir}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	sizes := make(map[string]int64)
+	modTimes := make(map[string]time.Time)
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		sizes[f.Name()] = info.Size()
+		modTimes[f.Name()] = info.ModTime()
+		h.TotalSize += info.Size()
+	}
+
+	indexPath := filepath.Join(dir, EntriesFileName)
+	ef, err := readEntries(indexPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	if ef != nil {
+		h.HasIndex = true
+		h.IndexSize = sizes[EntriesFileName]
+		h.Resource = rawString(ef.raw, "resource")
+		h.SourcePath = ResourcePath(h.Resource)
+		if h.SourcePath != "" {
+			_, statErr := os.Stat(h.SourcePath)
+			h.SourceExists = statErr == nil
+		}
+
+		for _, e := range ef.entries {
+			id := rawString(e, "id")
+			if id == "" || filepath.Base(id) != id {
+				continue
+			}
+			v := Version{
+				ID:   id,
+				Path: filepath.Join(dir, id),
+			}
+			if ts := rawInt(e, "timestamp"); ts > 0 {
+				v.Timestamp = time.UnixMilli(ts)
+			}
+			if size, ok := sizes[id]; ok {
+				v.Size = size
+				if v.Timestamp.IsZero() {
+					v.Timestamp = modTimes[id]
+				}
+			} else {
+				v.Missing = true
+			}
+			h.Versions = append(h.Versions, v)
+		}
+	} else {
+		// 没有索引的目录：所有文件都视为版本
+		for name, size := range sizes {
+			h.Versions = append(h.Versions, Version{
+				ID:        name,
+				Path:      filepath.Join(dir, name),
+				Size:      size,
+				Timestamp: modTimes[name],
+			})
+		}
+	}
+
+	sort.Slice(h.Versions, func(i, j int) bool {
+		return h.Versions[i].Timestamp.After(h.Versions[j].Timestamp)
+	})
+	if len(h.Versions) > 0 {
+		h.LatestVersion = h.Versions[0].Timestamp
+	}
+
+	return h, nil
+}
+
+// ResourcePath 将 entries.json 中的资源 URI 转换为本地路径，非 file 协议返回空
+func ResourcePath(resource string) string {
+	if resource == "" {
+		return ""
+	}
+	u, err := url.Parse(resource)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	path := u.Path
+	// Windows: file:///c%3A/Users/... -> c:/Users/...
+	if runtime.GOOS == "windows" && len(path) > 2 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// Plan 根据策略生成清理计划
+func (p *Pruner) Plan(policy Policy) ([]PruneAction, error) {
+	if policy.IsEmpty() {
+		return nil, nil
+	}
+
+	histories, err := p.Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	now := p.now()
+	var actions []PruneAction
+	for _, h := range histories {
+		action := PruneAction{
+			History: h,
+			Reasons: make(map[string]string),
+		}
+
+		// 只有版本文件存在的版本计入保留数量，缺失的条目在改写索引时一并移除
+		existing := 0
+		for _, v := range h.Versions {
+			if v.Missing {
+				continue
+			}
+			rank := existing
+			existing++
+			reason := ""
+			switch {
+			case policy.DropAll:
+				reason = "all history"
+			case policy.DropMissing && h.SourcePath != "" && !h.SourceExists:
+				reason = "source file deleted"
+			case policy.KeepLast > 0 && rank >= policy.KeepLast:
+				reason = fmt.Sprintf("beyond last %d versions", policy.KeepLast)
+			case policy.MaxAge > 0 && !v.Timestamp.IsZero() && now.Sub(v.Timestamp) > policy.MaxAge:
+				reason = fmt.Sprintf("older than %d days", int(policy.MaxAge.Hours()/24))
+			}
+			if reason == "" {
+				continue
+			}
+			action.Drop = append(action.Drop, v)
+			action.Reasons[v.ID] = reason
+			action.Bytes += v.Size
+		}
+
+		// 已经没有任何版本文件的目录直接移除
+		if existing == 0 {
+			action.RemoveDir = true
+			action.Bytes = h.TotalSize
+			actions = append(actions, action)
+			continue
+		}
+
+		// 没有要删除的版本时，索引中有缺失的条目也需要改写
+		if h.HasIndex {
+			action.Dangling = len(h.Versions) - existing
+		}
+		if len(action.Drop) == 0 && action.Dangling == 0 {
+			continue
+		}
+
+		// 所有版本都被删除时移除整个目录
+		if len(action.Drop) == existing {
+			action.RemoveDir = true
+			action.Bytes = h.TotalSize
+		}
+		actions = append(actions, action)
+	}
+
+	return actions, nil
+}
+
+// Apply 执行清理计划
+// 部分删除时先备份并原子改写 entries.json，再删除版本文件，保证索引不会引用不存在的文件
+func (p *Pruner) Apply(actions []PruneAction) *PruneResult {
+	result := &PruneResult{}
+	rootGuard := safety.NewGuard(p.root)
+
+	for _, action := range actions {
+		if action.RemoveDir {
+			if err := rootGuard.RemoveAll(action.History.Dir); err != nil {
+				result.Errors = append(result.Errors, fmt.Errorf("删除历史目录 %s 失败: %v", action.History.Dir, err))
+				continue
+			}
+			result.DirsRemoved++
+			result.VersionsRemoved += len(action.Drop)
+			result.FreedBytes += action.Bytes
+			continue
+		}
+
+		if action.History.HasIndex {
+			backupPath, err := p.rewriteEntries(action)
+			if err != nil {
+				result.Errors = append(result.Errors, err)
+				continue
+			}
+			result.IndexesUpdated++
+			if backupPath != "" {
+				result.IndexBackups = append(result.IndexBackups, backupPath)
+			}
+		}
+
+		// 版本文件名来自 entries.json，只允许删除该历史目录内的文件
+		dirGuard := safety.NewGuard(action.History.Dir)
+		for _, v := range action.Drop {
+			if v.Missing {
+				continue
+			}
+			if err := dirGuard.Remove(v.Path); err != nil && !os.IsNotExist(err) {
+				result.Errors = append(result.Errors, fmt.Errorf("删除历史版本 %s 失败: %v", v.Path, err))
+				continue
+			}
+			result.VersionsRemoved++
+			result.FreedBytes += v.Size
+		}
+	}
+
+	return result
+}
+
+// rewriteEntries 从 entries.json 中移除待删除版本和版本文件已缺失的条目，返回备份路径
+func (p *Pruner) rewriteEntries(action PruneAction) (string, error) {
+	indexPath := filepath.Join(action.History.Dir, EntriesFileName)
+	ef, err := readEntries(indexPath)
+	if err != nil {
+		return "", fmt.Errorf("读取 %s 失败: %v", indexPath, err)
+	}
+
+	drop := make(map[string]bool)
+	for _, v := range action.Drop {
+		drop[v.ID] = true
+	}
+	for _, v := range action.History.Versions {
+		if v.Missing {
+			drop[v.ID] = true
+		}
+	}
+	var kept []map[string]json.RawMessage
+	for _, e := range ef.entries {
+		if drop[rawString(e, "id")] {
+			continue
+		}
+		kept = append(kept, e)
+	}
+
+	var backupPath string
+	if p.backupMgr != nil {
+		backupPath, err = p.backupMgr.BackupFile(indexPath)
+		if err != nil {
+			return "", fmt.Errorf("备份 %s 失败: %v", indexPath, err)
+		}
+	}
+
+	data, err := ef.marshal(kept)
+	if err != nil {
+		return "", err
+	}
+	if err := utils.WriteFileAtomic(indexPath, data, 0644); err != nil {
+		return "", fmt.Errorf("改写 %s 失败: %v", indexPath, err)
+	}
+	return backupPath, nil
+}
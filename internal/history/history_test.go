@@ -0,0 +1,220 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// createHistoryDir 创建模拟的历史目录，versions 为版本距今的天数
+func createHistoryDir(t *testing.T, root, hash, source string, versionAges []int) string {
+	t.Helper()
+	dir := filepath.Join(root, hash)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("创建历史目录失败: %v", err)
+	}
+
+	var entries []map[string]interface{}
+	for i, days := range versionAges {
+		id := fmt.Sprintf("v%d.txt", i)
+		if err := os.WriteFile(filepath.Join(dir, id), []byte("version content"), 0644); err != nil {
+			t.Fatalf("写入版本失败: %v", err)
+		}
+		entries = append(entries, map[string]interface{}{
+			"id":        id,
+			"source":    "undoRedo.source",
+			"timestamp": time.Now().AddDate(0, 0, -days).UnixMilli(),
+		})
+	}
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"version":  1,
+		"resource": "file://" + filepath.ToSlash(source),
+		"entries":  entries,
+	})
+	if err := os.WriteFile(filepath.Join(dir, EntriesFileName), data, 0644); err != nil {
+		t.Fatalf("写入 entries.json 失败: %v", err)
+	}
+	return dir
+}
+
+// readEntryIDs 读取 entries.json 中的版本ID
+func readEntryIDs(t *testing.T, dir string) []string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, EntriesFileName))
+	if err != nil {
+		t.Fatalf("读取 entries.json 失败: %v", err)
+	}
+	var parsed struct {
+		Version int `json:"version"`
+		Entries []struct {
+			ID string `json:"id"`
+		} `json:"entries"`
+	}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatalf("解析 entries.json 失败: %v", err)
+	}
+	if parsed.Version != 1 {
+		t.Errorf("改写后应保留 version 字段")
+	}
+	var ids []string
+	for _, e := range parsed.Entries {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+func TestPruner_KeepLast(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(t.TempDir(), "main.go")
+	os.WriteFile(source, []byte("package main"), 0644)
+	dir := createHistoryDir(t, root, "abc", source, []int{1, 2, 3, 4})
+
+	pruner := NewPruner(root)
+	actions, err := pruner.Plan(Policy{KeepLast: 2})
+	if err != nil {
+		t.Fatalf("生成计划失败: %v", err)
+	}
+	if len(actions) != 1 || len(actions[0].Drop) != 2 || actions[0].RemoveDir {
+		t.Fatalf("应删除 2 个版本且保留目录: %+v", actions)
+	}
+
+	result := pruner.Apply(actions)
+	if len(result.Errors) > 0 {
+		t.Fatalf("清理出错: %v", result.Errors)
+	}
+
+	ids := readEntryIDs(t, dir)
+	if len(ids) != 2 || ids[0] != "v0.txt" || ids[1] != "v1.txt" {
+		t.Errorf("应保留最新的两个版本，实际 %v", ids)
+	}
+	for _, id := range ids {
+		if _, err := os.Stat(filepath.Join(dir, id)); err != nil {
+			t.Errorf("entries.json 引用的版本文件不存在: %s", id)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "v3.txt")); !os.IsNotExist(err) {
+		t.Error("v3.txt 应该被删除")
+	}
+}
+
+func TestPruner_KeepLastIgnoresMissingVersions(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(t.TempDir(), "main.go")
+	os.WriteFile(source, []byte("package main"), 0644)
+	dir := createHistoryDir(t, root, "abc", source, []int{1, 2, 3, 4})
+	// 最新的版本文件已缺失，不应占用保留名额
+	os.Remove(filepath.Join(dir, "v0.txt"))
+
+	pruner := NewPruner(root)
+	actions, err := pruner.Plan(Policy{KeepLast: 2})
+	if err != nil {
+		t.Fatalf("生成计划失败: %v", err)
+	}
+	if len(actions) != 1 || len(actions[0].Drop) != 1 || actions[0].Drop[0].ID != "v3.txt" {
+		t.Fatalf("只应删除 v3.txt: %+v", actions)
+	}
+	pruner.Apply(actions)
+
+	ids := readEntryIDs(t, dir)
+	if len(ids) != 2 || ids[0] != "v1.txt" || ids[1] != "v2.txt" {
+		t.Errorf("应保留两个存在的版本并移除缺失的条目，实际 %v", ids)
+	}
+}
+
+func TestPruner_DanglingEntriesRewriteIndex(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(t.TempDir(), "main.go")
+	os.WriteFile(source, []byte("package main"), 0644)
+	dir := createHistoryDir(t, root, "abc", source, []int{1, 2, 3})
+	// 没有版本超出保留数量，但索引引用了已缺失的文件
+	os.Remove(filepath.Join(dir, "v1.txt"))
+
+	pruner := NewPruner(root)
+	actions, err := pruner.Plan(Policy{KeepLast: 5})
+	if err != nil {
+		t.Fatalf("生成计划失败: %v", err)
+	}
+	if len(actions) != 1 || len(actions[0].Drop) != 0 || actions[0].Dangling != 1 || actions[0].RemoveDir {
+		t.Fatalf("应只改写索引: %+v", actions)
+	}
+
+	result := pruner.Apply(actions)
+	if len(result.Errors) > 0 || result.IndexesUpdated != 1 || result.VersionsRemoved != 0 {
+		t.Fatalf("清理结果不正确: %+v", result)
+	}
+	ids := readEntryIDs(t, dir)
+	if len(ids) != 2 || ids[0] != "v0.txt" || ids[1] != "v2.txt" {
+		t.Errorf("应移除缺失的条目，实际 %v", ids)
+	}
+}
+
+func TestPruner_MaxAge(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(t.TempDir(), "main.go")
+	os.WriteFile(source, []byte("package main"), 0644)
+	dir := createHistoryDir(t, root, "abc", source, []int{1, 10, 40, 60})
+
+	pruner := NewPruner(root)
+	actions, _ := pruner.Plan(Policy{MaxAge: 30 * 24 * time.Hour})
+	pruner.Apply(actions)
+
+	ids := readEntryIDs(t, dir)
+	if len(ids) != 2 {
+		t.Errorf("应保留 30 天内的 2 个版本，实际 %v", ids)
+	}
+}
+
+func TestPruner_DropMissingRemovesDirectory(t *testing.T) {
+	root := t.TempDir()
+	missing := filepath.Join(t.TempDir(), "deleted.go")
+	dir := createHistoryDir(t, root, "gone", missing, []int{1, 2})
+
+	existing := filepath.Join(t.TempDir(), "kept.go")
+	os.WriteFile(existing, []byte("package kept"), 0644)
+	keptDir := createHistoryDir(t, root, "kept", existing, []int{1})
+
+	pruner := NewPruner(root)
+	actions, _ := pruner.Plan(Policy{DropMissing: true})
+	if len(actions) != 1 || !actions[0].RemoveDir {
+		t.Fatalf("应只删除源文件已不存在的历史目录: %+v", actions)
+	}
+
+	result := pruner.Apply(actions)
+	if result.DirsRemoved != 1 {
+		t.Errorf("应删除 1 个目录，实际 %d", result.DirsRemoved)
+	}
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("源文件已删除的历史目录应该被移除")
+	}
+	if _, err := os.Stat(keptDir); err != nil {
+		t.Error("源文件仍存在的历史目录应该保留")
+	}
+}
+
+func TestPruner_EmptyDirectoryRemoved(t *testing.T) {
+	root := t.TempDir()
+	source := filepath.Join(t.TempDir(), "main.go")
+	os.WriteFile(source, []byte("package main"), 0644)
+	dir := createHistoryDir(t, root, "empty", source, nil)
+
+	pruner := NewPruner(root)
+	actions, _ := pruner.Plan(Policy{KeepLast: 5})
+	pruner.Apply(actions)
+
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Error("没有任何版本的历史目录应该被移除")
+	}
+}
+
+func TestPolicy_IsEmpty(t *testing.T) {
+	if !(Policy{}).IsEmpty() {
+		t.Error("零值策略应为空")
+	}
+	if (Policy{KeepLast: 1}).IsEmpty() {
+		t.Error("设置 KeepLast 后策略不应为空")
+	}
+}
@@ -0,0 +1,270 @@
+package orphans
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/sessions"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// ExecutionStateFile 工作区目录中的执行状态文件名（固定名称）
+const ExecutionStateFile = "f62de366d0006e17ea00a01f6624aabf"
+
+// DiffsDirName 保存差异内容的目录名
+const DiffsDirName = ".diffs"
+
+// DefaultGracePeriod 最近修改的产物可能属于正在进行的执行，不视为孤立
+const DefaultGracePeriod = 24 * time.Hour
+
+// 产物类型
+const (
+	KindSnapshot = "snapshot" // 工作区下的 <hash>/ 代码快照目录
+	KindDiff     = "diff"     // .diffs 下的差异内容
+)
+
+// skipDirs kiro.kiroagent 下不是工作区的目录
+var skipDirs = map[string]bool{
+	"index":                  true,
+	"dev_data":               true,
+	sessions.SessionsDirName: true,
+	".migrations":            true,
+	DiffsDirName:             true,
+	".utils":                 true,
+	"default":                true,
+}
+
+// Artifact 一个快照目录或差异内容
+type Artifact struct {
+	Path       string    `json:"path"`
+	Kind       string    `json:"kind"`
+	Name       string    `json:"name"` // 用于匹配引用的名称（目录名或去掉扩展名的文件名）
+	Size       int64     `json:"size"`
+	Files      int       `json:"files"`
+	ModTime    time.Time `json:"mod_time"`   // 其中最新文件的修改时间
+	Referenced bool      `json:"referenced"` // 被对话、会话或执行状态引用
+	Recent     bool      `json:"recent"`     // 在宽限期内修改过
+}
+
+// Orphaned 判断产物是否孤立（没有被引用且不在宽限期内），孤立产物可以安全删除
+func (a Artifact) Orphaned() bool {
+	return !a.Referenced && !a.Recent
+}
+
+// Record 返回产物对应的记录
+func (a Artifact) Record() types.DBRecord {
+	return types.DBRecord{
+		TableName:  a.Kind,
+		UpdatedAt:  a.ModTime,
+		DataSize:   a.Size,
+		IsOrphaned: a.Orphaned(),
+	}
+}
+
+// Options 分析选项
+type Options struct {
+	GracePeriod time.Duration // 0 表示使用 DefaultGracePeriod
+	Now         time.Time
+}
+
+// Report 引用分析结果
+type Report struct {
+	AgentPath string     `json:"agent_path"`
+	Sources   int        `json:"sources"` // 读取的引用来源文件数
+	Artifacts []Artifact `json:"artifacts"`
+
+	index map[string]int // 产物路径 -> Artifacts 中的下标，Find 时建立
+}
+
+// Orphans 返回孤立的产物，按大小降序
+func (r *Report) Orphans() []Artifact {
+	var list []Artifact
+	for _, a := range r.Artifacts {
+		if a.Orphaned() {
+			list = append(list, a)
+		}
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Size > list[j].Size })
+	return list
+}
+
+// OrphanSize 返回孤立产物的总大小
+func (r *Report) OrphanSize() int64 {
+	var total int64
+	for _, a := range r.Orphans() {
+		total += a.Size
+	}
+	return total
+}
+
+// Find 返回包含该路径的产物：按路径本身和各级上级目录查表，扫描大量文件时不必逐个比较产物
+func (r *Report) Find(path string) (Artifact, bool) {
+	if len(r.index) != len(r.Artifacts) {
+		r.index = make(map[string]int, len(r.Artifacts))
+		for i, a := range r.Artifacts {
+			r.index[filepath.Clean(a.Path)] = i
+		}
+	}
+	for dir := filepath.Clean(path); ; {
+		if i, ok := r.index[dir]; ok {
+			return r.Artifacts[i], true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return Artifact{}, false
+		}
+		dir = parent
+	}
+}
+
+// snapshotName 快照目录的名称：十六进制哈希或执行 ID（UUID）
+// 工作区目录下其他名称的目录可能是 Kiro 的其他数据，不当作快照，也就不会被当作孤立内容删除
+var snapshotName = regexp.MustCompile(`^(?:[0-9a-f]{16,64}|[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})$`)
+
+// Analyze 收集 kiro.kiroagent 下的快照和差异产物，并根据对话、会话和执行状态文件
+// 中出现的标识判断它们是否仍被引用。只要名称出现在任一来源中就视为被引用
+func Analyze(agentPath string, opts Options) (*Report, error) {
+	if opts.GracePeriod <= 0 {
+		opts.GracePeriod = DefaultGracePeriod
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	entries, err := os.ReadDir(agentPath)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{AgentPath: agentPath}
+	var sources []string
+	report.Artifacts = append(report.Artifacts, diffArtifacts(filepath.Join(agentPath, DiffsDirName))...)
+	for _, entry := range entries {
+		if !entry.IsDir() || skipDirs[entry.Name()] {
+			continue
+		}
+		dir := filepath.Join(agentPath, entry.Name())
+		children, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, child := range children {
+			path := filepath.Join(dir, child.Name())
+			switch {
+			case child.Name() == DiffsDirName && child.IsDir():
+				report.Artifacts = append(report.Artifacts, diffArtifacts(path)...)
+			case child.IsDir() && snapshotName.MatchString(child.Name()):
+				report.Artifacts = append(report.Artifacts, measure(path, KindSnapshot, child.Name()))
+			case strings.HasSuffix(child.Name(), ".chat") || child.Name() == ExecutionStateFile:
+				sources = append(sources, path)
+			}
+		}
+	}
+	// 没有产物时不必读取引用来源
+	if len(report.Artifacts) == 0 {
+		return report, nil
+	}
+	filepath.Walk(filepath.Join(agentPath, sessions.SessionsDirName), func(path string, info os.FileInfo, err error) error {
+		if err == nil && info.Mode().IsRegular() && strings.HasSuffix(info.Name(), ".json") {
+			sources = append(sources, path)
+		}
+		return nil
+	})
+
+	names := make(map[string]bool, len(report.Artifacts))
+	for _, a := range report.Artifacts {
+		names[a.Name] = false
+	}
+	for _, path := range sources {
+		markReferences(filepath.Base(path), names)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			// 引用不完整时无法确定哪些产物孤立
+			return nil, fmt.Errorf("读取引用来源失败: %v", err)
+		}
+		report.Sources++
+		markReferences(string(data), names)
+	}
+
+	cutoff := opts.Now.Add(-opts.GracePeriod)
+	for i := range report.Artifacts {
+		a := &report.Artifacts[i]
+		a.Referenced = names[a.Name]
+		a.Recent = a.ModTime.After(cutoff)
+	}
+	return report, nil
+}
+
+// diffArtifacts 返回 .diffs 目录下的每一项
+func diffArtifacts(dir string) []Artifact {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var list []Artifact
+	for _, entry := range entries {
+		name := entry.Name()
+		if !entry.IsDir() {
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		list = append(list, measure(filepath.Join(dir, entry.Name()), KindDiff, name))
+	}
+	return list
+}
+
+// measure 统计产物的大小、文件数和其中文件的最新修改时间，不跟随符号链接
+func measure(path, kind, name string) Artifact {
+	a := Artifact{Path: path, Kind: kind, Name: name}
+	filepath.Walk(path, func(p string, info os.FileInfo, err error) error {
+		if err != nil || info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if p == path && a.ModTime.IsZero() {
+			a.ModTime = info.ModTime() // 空目录使用目录本身的修改时间
+		}
+		if info.IsDir() {
+			return nil
+		}
+		if a.Files == 0 || info.ModTime().After(a.ModTime) {
+			a.ModTime = info.ModTime()
+		}
+		a.Size += info.Size()
+		a.Files++
+		return nil
+	})
+	return a
+}
+
+// markReferences 把文本中出现的产物名称标记为已引用
+// 文本按标识符字符切分，带分隔符的标识（如 UUID、带扩展名的文件名）同时按整体和各段匹配
+func markReferences(text string, names map[string]bool) {
+	mark := func(token string) {
+		if _, ok := names[token]; ok {
+			names[token] = true
+		}
+	}
+	text = jsonEscapes.Replace(text)
+	for _, token := range strings.FieldsFunc(text, func(r rune) bool { return !isIdent(r) }) {
+		mark(token)
+		if strings.ContainsAny(token, ".-_") {
+			for _, part := range strings.FieldsFunc(token, isSeparator) {
+				mark(part)
+			}
+		}
+	}
+}
+
+// jsonEscapes JSON 字符串中的转义序列，避免 "\n<hash>" 被当作一个标识
+var jsonEscapes = strings.NewReplacer(`\n`, " ", `\r`, " ", `\t`, " ", `\"`, " ", `\/`, "/", `\\`, " ")
+
+func isSeparator(r rune) bool {
+	return r == '.' || r == '-' || r == '_'
+}
+
+func isIdent(r rune) bool {
+	return isSeparator(r) || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z'
+}
@@ -0,0 +1,67 @@
+package orphans
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string, mod time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mod, mod); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAnalyzeReferenceGraph(t *testing.T) {
+	agent := t.TempDir()
+	old := time.Now().AddDate(0, 0, -10)
+	ws := filepath.Join(agent, "0123abcd")
+
+	writeFile(t, filepath.Join(ws, "c1.chat"), `{"chat":[{"role":"tool","content":"restored\n5a1f0c2e9b7d4e31/main.go"}]}`, old)
+	writeFile(t, filepath.Join(ws, ExecutionStateFile), `{"executions":[{"executionId":"2222aaaa-bbbb-4ccc-8ddd-eeeeffff0000"}]}`, old)
+	writeFile(t, filepath.Join(agent, "workspace-sessions", "L3dvcmsK", "s1.json"), `{"diff":"d3333.patch"}`, old)
+
+	writeFile(t, filepath.Join(ws, "5a1f0c2e9b7d4e31", "main.go"), "package main", old)       // 对话引用
+	writeFile(t, filepath.Join(ws, "2222aaaa-bbbb-4ccc-8ddd-eeeeffff0000", "a.go"), "a", old) // 执行状态引用
+	writeFile(t, filepath.Join(ws, "9c0e7d1a3b5f8e42", "a.go"), "orphan", old)                // 孤立
+	writeFile(t, filepath.Join(ws, "8b2d4f6a1c3e5d70", "a.go"), "recent", time.Now())         // 宽限期内
+	writeFile(t, filepath.Join(agent, ".diffs", "d3333.patch"), "x", old)                     // 会话引用
+	writeFile(t, filepath.Join(agent, ".diffs", "d4444.patch"), "xyz", old)                   // 孤立
+	writeFile(t, filepath.Join(agent, "index", "big", "x"), "index", old)                     // 不是工作区
+	writeFile(t, filepath.Join(ws, "tool-cache", "x"), "unknown", old)                        // 不是快照
+
+	report, err := Analyze(agent, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Artifacts) != 6 || report.Sources != 3 {
+		t.Fatalf("应找到 6 个产物和 3 个引用来源: %+v", report)
+	}
+
+	orphans := report.Orphans()
+	if len(orphans) != 2 || orphans[0].Name != "9c0e7d1a3b5f8e42" || orphans[1].Name != "d4444" {
+		t.Fatalf("孤立产物不正确: %+v", orphans)
+	}
+	if orphans[0].Kind != KindSnapshot || orphans[1].Kind != KindDiff || report.OrphanSize() != 9 {
+		t.Errorf("孤立产物类型或大小不正确: %+v", orphans)
+	}
+	if !orphans[0].Record().IsOrphaned {
+		t.Error("孤立产物的记录应标记为孤立")
+	}
+
+	a, ok := report.Find(filepath.Join(ws, "8b2d4f6a1c3e5d70", "a.go"))
+	if !ok || a.Orphaned() || !a.Recent {
+		t.Errorf("宽限期内的产物不应视为孤立: %+v", a)
+	}
+	if _, ok := report.Find(filepath.Join(ws, "c1.chat")); ok {
+		t.Error("对话文件不是产物")
+	}
+}
@@ -0,0 +1,259 @@
+package pins
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/tokens"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// Chat 一个 .chat 文件的标识信息
+type Chat struct {
+	Path        string    `json:"path"`
+	ExecutionID string    `json:"execution_id"`
+	Title       string    `json:"title"` // 第一条用户消息
+	Size        int64     `json:"size"`
+	Tokens      int       `json:"tokens"` // 估算的 token 数
+	ModTime     time.Time `json:"mod_time"`
+}
+
+// ShortID 返回用于显示和匹配的短ID（executionId 前8位，缺失时用文件名）
+func (c Chat) ShortID() string {
+	id := c.ExecutionID
+	if id == "" {
+		id = strings.TrimSuffix(filepath.Base(c.Path), ".chat")
+	}
+	if len(id) > 8 {
+		id = id[:8]
+	}
+	return id
+}
+
+// ReadChat 读取对话文件的 executionId、标题和估算的 token 数
+func ReadChat(path string) (Chat, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return Chat{}, fmt.Errorf("获取文件信息失败: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Chat{}, fmt.Errorf("读取文件失败: %v", err)
+	}
+	var chatFile types.ChatFile
+	if err := json.Unmarshal(data, &chatFile); err != nil {
+		return Chat{}, fmt.Errorf("解析 JSON 失败: %v", err)
+	}
+
+	chat := Chat{
+		Path:        path,
+		ExecutionID: chatFile.ExecutionID,
+		Size:        info.Size(),
+		Tokens:      tokens.CountMessages(tokens.Default(), chatFile.Chat).Total,
+		ModTime:     info.ModTime(),
+	}
+	for _, msg := range chatFile.Chat {
+		if msg.Role == "human" && strings.TrimSpace(msg.Content) != "" {
+			chat.Title = strings.Join(strings.Fields(msg.Content), " ")
+			break
+		}
+	}
+	return chat, nil
+}
+
+// Pin 一个被固定的对话，以 executionId 和路径共同标识
+type Pin struct {
+	ExecutionID string    `json:"execution_id,omitempty"`
+	Path        string    `json:"path"`
+	Title       string    `json:"title,omitempty"`
+	PinnedAt    time.Time `json:"pinned_at"`
+}
+
+// Matches 判断对话是否对应这个固定项（executionId 相同或路径相同）
+func (p Pin) Matches(c Chat) bool {
+	if p.ExecutionID != "" && p.ExecutionID == c.ExecutionID {
+		return true
+	}
+	return p.Path == c.Path
+}
+
+// Registry 本地的固定对话列表
+type Registry struct {
+	path string
+	pins []Pin
+}
+
+// Load 读取固定列表，文件不存在时返回空列表
+func Load(path string) (*Registry, error) {
+	r := &Registry{path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return r, nil
+		}
+		return r, fmt.Errorf("读取固定列表失败: %v", err)
+	}
+	if err := json.Unmarshal(data, &r.pins); err != nil {
+		return r, fmt.Errorf("解析固定列表失败: %v", err)
+	}
+	return r, nil
+}
+
+// Save 写入固定列表（先写临时文件再重命名）
+func (r *Registry) Save() error {
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	data, err := json.MarshalIndent(r.pins, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("写入固定列表失败: %v", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("写入固定列表失败: %v", err)
+	}
+	return nil
+}
+
+// Pins 返回所有固定项，新固定的在前
+func (r *Registry) Pins() []Pin {
+	pins := append([]Pin(nil), r.pins...)
+	sort.SliceStable(pins, func(i, j int) bool { return pins[i].PinnedAt.After(pins[j].PinnedAt) })
+	return pins
+}
+
+// Len 返回固定项数量
+func (r *Registry) Len() int {
+	return len(r.pins)
+}
+
+// Add 固定对话，已固定时返回 false
+func (r *Registry) Add(c Chat, now time.Time) bool {
+	if r.IsPinned(c) {
+		return false
+	}
+	r.pins = append(r.pins, Pin{ExecutionID: c.ExecutionID, Path: c.Path, Title: c.Title, PinnedAt: now})
+	return true
+}
+
+// Remove 取消固定对话，未固定时返回 false
+func (r *Registry) Remove(c Chat) bool {
+	kept := r.pins[:0]
+	removed := false
+	for _, p := range r.pins {
+		if p.Matches(c) {
+			removed = true
+			continue
+		}
+		kept = append(kept, p)
+	}
+	r.pins = kept
+	return removed
+}
+
+// IsPinned 判断对话是否被固定
+func (r *Registry) IsPinned(c Chat) bool {
+	for _, p := range r.pins {
+		if p.Matches(c) {
+			return true
+		}
+	}
+	return false
+}
+
+// Contains 按路径判断文件是否被固定（调用前应先 Follow 已移动的文件）
+func (r *Registry) Contains(path string) bool {
+	if r == nil {
+		return false
+	}
+	path = filepath.Clean(path)
+	for _, p := range r.pins {
+		if filepath.Clean(p.Path) == path {
+			return true
+		}
+	}
+	return false
+}
+
+// Missing 返回文件已不在原路径的固定项
+func (r *Registry) Missing() []Pin {
+	var missing []Pin
+	for _, p := range r.pins {
+		if _, err := os.Stat(p.Path); os.IsNotExist(err) {
+			missing = append(missing, p)
+		}
+	}
+	return missing
+}
+
+// Follow 按 executionId 更新已被 Kiro 移动的对话路径，返回更新的数量
+func (r *Registry) Follow(chats []Chat) int {
+	byID := make(map[string]string)
+	for _, c := range chats {
+		if c.ExecutionID != "" {
+			byID[c.ExecutionID] = c.Path
+		}
+	}
+	moved := 0
+	for i, p := range r.pins {
+		if p.ExecutionID == "" {
+			continue
+		}
+		if _, err := os.Stat(p.Path); !os.IsNotExist(err) {
+			continue
+		}
+		if path, ok := byID[p.ExecutionID]; ok && path != p.Path {
+			r.pins[i].Path = path
+			moved++
+		}
+	}
+	return moved
+}
+
+// Lookup 在对话列表中查找 ref 对应的对话：
+// 已存在的文件路径、executionId（或其前缀）、文件名，否则按标题搜索（不区分大小写）
+func Lookup(ref string, chats []Chat) []Chat {
+	if info, err := os.Stat(ref); err == nil && !info.IsDir() {
+		abs, _ := filepath.Abs(ref)
+		for _, c := range chats {
+			if c.Path == abs {
+				return []Chat{c}
+			}
+		}
+		if c, err := ReadChat(abs); err == nil {
+			return []Chat{c}
+		}
+	}
+
+	var byID []Chat
+	for _, c := range chats {
+		name := strings.TrimSuffix(filepath.Base(c.Path), ".chat")
+		if c.ExecutionID == ref || name == ref {
+			return []Chat{c}
+		}
+		if len(ref) >= 4 && (strings.HasPrefix(c.ExecutionID, ref) || strings.HasPrefix(name, ref)) {
+			byID = append(byID, c)
+		}
+	}
+	if len(byID) > 0 {
+		return byID
+	}
+
+	var found []Chat
+	query := strings.ToLower(ref)
+	for _, c := range chats {
+		if strings.Contains(strings.ToLower(c.Title), query) {
+			found = append(found, c)
+		}
+	}
+	return found
+}
@@ -0,0 +1,119 @@
+package pins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeChat(t *testing.T, path, executionID, message string) Chat {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	content := `{"executionId":"` + executionID + `","chat":[{"role":"human","content":"` + message + `"},{"role":"bot","content":"ok"}]}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	c, err := ReadChat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func TestReadChat(t *testing.T) {
+	dir := t.TempDir()
+	c := writeChat(t, filepath.Join(dir, "ws", "a.chat"), "f00dcafe-1234", "Fix  the\\nlogin bug")
+	if c.ExecutionID != "f00dcafe-1234" || c.Title != "Fix the login bug" || c.ShortID() != "f00dcafe" {
+		t.Errorf("对话信息不正确: %+v", c)
+	}
+}
+
+func TestRegistryPinAndPersist(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "pins.json")
+	c := writeChat(t, filepath.Join(dir, "ws", "a.chat"), "exec-1", "hello")
+
+	r, err := Load(path)
+	if err != nil || r.Len() != 0 {
+		t.Fatalf("新列表应为空: %v", err)
+	}
+	if !r.Add(c, time.Now()) || r.Add(c, time.Now()) {
+		t.Error("重复固定应返回 false")
+	}
+	if err := r.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err = Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !r.Contains(c.Path) || !r.IsPinned(Chat{ExecutionID: "exec-1"}) {
+		t.Error("重新读取后应仍然固定")
+	}
+	if !r.Remove(c) || r.Remove(c) || r.Contains(c.Path) {
+		t.Error("取消固定失败")
+	}
+
+	var nilRegistry *Registry
+	if nilRegistry.Contains(c.Path) {
+		t.Error("nil 列表不应包含任何文件")
+	}
+}
+
+func TestFollowMovedChat(t *testing.T) {
+	dir := t.TempDir()
+	oldPath := filepath.Join(dir, "ws1", "a.chat")
+	c := writeChat(t, oldPath, "exec-1", "hello")
+
+	r, _ := Load(filepath.Join(dir, "pins.json"))
+	r.Add(c, time.Now())
+
+	newPath := filepath.Join(dir, "ws2", "a.chat")
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Missing()) != 1 {
+		t.Fatal("移动后应报告缺失")
+	}
+
+	moved, _ := ReadChat(newPath)
+	if n := r.Follow([]Chat{moved}); n != 1 {
+		t.Fatalf("应更新 1 个路径，实际 %d", n)
+	}
+	if !r.Contains(newPath) || r.Contains(oldPath) || len(r.Missing()) != 0 {
+		t.Error("固定项应跟随文件移动")
+	}
+}
+
+func TestLookup(t *testing.T) {
+	dir := t.TempDir()
+	a := writeChat(t, filepath.Join(dir, "ws", "one.chat"), "abcdef12-0000", "Refactor the parser")
+	b := writeChat(t, filepath.Join(dir, "ws", "two.chat"), "abcd9999-0000", "Write parser tests")
+	chats := []Chat{a, b}
+
+	if got := Lookup(a.Path, chats); len(got) != 1 || got[0].Path != a.Path {
+		t.Errorf("按路径查找失败: %v", got)
+	}
+	if got := Lookup("abcdef12", chats); len(got) != 1 || got[0].Path != a.Path {
+		t.Errorf("按ID前缀查找失败: %v", got)
+	}
+	if got := Lookup("abcd", chats); len(got) != 2 {
+		t.Errorf("有歧义的前缀应返回多个结果: %v", got)
+	}
+	if got := Lookup("two", chats); len(got) != 1 || got[0].Path != b.Path {
+		t.Errorf("按文件名查找失败: %v", got)
+	}
+	if got := Lookup("PARSER TESTS", chats); len(got) != 1 || got[0].Path != b.Path {
+		t.Errorf("按标题搜索失败: %v", got)
+	}
+	if got := Lookup("nothing", chats); len(got) != 0 {
+		t.Errorf("不应找到对话: %v", got)
+	}
+}
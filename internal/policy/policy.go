@@ -0,0 +1,123 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames 项目根目录下的策略文件名（按优先级）
+var FileNames = []string{".kiro-cleaner.json", ".kiro-cleaner.yaml", ".kiro-cleaner.yml"}
+
+// 可保护的数据范围
+const (
+	ScopeChats    = "chats"    // kiro.kiroagent/<workspace>/*.chat
+	ScopeSessions = "sessions" // workspace-sessions/<workspace>/
+	ScopeHistory  = "history"  // 项目内文件的编辑历史
+)
+
+// Scopes 所有数据范围
+var Scopes = []string{ScopeChats, ScopeSessions, ScopeHistory}
+
+// Policy 项目级清理策略，叠加在全局配置之上
+type Policy struct {
+	Path               string   `json:"-" yaml:"-"`                                       // 策略文件路径
+	Root               string   `json:"-" yaml:"-"`                                       // 项目根目录
+	ChatRetentionDays  int      `json:"chat_retention_days" yaml:"chat_retention_days"`   // 对话保留天数，超过后清理（即使全局保留对话）
+	NeverDelete        []string `json:"never_delete" yaml:"never_delete"`                 // 永不删除的数据范围
+	ExportBeforeDelete bool     `json:"export_before_delete" yaml:"export_before_delete"` // 删除对话前先导出
+}
+
+// Load 读取项目根目录下的策略文件，没有策略文件时返回 nil
+func Load(root string) (*Policy, error) {
+	for _, name := range FileNames {
+		path := filepath.Join(root, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("读取策略文件失败: %v", err)
+		}
+
+		p := &Policy{}
+		if strings.HasSuffix(name, ".json") {
+			err = json.Unmarshal(data, p)
+		} else {
+			err = yaml.Unmarshal(data, p)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("解析策略文件 %s 失败: %v", path, err)
+		}
+		p.Path = path
+		p.Root = root
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		return p, nil
+	}
+	return nil, nil
+}
+
+// Validate 检查策略
+func (p *Policy) Validate() error {
+	if p.ChatRetentionDays < 0 {
+		return fmt.Errorf("%s: chat_retention_days 不能为负数", p.Path)
+	}
+	for _, scope := range p.NeverDelete {
+		if !isScope(scope) {
+			return fmt.Errorf("%s: never_delete 中的范围无效: %s（可选 %s）", p.Path, scope, strings.Join(Scopes, ", "))
+		}
+	}
+	if p.ChatRetentionDays > 0 && p.Protects(ScopeChats) {
+		return fmt.Errorf("%s: chat_retention_days 与 never_delete: chats 冲突", p.Path)
+	}
+	return nil
+}
+
+// Protects 判断数据范围是否永不删除（nil 策略不保护任何数据）
+func (p *Policy) Protects(scope string) bool {
+	if p == nil {
+		return false
+	}
+	for _, s := range p.NeverDelete {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary 返回策略的一行描述
+func (p *Policy) Summary() string {
+	if p == nil {
+		return "global config"
+	}
+	var parts []string
+	if p.ChatRetentionDays > 0 {
+		parts = append(parts, fmt.Sprintf("chats purged after %dd", p.ChatRetentionDays))
+	}
+	if len(p.NeverDelete) > 0 {
+		parts = append(parts, "never delete "+strings.Join(p.NeverDelete, ", "))
+	}
+	if p.ExportBeforeDelete {
+		parts = append(parts, "export before delete")
+	}
+	if len(parts) == 0 {
+		return "no rules"
+	}
+	return strings.Join(parts, "; ")
+}
+
+func isScope(scope string) bool {
+	for _, s := range Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadJSONAndYAML(t *testing.T) {
+	dir := t.TempDir()
+	if p, err := Load(dir); p != nil || err != nil {
+		t.Fatalf("没有策略文件时应返回 nil: %v, %v", p, err)
+	}
+
+	writeFile(t, filepath.Join(dir, ".kiro-cleaner.yaml"), "chat_retention_days: 14\nnever_delete: [history]\nexport_before_delete: true\n")
+	p, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ChatRetentionDays != 14 || !p.Protects(ScopeHistory) || p.Protects(ScopeChats) || !p.ExportBeforeDelete {
+		t.Errorf("YAML 策略解析不正确: %+v", p)
+	}
+	if p.Root != dir || p.Path != filepath.Join(dir, ".kiro-cleaner.yaml") {
+		t.Errorf("路径不正确: %s %s", p.Root, p.Path)
+	}
+
+	// JSON 优先于 YAML
+	writeFile(t, filepath.Join(dir, ".kiro-cleaner.json"), `{"never_delete": ["chats", "sessions"]}`)
+	p, err = Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.Protects(ScopeChats) || !p.Protects(ScopeSessions) || p.ChatRetentionDays != 0 {
+		t.Errorf("应使用 JSON 策略: %+v", p)
+	}
+	if got := p.Summary(); got != "never delete chats, sessions" {
+		t.Errorf("Summary = %q", got)
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	cases := []string{
+		`{"never_delete": ["everything"]}`,
+		`{"chat_retention_days": -1}`,
+		`{"chat_retention_days": 7, "never_delete": ["chats"]}`,
+		`{not json`,
+	}
+	for _, content := range cases {
+		dir := t.TempDir()
+		writeFile(t, filepath.Join(dir, ".kiro-cleaner.json"), content)
+		if _, err := Load(dir); err == nil {
+			t.Errorf("策略 %s 应校验失败", content)
+		}
+	}
+}
+
+func TestNilPolicy(t *testing.T) {
+	var p *Policy
+	if p.Protects(ScopeChats) {
+		t.Error("nil 策略不应保护任何数据")
+	}
+	if p.Summary() != "global config" {
+		t.Errorf("Summary = %q", p.Summary())
+	}
+}
+
+func TestSetLookup(t *testing.T) {
+	base := t.TempDir()
+	project := filepath.Join(base, "project")
+	nested := filepath.Join(project, "packages", "web")
+	other := filepath.Join(base, "other")
+	writeFile(t, filepath.Join(project, ".kiro-cleaner.json"), `{"never_delete": ["history"]}`)
+	writeFile(t, filepath.Join(nested, ".kiro-cleaner.json"), `{"chat_retention_days": 3}`)
+	if err := os.MkdirAll(other, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	set := NewSet([]string{project, nested, other, project})
+	if len(set.Workspaces()) != 3 || len(set.WithPolicy()) != 2 {
+		t.Fatalf("工作区数量不正确: %d/%d", len(set.Workspaces()), len(set.WithPolicy()))
+	}
+
+	if p := set.PolicyForPath(filepath.Join(project, "main.go")); !p.Protects(ScopeHistory) {
+		t.Error("项目内的文件应使用项目策略")
+	}
+	if p := set.PolicyForPath(filepath.Join(nested, "src", "app.ts")); p == nil || p.ChatRetentionDays != 3 {
+		t.Error("应使用最深的项目策略")
+	}
+	if p := set.PolicyForPath(project + "-copy/main.go"); p != nil {
+		t.Error("前缀相同的其他目录不应匹配")
+	}
+	if p := set.PolicyForPath(filepath.Join(other, "x")); p != nil {
+		t.Error("没有策略文件的项目应返回 nil")
+	}
+
+	chat := filepath.Join("agent", ChatWorkspaceID(nested), "abc.chat")
+	if p := set.PolicyForChat(chat); p == nil || p.ChatRetentionDays != 3 {
+		t.Error("应通过目录名找到对话所属的工作区")
+	}
+	if ws := set.ForChat(filepath.Join("agent", "unknown", "abc.chat")); ws != nil {
+		t.Error("未知目录不应匹配工作区")
+	}
+}
+
+func TestDiscoverRoots(t *testing.T) {
+	base := t.TempDir()
+	fromSessions := filepath.Join(base, "a")
+	fromStorage := filepath.Join(base, "b")
+	for _, dir := range []string{fromSessions, fromStorage} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	agentPath := filepath.Join(base, "agent")
+	encoded := base64.StdEncoding.EncodeToString([]byte(fromSessions))
+	if err := os.MkdirAll(filepath.Join(agentPath, "workspace-sessions", encoded), 0755); err != nil {
+		t.Fatal(err)
+	}
+	missing := base64.StdEncoding.EncodeToString([]byte(filepath.Join(base, "gone")))
+	if err := os.MkdirAll(filepath.Join(agentPath, "workspace-sessions", missing), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	kiroPath := filepath.Join(base, "Kiro")
+	writeFile(t, filepath.Join(kiroPath, "User", "workspaceStorage", "123", "workspace.json"), `{"folder": "file://`+fromStorage+`"}`)
+
+	roots := DiscoverRoots(agentPath, []string{kiroPath})
+	if len(roots) != 2 || roots[0] != fromSessions || roots[1] != fromStorage {
+		t.Errorf("roots = %v", roots)
+	}
+}
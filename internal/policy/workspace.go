@@ -0,0 +1,166 @@
+package policy
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/history"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/sessions"
+)
+
+// Workspace 一个已解析到项目根目录的工作区
+type Workspace struct {
+	Root   string  // 项目根目录
+	Policy *Policy // 项目策略（nil 表示只使用全局配置）
+	Err    error   // 读取策略文件的错误
+}
+
+// Set 所有已知工作区及其策略
+type Set struct {
+	workspaces map[string]*Workspace // 项目根目录 -> 工作区
+	chatIDs    map[string]string     // kiro.kiroagent 下的工作区目录名 -> 项目根目录
+}
+
+// ChatWorkspaceID 返回项目根目录在 kiro.kiroagent 下对应的目录名（路径的 md5）
+func ChatWorkspaceID(root string) string {
+	sum := md5.Sum([]byte(root))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewSet 为项目根目录加载策略
+func NewSet(roots []string) *Set {
+	s := &Set{
+		workspaces: make(map[string]*Workspace),
+		chatIDs:    make(map[string]string),
+	}
+	for _, root := range roots {
+		root = filepath.Clean(root)
+		if _, ok := s.workspaces[root]; ok {
+			continue
+		}
+		p, err := Load(root)
+		s.workspaces[root] = &Workspace{Root: root, Policy: p, Err: err}
+		s.chatIDs[ChatWorkspaceID(root)] = root
+		// 部分平台使用 "/" 分隔的路径计算
+		s.chatIDs[ChatWorkspaceID(filepath.ToSlash(root))] = root
+	}
+	return s
+}
+
+// Workspaces 返回所有工作区，按项目根目录排序
+func (s *Set) Workspaces() []*Workspace {
+	var list []*Workspace
+	for _, ws := range s.workspaces {
+		list = append(list, ws)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Root < list[j].Root })
+	return list
+}
+
+// WithPolicy 返回有策略文件的工作区
+func (s *Set) WithPolicy() []*Workspace {
+	var list []*Workspace
+	for _, ws := range s.Workspaces() {
+		if ws.Policy != nil {
+			list = append(list, ws)
+		}
+	}
+	return list
+}
+
+// ForChatDir 返回 kiro.kiroagent/<id> 目录对应的工作区，无法解析时返回 nil
+func (s *Set) ForChatDir(dir string) *Workspace {
+	if root, ok := s.chatIDs[filepath.Base(dir)]; ok {
+		return s.workspaces[root]
+	}
+	return nil
+}
+
+// ForChat 返回对话文件所属的工作区
+func (s *Set) ForChat(chatPath string) *Workspace {
+	return s.ForChatDir(filepath.Dir(chatPath))
+}
+
+// ForPath 返回包含该路径的工作区（取最深的项目根目录）
+func (s *Set) ForPath(path string) *Workspace {
+	if path == "" {
+		return nil
+	}
+	path = filepath.Clean(path)
+	var best *Workspace
+	for root, ws := range s.workspaces {
+		if path != root && !strings.HasPrefix(path, root+string(filepath.Separator)) {
+			continue
+		}
+		if best == nil || len(root) > len(best.Root) {
+			best = ws
+		}
+	}
+	return best
+}
+
+// PolicyForChat 返回对话文件适用的策略（可能为 nil）
+func (s *Set) PolicyForChat(chatPath string) *Policy {
+	if ws := s.ForChat(chatPath); ws != nil {
+		return ws.Policy
+	}
+	return nil
+}
+
+// PolicyForPath 返回路径适用的策略（可能为 nil）
+func (s *Set) PolicyForPath(path string) *Policy {
+	if ws := s.ForPath(path); ws != nil {
+		return ws.Policy
+	}
+	return nil
+}
+
+// DiscoverRoots 从 Kiro 数据中找出工作区的项目根目录：
+// workspace-sessions 的目录名（base64 编码的路径）和 User/workspaceStorage/*/workspace.json
+func DiscoverRoots(agentPath string, kiroPaths []string) []string {
+	seen := make(map[string]bool)
+	var roots []string
+	add := func(root string) {
+		if root == "" || !filepath.IsAbs(root) || seen[root] {
+			return
+		}
+		if info, err := os.Stat(root); err != nil || !info.IsDir() {
+			return
+		}
+		seen[root] = true
+		roots = append(roots, root)
+	}
+
+	if agentPath != "" {
+		entries, _ := os.ReadDir(filepath.Join(agentPath, sessions.SessionsDirName))
+		for _, entry := range entries {
+			if entry.IsDir() {
+				add(filepath.Clean(sessions.DecodeWorkspacePath(entry.Name())))
+			}
+		}
+	}
+
+	for _, kiroPath := range kiroPaths {
+		storageDirs, _ := filepath.Glob(filepath.Join(kiroPath, "User", "workspaceStorage", "*", "workspace.json"))
+		for _, path := range storageDirs {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				continue
+			}
+			var ws struct {
+				Folder string `json:"folder"`
+			}
+			if json.Unmarshal(data, &ws) == nil {
+				add(filepath.Clean(history.ResourcePath(ws.Folder)))
+			}
+		}
+	}
+
+	sort.Strings(roots)
+	return roots
+}
@@ -0,0 +1,153 @@
+package preflight
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// DefaultMinDiskSpace 未配置时的最小剩余空间
+const DefaultMinDiskSpace = "100MB"
+
+// zipEntryOverhead 每个 zip 条目的头部开销估算（本地头 + 中央目录）
+const zipEntryOverhead = 128
+
+// compressionRatios 按扩展名估算的压缩率（压缩后/压缩前）
+var compressionRatios = map[string]float64{
+	// 已压缩格式，几乎无法再压缩
+	".zip": 1.0, ".gz": 1.0, ".tgz": 1.0, ".xz": 1.0, ".zst": 1.0, ".br": 1.0,
+	".png": 1.0, ".jpg": 1.0, ".jpeg": 1.0, ".gif": 1.0, ".webp": 1.0,
+	".woff": 1.0, ".woff2": 1.0, ".mp4": 1.0, ".webm": 1.0,
+	".ldb": 0.95, ".lance": 0.9, ".dmp": 0.5,
+	// 文本类
+	".log": 0.15, ".txt": 0.3, ".json": 0.2, ".chat": 0.2, ".md": 0.35,
+	".js": 0.3, ".ts": 0.3, ".css": 0.25, ".html": 0.25, ".xml": 0.2,
+	".yaml": 0.3, ".yml": 0.3, ".map": 0.25,
+	// 数据库
+	".db": 0.4, ".sqlite": 0.4, ".vscdb": 0.4, ".wal": 0.5,
+}
+
+// defaultCompressionRatio 未知类型的压缩率
+const defaultCompressionRatio = 0.6
+
+// Result 预检结果
+type Result struct {
+	BackupDir       string // 备份目录
+	EstimatedBackup int64  // 估算的压缩备份大小
+	FreeBytes       int64  // 备份卷剩余空间
+	MinFree         int64  // 配置的最小剩余空间
+	Required        int64  // 需要的空间（备份 + 最小剩余）
+	OK              bool   // 空间是否足够
+}
+
+// Shortfall 返回空间缺口
+func (r *Result) Shortfall() int64 {
+	if r.OK {
+		return 0
+	}
+	return r.Required - r.FreeBytes
+}
+
+// ParseSize 解析 "100MB"、"1.5 GB"、"512k"、"2GiB" 或纯字节数（1024 进制）
+func ParseSize(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+
+	i := 0
+	for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.') {
+		i++
+	}
+	number, unit := s[:i], strings.ToUpper(strings.TrimSpace(s[i:]))
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("无效的大小: %q", s)
+	}
+
+	multiplier := map[string]float64{
+		"": 1, "B": 1,
+		"K": 1 << 10, "KB": 1 << 10, "KIB": 1 << 10,
+		"M": 1 << 20, "MB": 1 << 20, "MIB": 1 << 20,
+		"G": 1 << 30, "GB": 1 << 30, "GIB": 1 << 30,
+		"T": 1 << 40, "TB": 1 << 40, "TIB": 1 << 40,
+	}[unit]
+	if multiplier == 0 {
+		return 0, fmt.Errorf("无效的大小单位: %q", s)
+	}
+	return int64(value * multiplier), nil
+}
+
+// EstimateCompressedSize 按扩展名估算文件压缩后的备份大小
+func EstimateCompressedSize(files []types.FileInfo) int64 {
+	var total float64
+	for _, f := range files {
+		size := f.Size
+		if size == 0 {
+			if info, err := os.Stat(f.Path); err == nil {
+				size = info.Size()
+			}
+		}
+		ratio, ok := compressionRatios[strings.ToLower(filepath.Ext(f.Path))]
+		if !ok {
+			ratio = defaultCompressionRatio
+		}
+		total += float64(size)*ratio + zipEntryOverhead + float64(len(f.Path))
+	}
+	return int64(total)
+}
+
+// Check 检查备份卷是否有足够空间写入备份并保留最小剩余空间
+func Check(files []types.FileInfo, backupDir string, safety *types.SafetyConfig) (*Result, error) {
+	minSpec := DefaultMinDiskSpace
+	if safety != nil && safety.MinDiskSpace != "" {
+		minSpec = safety.MinDiskSpace
+	}
+	minFree, err := ParseSize(minSpec)
+	if err != nil {
+		return nil, fmt.Errorf("解析 min_disk_space 失败: %v", err)
+	}
+
+	free, err := FreeSpace(backupDir)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		BackupDir:       backupDir,
+		EstimatedBackup: EstimateCompressedSize(files),
+		FreeBytes:       free,
+		MinFree:         minFree,
+	}
+	result.Required = result.EstimatedBackup + minFree
+	result.OK = free >= result.Required
+	return result, nil
+}
+
+// FreeSpace 返回路径所在卷的可用空间，路径不存在时使用最近的已存在父目录
+func FreeSpace(path string) (int64, error) {
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return 0, err
+	}
+	for {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return 0, fmt.Errorf("找不到已存在的目录: %s", path)
+		}
+		dir = parent
+	}
+
+	free, err := freeSpace(dir)
+	if err != nil {
+		return 0, fmt.Errorf("读取磁盘剩余空间失败: %v", err)
+	}
+	return free, nil
+}
@@ -0,0 +1,196 @@
+package preflight
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected int64
+	}{
+		{"", 0},
+		{"100", 100},
+		{"100B", 100},
+		{"512k", 512 << 10},
+		{"100MB", 100 << 20},
+		{"1.5 GB", 3 << 29},
+		{"2GiB", 2 << 30},
+		{"1tb", 1 << 40},
+	}
+	for _, tt := range tests {
+		got, err := ParseSize(tt.input)
+		if err != nil {
+			t.Errorf("ParseSize(%q) 返回错误: %v", tt.input, err)
+			continue
+		}
+		if got != tt.expected {
+			t.Errorf("ParseSize(%q) = %d, want %d", tt.input, got, tt.expected)
+		}
+	}
+
+	for _, bad := range []string{"abc", "10XB", "-5MB", "MB"} {
+		if _, err := ParseSize(bad); err == nil {
+			t.Errorf("ParseSize(%q) 应该返回错误", bad)
+		}
+	}
+}
+
+func TestEstimateCompressedSize(t *testing.T) {
+	logs := EstimateCompressedSize([]types.FileInfo{{Path: "/a/main.log", Size: 1 << 20}})
+	images := EstimateCompressedSize([]types.FileInfo{{Path: "/a/shot.png", Size: 1 << 20}})
+	if logs >= images {
+		t.Errorf("日志应比已压缩的图片压缩得更小: log=%d png=%d", logs, images)
+	}
+	if images < 1<<20 {
+		t.Errorf("已压缩文件的估算不应小于原始大小: %d", images)
+	}
+
+	// 未给出大小时读取文件
+	path := filepath.Join(t.TempDir(), "data.bin")
+	os.WriteFile(path, make([]byte, 4096), 0644)
+	if got := EstimateCompressedSize([]types.FileInfo{{Path: path}}); got < 2048 {
+		t.Errorf("应按文件实际大小估算，实际 %d", got)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	files := []types.FileInfo{{Path: "/a/main.log", Size: 1 << 20}}
+
+	free, err := FreeSpace(dir)
+	if err != nil || free <= 0 {
+		t.Fatalf("读取剩余空间失败: %d, %v", free, err)
+	}
+
+	ok, err := Check(files, filepath.Join(dir, "not", "created", "yet"), &types.SafetyConfig{MinDiskSpace: "1KB"})
+	if err != nil {
+		t.Fatalf("预检失败: %v", err)
+	}
+	if !ok.OK || ok.Shortfall() != 0 {
+		t.Errorf("空间足够时应通过: %+v", ok)
+	}
+
+	tooMuch, err := Check(files, dir, &types.SafetyConfig{MinDiskSpace: "1000000TB"})
+	if err != nil {
+		t.Fatalf("预检失败: %v", err)
+	}
+	if tooMuch.OK || tooMuch.Shortfall() <= 0 {
+		t.Errorf("空间不足时应拒绝: %+v", tooMuch)
+	}
+
+	if _, err := Check(files, dir, &types.SafetyConfig{MinDiskSpace: "lots"}); err == nil {
+		t.Error("无效的 min_disk_space 应该返回错误")
+	}
+}
+
+func TestQuarantine_Move(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "Kiro")
+	file := filepath.Join(root, "logs", "main.log")
+	os.MkdirAll(filepath.Dir(file), 0755)
+	os.WriteFile(file, []byte("log data"), 0644)
+
+	q := NewQuarantine([]string{root})
+	dest, err := q.Move(file)
+	if err != nil {
+		t.Fatalf("隔离失败: %v", err)
+	}
+
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("原文件应该被移走")
+	}
+	want := filepath.Join(q.Dir(root), "Kiro", "logs", "main.log")
+	if dest != want {
+		t.Errorf("隔离路径错误: %s, want %s", dest, want)
+	}
+	if data, _ := os.ReadFile(dest); string(data) != "log data" {
+		t.Error("隔离后的文件内容应保持不变")
+	}
+
+	f, err := os.Open(filepath.Join(q.Dir(root), ManifestFileName))
+	if err != nil {
+		t.Fatalf("应写入隔离清单: %v", err)
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	scanner.Scan()
+	var entry QuarantineEntry
+	if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil || entry.Original != file || entry.Quarantined != dest {
+		t.Errorf("隔离清单内容错误: %s", scanner.Text())
+	}
+
+	outside := filepath.Join(parent, "other.txt")
+	os.WriteFile(outside, []byte("x"), 0644)
+	if _, err := q.Move(outside); err == nil {
+		t.Error("Kiro 数据目录之外的文件不应被隔离")
+	}
+}
+
+func TestQuarantine_ListRestorePurge(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "Kiro")
+	a := filepath.Join(root, "logs", "a.log")
+	b := filepath.Join(root, "logs", "b.log")
+	os.MkdirAll(filepath.Dir(a), 0755)
+	os.WriteFile(a, []byte("aaaa"), 0644)
+	os.WriteFile(b, []byte("bb"), 0644)
+
+	q := NewQuarantine([]string{root})
+	q.Move(a)
+	q.Move(b)
+
+	batches, err := ListQuarantine([]string{root, root})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 1 || len(batches[0].Entries) != 2 || batches[0].Size != 6 {
+		t.Fatalf("隔离批次不正确: %+v", batches)
+	}
+
+	// 原位置已有文件时保留隔离的副本
+	os.WriteFile(b, []byte("new"), 0644)
+	restored, errs := batches[0].Restore([]string{root})
+	if len(restored) != 1 || restored[0].Original != a || len(errs) != 1 {
+		t.Fatalf("恢复结果不正确: %+v %v", restored, errs)
+	}
+	if data, _ := os.ReadFile(a); string(data) != "aaaa" {
+		t.Error("文件应恢复到原位置")
+	}
+	if data, _ := os.ReadFile(b); string(data) != "new" {
+		t.Error("不应覆盖原位置的文件")
+	}
+
+	batches, _ = ListQuarantine([]string{root})
+	if len(batches) != 1 || len(batches[0].Entries) != 1 || batches[0].Entries[0].Original != b {
+		t.Fatalf("清单应只保留未恢复的文件: %+v", batches)
+	}
+	if err := batches[0].Purge(); err != nil {
+		t.Fatal(err)
+	}
+	if batches, _ = ListQuarantine([]string{root}); len(batches) != 0 {
+		t.Errorf("清除后不应有批次: %+v", batches)
+	}
+}
+
+func TestQuarantine_RestoreRejectsOutsideRoots(t *testing.T) {
+	parent := t.TempDir()
+	root := filepath.Join(parent, "Kiro")
+	file := filepath.Join(root, "a.log")
+	os.MkdirAll(root, 0755)
+	os.WriteFile(file, []byte("x"), 0644)
+	q := NewQuarantine([]string{root})
+	dest, _ := q.Move(file)
+
+	// 被改写的清单指向数据目录之外
+	batch := QuarantineBatch{Dir: q.Dir(root), Entries: []QuarantineEntry{{Original: filepath.Join(parent, "evil"), Quarantined: dest}}}
+	if restored, errs := batch.Restore([]string{root}); len(restored) != 0 || len(errs) != 1 {
+		t.Errorf("不应恢复到数据目录之外: %+v %v", restored, errs)
+	}
+}
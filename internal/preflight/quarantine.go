@@ -0,0 +1,252 @@
+package preflight
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+)
+
+// QuarantineDirName 隔离目录名（位于 Kiro 数据目录的同级目录中）
+const QuarantineDirName = ".kiro-cleaner-quarantine"
+
+// ManifestFileName 隔离清单文件名
+const ManifestFileName = "manifest.jsonl"
+
+// QuarantineEntry 隔离清单中的一条记录
+type QuarantineEntry struct {
+	Original    string    `json:"original"`
+	Quarantined string    `json:"quarantined"`
+	Size        int64     `json:"size"`
+	MovedAt     time.Time `json:"moved_at"`
+}
+
+// Quarantine 通过重命名把文件移到同一文件系统上的隔离目录，不需要额外空间
+type Quarantine struct {
+	roots []string
+	stamp string
+}
+
+// NewQuarantine 创建隔离器，roots 为允许隔离的 Kiro 数据目录
+func NewQuarantine(roots []string) *Quarantine {
+	return &Quarantine{
+		roots: roots,
+		stamp: time.Now().Format("20060102_150405"),
+	}
+}
+
+// ID 返回本次隔离的批次 ID（即批次目录名）
+func (q *Quarantine) ID() string {
+	return q.stamp
+}
+
+// Dir 返回某个 Kiro 数据目录对应的本次隔离目录
+func (q *Quarantine) Dir(root string) string {
+	return filepath.Join(QuarantineDir(root), q.stamp)
+}
+
+// Move 将文件或目录移入隔离目录，返回新路径
+func (q *Quarantine) Move(path string) (string, error) {
+	root, rel, ok := q.locate(path)
+	if !ok {
+		return "", fmt.Errorf("路径不在 Kiro 数据目录内: %s", path)
+	}
+	if err := safety.NewGuard(q.roots...).Check(path); err != nil {
+		return "", err
+	}
+
+	info, err := os.Lstat(path)
+	if err != nil {
+		return "", err
+	}
+
+	dir := q.Dir(root)
+	dest := filepath.Join(dir, filepath.Base(root), rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0700); err != nil {
+		return "", fmt.Errorf("创建隔离目录失败: %v", err)
+	}
+	if err := os.Rename(path, dest); err != nil {
+		return "", fmt.Errorf("移动到隔离目录失败: %v", err)
+	}
+
+	entry := QuarantineEntry{Original: path, Quarantined: dest, Size: info.Size(), MovedAt: time.Now()}
+	if err := appendManifest(filepath.Join(dir, ManifestFileName), entry); err != nil {
+		return dest, fmt.Errorf("写入隔离清单失败: %v", err)
+	}
+	return dest, nil
+}
+
+// locate 找到包含路径的 Kiro 数据目录
+func (q *Quarantine) locate(path string) (string, string, bool) {
+	for _, root := range q.roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return root, rel, true
+	}
+	return "", "", false
+}
+
+// appendManifest 追加一条隔离记录
+func appendManifest(path string, entry QuarantineEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// QuarantineBatch 一次清理隔离的文件（隔离目录下以时间戳命名的子目录）
+type QuarantineBatch struct {
+	ID      string            // 时间戳，如 20240301_120000
+	Dir     string            // 批次目录
+	Entries []QuarantineEntry // 清单中仍在隔离目录里的文件
+	Size    int64             // 批次目录中所有文件的大小
+}
+
+// QuarantineDir 返回 Kiro 数据目录对应的隔离目录
+func QuarantineDir(root string) string {
+	return filepath.Join(filepath.Dir(filepath.Clean(root)), QuarantineDirName)
+}
+
+// ListQuarantine 列出各 Kiro 数据目录对应隔离目录中的批次，最新的在前
+func ListQuarantine(roots []string) ([]QuarantineBatch, error) {
+	var batches []QuarantineBatch
+	seen := make(map[string]bool)
+	for _, root := range roots {
+		dir := QuarantineDir(root)
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("读取隔离目录失败: %v", err)
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() {
+				continue
+			}
+			batch := QuarantineBatch{ID: entry.Name(), Dir: filepath.Join(dir, entry.Name())}
+			batch.Entries, err = readManifest(filepath.Join(batch.Dir, ManifestFileName))
+			if err != nil {
+				return nil, err
+			}
+			safety.Walk(batch.Dir, func(path string, info os.FileInfo, err error) error {
+				if err == nil && info.Mode().IsRegular() && info.Name() != ManifestFileName {
+					batch.Size += info.Size()
+				}
+				return nil
+			})
+			batches = append(batches, batch)
+		}
+	}
+	sort.SliceStable(batches, func(i, j int) bool { return batches[i].ID > batches[j].ID })
+	return batches, nil
+}
+
+// Restore 把批次中的文件移回原位置，roots 为允许恢复到的 Kiro 数据目录
+// 原位置已有文件时保留隔离的副本；全部恢复后删除批次目录
+func (b QuarantineBatch) Restore(roots []string) (restored []QuarantineEntry, errs []error) {
+	guard := safety.NewGuard(roots...)
+	var remaining []QuarantineEntry
+	for _, e := range b.Entries {
+		if err := b.restoreEntry(e, guard); err != nil {
+			errs = append(errs, err)
+			remaining = append(remaining, e)
+			continue
+		}
+		restored = append(restored, e)
+	}
+	if len(remaining) == 0 {
+		if err := os.RemoveAll(b.Dir); err != nil {
+			errs = append(errs, fmt.Errorf("删除隔离目录失败: %v", err))
+		}
+		return restored, errs
+	}
+	if err := writeManifest(filepath.Join(b.Dir, ManifestFileName), remaining); err != nil {
+		errs = append(errs, fmt.Errorf("更新隔离清单失败: %v", err))
+	}
+	return restored, errs
+}
+
+// restoreEntry 恢复一个文件：清单可能被改写，隔离路径必须在批次目录内，原路径必须在 Kiro 数据目录内
+func (b QuarantineBatch) restoreEntry(e QuarantineEntry, guard *safety.Guard) error {
+	rel, err := filepath.Rel(b.Dir, e.Quarantined)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("隔离路径不在批次目录内: %s", e.Quarantined)
+	}
+	if !filepath.IsAbs(e.Original) {
+		return fmt.Errorf("原路径无效: %s", e.Original)
+	}
+	if err := guard.Check(e.Original); err != nil {
+		return err
+	}
+	if _, err := os.Lstat(e.Original); err == nil {
+		return fmt.Errorf("原位置已有文件: %s", e.Original)
+	}
+	if err := os.MkdirAll(filepath.Dir(e.Original), 0755); err != nil {
+		return fmt.Errorf("创建目录失败: %v", err)
+	}
+	if err := os.Rename(e.Quarantined, e.Original); err != nil {
+		return fmt.Errorf("恢复失败: %v", err)
+	}
+	return nil
+}
+
+// Purge 永久删除批次中的文件
+func (b QuarantineBatch) Purge() error {
+	if err := os.RemoveAll(b.Dir); err != nil {
+		return fmt.Errorf("删除隔离目录失败: %v", err)
+	}
+	return nil
+}
+
+// readManifest 读取隔离清单，跳过格式不正确的行，清单不存在时返回空
+func readManifest(path string) ([]QuarantineEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取隔离清单失败: %v", err)
+	}
+	var entries []QuarantineEntry
+	for _, line := range strings.Split(string(data), "\n") {
+		var e QuarantineEntry
+		if strings.TrimSpace(line) == "" || json.Unmarshal([]byte(line), &e) != nil {
+			continue
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// writeManifest 原子改写隔离清单
+func writeManifest(path string, entries []QuarantineEntry) error {
+	var buf []byte
+	for _, e := range entries {
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		buf = append(append(buf, data...), '\n')
+	}
+	return utils.WriteFileAtomic(path, buf, 0600)
+}
@@ -0,0 +1,14 @@
+//go:build !windows
+
+package preflight
+
+import "syscall"
+
+// freeSpace 通过 statfs 获取非特权用户可用空间
+func freeSpace(dir string) (int64, error) {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(dir, &st); err != nil {
+		return 0, err
+	}
+	return int64(st.Bavail) * int64(st.Bsize), nil
+}
@@ -0,0 +1,175 @@
+package profile
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 清理类别
+const (
+	CategoryTemp    = "temp"
+	CategoryLogs    = "logs"
+	CategoryCache   = "cache"
+	CategoryIndex   = "index"
+	CategoryChats   = "chats"
+	CategoryHistory = "history"
+	CategoryCrash   = "crash"
+	CategoryOrphans = "orphans" // 没有被引用的快照和差异内容，删除是安全的
+)
+
+// Categories 所有清理类别（按显示顺序）
+var Categories = []string{
+	CategoryLogs, CategoryCache, CategoryIndex, CategoryChats,
+	CategoryHistory, CategoryCrash, CategoryOrphans, CategoryTemp,
+}
+
+// Profile 命名的清理预设
+type Profile struct {
+	Name        string         `json:"-"`
+	Description string         `json:"description"`
+	Categories  []string       `json:"categories"`           // 要清理的类别
+	KeepRecent  int            `json:"keep_recent"`          // 保留最近N天修改的文件（所有类别）
+	OlderThan   map[string]int `json:"older_than,omitempty"` // 类别 -> 只清理超过N天的文件
+	Builtin     bool           `json:"-"`
+}
+
+// Builtins 内置预设，配置中的同名预设会覆盖它们
+var Builtins = map[string]Profile{
+	"light": {
+		Description: "Temp files, crash reports past retention and orphaned snapshots",
+		Categories:  []string{CategoryTemp, CategoryCrash, CategoryOrphans},
+	},
+	"weekly": {
+		Description: "Logs older than 7 days, cache, orphaned snapshots, history older than 30 days",
+		Categories:  []string{CategoryLogs, CategoryCache, CategoryOrphans, CategoryHistory},
+		OlderThan: map[string]int{
+			CategoryLogs:    7,
+			CategoryHistory: 30,
+		},
+	},
+	"nuclear": {
+		Description: "Everything, including the code index and conversations",
+		Categories:  append([]string(nil), Categories...),
+	},
+}
+
+// Includes 判断预设是否清理某个类别
+func (p Profile) Includes(category string) bool {
+	for _, c := range p.Categories {
+		if c == category {
+			return true
+		}
+	}
+	return false
+}
+
+// MinAgeDays 返回类别中文件需要达到的最小天数（取 keep_recent 和 older_than 的较大值）
+func (p Profile) MinAgeDays(category string) int {
+	days := p.KeepRecent
+	if d := p.OlderThan[category]; d > days {
+		days = d
+	}
+	return days
+}
+
+// Cutoff 返回类别的截止时间，之后修改的文件应保留；零值表示不限制
+func (p Profile) Cutoff(category string, now time.Time) time.Time {
+	days := p.MinAgeDays(category)
+	if days <= 0 {
+		return time.Time{}
+	}
+	return now.AddDate(0, 0, -days)
+}
+
+// Validate 检查类别名称和天数
+func (p Profile) Validate() error {
+	if len(p.Categories) == 0 {
+		return fmt.Errorf("预设 %s 没有选择任何类别", p.Name)
+	}
+	for _, c := range p.Categories {
+		if !isCategory(c) {
+			return fmt.Errorf("预设 %s 中的类别无效: %s（可选 %s）", p.Name, c, strings.Join(Categories, ", "))
+		}
+	}
+	if p.KeepRecent < 0 {
+		return fmt.Errorf("预设 %s 的 keep_recent 不能为负数", p.Name)
+	}
+	for c, days := range p.OlderThan {
+		if !isCategory(c) {
+			return fmt.Errorf("预设 %s 的 older_than 中类别无效: %s", p.Name, c)
+		}
+		if days < 0 {
+			return fmt.Errorf("预设 %s 的 older_than.%s 不能为负数", p.Name, c)
+		}
+	}
+	return nil
+}
+
+// Summary 返回一行描述，如 "logs >7d, cache, history >30d"
+func (p Profile) Summary() string {
+	var parts []string
+	for _, c := range Categories {
+		if !p.Includes(c) {
+			continue
+		}
+		if days := p.MinAgeDays(c); days > 0 {
+			parts = append(parts, fmt.Sprintf("%s >%dd", c, days))
+		} else {
+			parts = append(parts, c)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Lookup 按名称查找预设，配置中的预设优先于内置预设
+func Lookup(name string, custom map[string]Profile) (Profile, error) {
+	if p, ok := custom[name]; ok {
+		p.Name = name
+		return p, p.Validate()
+	}
+	if p, ok := Builtins[name]; ok {
+		p.Name = name
+		p.Builtin = true
+		return p, nil
+	}
+	return Profile{}, fmt.Errorf("未找到预设: %s（可选 %s）", name, strings.Join(Names(custom), ", "))
+}
+
+// List 返回所有预设（内置和配置中的），按名称排序
+func List(custom map[string]Profile) []Profile {
+	var profiles []Profile
+	for _, name := range Names(custom) {
+		p, _ := Lookup(name, custom)
+		p.Name = name
+		profiles = append(profiles, p)
+	}
+	return profiles
+}
+
+// Names 返回所有预设名称，按名称排序
+func Names(custom map[string]Profile) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for name := range Builtins {
+		seen[name] = true
+		names = append(names, name)
+	}
+	for name := range custom {
+		if !seen[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func isCategory(c string) bool {
+	for _, known := range Categories {
+		if c == known {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package profile
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuiltinsAreValid(t *testing.T) {
+	for name, p := range Builtins {
+		p.Name = name
+		if err := p.Validate(); err != nil {
+			t.Errorf("内置预设 %s 无效: %v", name, err)
+		}
+	}
+}
+
+func TestBuiltinRecipes(t *testing.T) {
+	light, _ := Lookup("light", nil)
+	if !light.Includes(CategoryTemp) || !light.Includes(CategoryCrash) || !light.Includes(CategoryOrphans) || light.Includes(CategoryLogs) {
+		t.Errorf("light 应只包含 temp、crash 和 orphans: %v", light.Categories)
+	}
+
+	weekly, _ := Lookup("weekly", nil)
+	if weekly.MinAgeDays(CategoryLogs) != 7 || weekly.MinAgeDays(CategoryHistory) != 30 || weekly.MinAgeDays(CategoryCache) != 0 {
+		t.Errorf("weekly 的天数不正确: %v", weekly.OlderThan)
+	}
+	if weekly.Includes(CategoryChats) || weekly.Includes(CategoryIndex) {
+		t.Error("weekly 不应清理对话和索引")
+	}
+
+	nuclear, _ := Lookup("nuclear", nil)
+	for _, c := range Categories {
+		if !nuclear.Includes(c) {
+			t.Errorf("nuclear 应包含 %s", c)
+		}
+	}
+}
+
+func TestLookupCustomOverridesBuiltin(t *testing.T) {
+	custom := map[string]Profile{
+		"light": {Categories: []string{CategoryTemp}},
+		"team":  {Categories: []string{CategoryCache}, KeepRecent: 3},
+	}
+	light, err := Lookup("light", custom)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if light.Builtin || light.Includes(CategoryCrash) {
+		t.Errorf("配置中的 light 应覆盖内置预设: %+v", light)
+	}
+
+	team, err := Lookup("team", custom)
+	if err != nil || team.Name != "team" {
+		t.Fatalf("应找到配置中的预设: %v", err)
+	}
+	if _, err := Lookup("missing", custom); err == nil {
+		t.Error("未知预设应报错")
+	}
+
+	names := Names(custom)
+	want := []string{"light", "nuclear", "team", "weekly"}
+	if len(names) != len(want) {
+		t.Fatalf("名称 = %v, 期望 %v", names, want)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("名称 = %v, 期望 %v", names, want)
+		}
+	}
+}
+
+func TestLookupValidatesCustom(t *testing.T) {
+	custom := map[string]Profile{
+		"bad":   {Categories: []string{"everything"}},
+		"empty": {},
+		"age":   {Categories: []string{CategoryLogs}, OlderThan: map[string]int{"bogus": 3}},
+	}
+	for name := range custom {
+		if _, err := Lookup(name, custom); err == nil {
+			t.Errorf("预设 %s 应校验失败", name)
+		}
+	}
+}
+
+func TestCutoffAndSummary(t *testing.T) {
+	p := Profile{
+		Categories: []string{CategoryLogs, CategoryCache},
+		KeepRecent: 2,
+		OlderThan:  map[string]int{CategoryLogs: 7},
+	}
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	if got := p.Cutoff(CategoryLogs, now); !got.Equal(now.AddDate(0, 0, -7)) {
+		t.Errorf("logs 截止时间 = %v", got)
+	}
+	if got := p.Cutoff(CategoryCache, now); !got.Equal(now.AddDate(0, 0, -2)) {
+		t.Errorf("keep_recent 应对所有类别生效: %v", got)
+	}
+	if got := (Profile{}).Cutoff(CategoryTemp, now); !got.IsZero() {
+		t.Errorf("没有天数限制时应返回零值: %v", got)
+	}
+	if got := p.Summary(); got != "logs >7d, cache >2d" {
+		t.Errorf("Summary = %q", got)
+	}
+}
@@ -0,0 +1,217 @@
+package safety
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	// ErrOutsideRoot 路径不在允许的目录内
+	ErrOutsideRoot = errors.New("路径不在允许的目录内")
+	// ErrSymlinkEscape 路径经符号链接解析后指向允许目录之外
+	ErrSymlinkEscape = errors.New("路径经符号链接指向允许的目录之外")
+	// ErrRootTarget 不允许直接删除或移动允许的根目录
+	ErrRootTarget = errors.New("不允许操作根目录本身")
+	// ErrUnsafeArchivePath 归档条目名不安全（绝对路径或包含 ..）
+	ErrUnsafeArchivePath = errors.New("归档条目路径不安全")
+)
+
+// root 允许的根目录（原始路径和符号链接解析后的路径）
+type root struct {
+	path     string
+	resolved string
+}
+
+// Guard 破坏性操作（删除、移动、恢复）的路径守卫
+// 所有目标必须位于允许的根目录内，且解析符号链接后仍位于该根目录内
+type Guard struct {
+	roots []root
+}
+
+// NewGuard 创建路径守卫
+func NewGuard(roots ...string) *Guard {
+	g := &Guard{}
+	for _, r := range roots {
+		g.AddRoot(r)
+	}
+	return g
+}
+
+// AddRoot 添加允许的根目录
+func (g *Guard) AddRoot(path string) {
+	if path == "" {
+		return
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return
+	}
+	resolved, err := resolveExisting(abs)
+	if err != nil {
+		resolved = abs
+	}
+	g.roots = append(g.roots, root{path: abs, resolved: resolved})
+}
+
+// Roots 返回允许的根目录
+func (g *Guard) Roots() []string {
+	roots := make([]string, len(g.roots))
+	for i, r := range g.roots {
+		roots[i] = r.path
+	}
+	return roots
+}
+
+// Check 检查路径是否可以被删除或移动
+// 路径本身可以是符号链接（删除的是链接而不是目标），但其所在目录解析后必须仍在根目录内
+func (g *Guard) Check(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("解析路径失败: %v", err)
+	}
+
+	for _, r := range g.roots {
+		if abs == r.path {
+			return fmt.Errorf("%w: %s", ErrRootTarget, abs)
+		}
+		if !within(abs, r.path) {
+			continue
+		}
+
+		parent, err := resolveExisting(filepath.Dir(abs))
+		if err != nil {
+			return fmt.Errorf("解析路径失败: %w", err)
+		}
+		if parent != r.resolved && !within(parent, r.resolved) {
+			return fmt.Errorf("%w: %s -> %s", ErrSymlinkEscape, abs, parent)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrOutsideRoot, abs)
+}
+
+// Remove 检查后删除文件或空目录
+func (g *Guard) Remove(path string) error {
+	if err := g.Check(path); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// RemoveAll 检查后递归删除（os.RemoveAll 不会跟随目录内的符号链接）
+func (g *Guard) RemoveAll(path string) error {
+	if err := g.Check(path); err != nil {
+		return err
+	}
+	return os.RemoveAll(path)
+}
+
+// Rename 检查源和目标后移动文件
+func (g *Guard) Rename(src, dst string) error {
+	if err := g.Check(src); err != nil {
+		return err
+	}
+	if err := g.Check(dst); err != nil {
+		return err
+	}
+	return os.Rename(src, dst)
+}
+
+// SafeJoin 将归档条目名拼接到目标目录，拒绝绝对路径和 .. 逃逸（zip-slip）
+func SafeJoin(base, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("%w: 空条目名", ErrUnsafeArchivePath)
+	}
+	// 归档内统一使用 /，同时拒绝 Windows 风格的分隔符和盘符
+	normalized := strings.ReplaceAll(name, `\`, "/")
+	if strings.HasPrefix(normalized, "/") || filepath.IsAbs(name) || filepath.VolumeName(name) != "" ||
+		(len(normalized) >= 2 && normalized[1] == ':') {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+	}
+	for _, part := range strings.Split(normalized, "/") {
+		if part == ".." {
+			return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+		}
+	}
+
+	joined := filepath.Join(base, filepath.FromSlash(normalized))
+	if !within(joined, filepath.Clean(base)) {
+		return "", fmt.Errorf("%w: %s", ErrUnsafeArchivePath, name)
+	}
+	return joined, nil
+}
+
+// CheckExtractTarget 检查解压目标：目标所在目录解析符号链接后仍须位于 base 内
+func CheckExtractTarget(base, target string) error {
+	resolvedBase, err := resolveExisting(base)
+	if err != nil {
+		return err
+	}
+	parent, err := resolveExisting(filepath.Dir(target))
+	if err != nil {
+		return err
+	}
+	if parent != resolvedBase && !within(parent, resolvedBase) {
+		return fmt.Errorf("%w: %s -> %s", ErrSymlinkEscape, target, parent)
+	}
+	if info, err := os.Lstat(target); err == nil && IsSymlink(info) {
+		return fmt.Errorf("%w: %s 是符号链接", ErrSymlinkEscape, target)
+	}
+	return nil
+}
+
+// Walk 与 filepath.Walk 相同，但不把符号链接交给 fn：统计或清理时不会触及链接指向的目录之外的文件
+func Walk(root string, fn filepath.WalkFunc) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && IsSymlink(info) {
+			return nil
+		}
+		return fn(path, info, err)
+	})
+}
+
+// IsSymlink 判断路径本身是否是符号链接
+func IsSymlink(info os.FileInfo) bool {
+	return info != nil && info.Mode()&os.ModeSymlink != 0
+}
+
+// within 判断 path 是否严格位于 dir 内（词法判断）
+func within(path, dir string) bool {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return false
+	}
+	return rel != "." && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
+
+// resolveExisting 解析路径中已存在部分的符号链接，不存在的部分原样拼接
+func resolveExisting(path string) (string, error) {
+	path = filepath.Clean(path)
+	var rest []string
+	current := path
+	for {
+		resolved, err := filepath.EvalSymlinks(current)
+		if err == nil {
+			for i := len(rest) - 1; i >= 0; i-- {
+				resolved = filepath.Join(resolved, rest[i])
+			}
+			return resolved, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", err
+		}
+		// 悬空的符号链接无法确定最终位置，直接拒绝
+		if info, lerr := os.Lstat(current); lerr == nil && IsSymlink(info) {
+			return "", fmt.Errorf("%w: 悬空的符号链接 %s", ErrSymlinkEscape, current)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path, nil
+		}
+		rest = append(rest, filepath.Base(current))
+		current = parent
+	}
+}
@@ -0,0 +1,223 @@
+package safety
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupRoot 创建一个根目录和一个根目录之外的目录
+func setupRoot(t *testing.T) (root, outside string) {
+	t.Helper()
+	base := t.TempDir()
+	root = filepath.Join(base, "Kiro")
+	outside = filepath.Join(base, "outside")
+	os.MkdirAll(filepath.Join(root, "logs"), 0755)
+	os.MkdirAll(outside, 0755)
+	return root, outside
+}
+
+func TestGuard_AllowsPathInsideRoot(t *testing.T) {
+	root, _ := setupRoot(t)
+	file := filepath.Join(root, "logs", "main.log")
+	os.WriteFile(file, []byte("x"), 0644)
+
+	g := NewGuard(root)
+	if err := g.Remove(file); err != nil {
+		t.Fatalf("根目录内的文件应允许删除: %v", err)
+	}
+	if _, err := os.Stat(file); !os.IsNotExist(err) {
+		t.Error("文件应该被删除")
+	}
+}
+
+func TestGuard_RejectsOutsideRoot(t *testing.T) {
+	root, outside := setupRoot(t)
+	file := filepath.Join(outside, "important.txt")
+	os.WriteFile(file, []byte("x"), 0644)
+
+	err := NewGuard(root).Remove(file)
+	if !errors.Is(err, ErrOutsideRoot) {
+		t.Fatalf("根目录之外的文件应被拒绝, 实际: %v", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Error("根目录之外的文件不应被删除")
+	}
+}
+
+func TestGuard_RejectsDotDotTraversal(t *testing.T) {
+	root, outside := setupRoot(t)
+	file := filepath.Join(outside, "important.txt")
+	os.WriteFile(file, []byte("x"), 0644)
+
+	traversal := root + string(filepath.Separator) + filepath.Join("logs", "..", "..", "outside", "important.txt")
+	if err := NewGuard(root).Remove(traversal); !errors.Is(err, ErrOutsideRoot) {
+		t.Fatalf(".. 逃逸应被拒绝, 实际: %v", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Error("通过 .. 指向的文件不应被删除")
+	}
+}
+
+func TestGuard_RejectsRootItself(t *testing.T) {
+	root, _ := setupRoot(t)
+	if err := NewGuard(root).RemoveAll(root); !errors.Is(err, ErrRootTarget) {
+		t.Fatalf("根目录本身应被拒绝, 实际: %v", err)
+	}
+	if _, err := os.Stat(root); err != nil {
+		t.Error("根目录不应被删除")
+	}
+}
+
+func TestGuard_RejectsSymlinkedParentEscape(t *testing.T) {
+	root, outside := setupRoot(t)
+	file := filepath.Join(outside, "important.txt")
+	os.WriteFile(file, []byte("x"), 0644)
+
+	link := filepath.Join(root, "logs", "escape")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+
+	err := NewGuard(root).Remove(filepath.Join(link, "important.txt"))
+	if !errors.Is(err, ErrSymlinkEscape) {
+		t.Fatalf("经符号链接目录逃逸应被拒绝, 实际: %v", err)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Error("符号链接指向的文件不应被删除")
+	}
+}
+
+func TestGuard_RemovesSymlinkNotTarget(t *testing.T) {
+	root, outside := setupRoot(t)
+	target := filepath.Join(outside, "important.txt")
+	os.WriteFile(target, []byte("x"), 0644)
+
+	link := filepath.Join(root, "logs", "link.log")
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+
+	if err := NewGuard(root).Remove(link); err != nil {
+		t.Fatalf("根目录内的符号链接本身应允许删除: %v", err)
+	}
+	if _, err := os.Lstat(link); !os.IsNotExist(err) {
+		t.Error("符号链接应该被删除")
+	}
+	if _, err := os.Stat(target); err != nil {
+		t.Error("符号链接的目标不应被删除")
+	}
+}
+
+func TestGuard_RejectsDanglingSymlinkParent(t *testing.T) {
+	root, outside := setupRoot(t)
+	link := filepath.Join(root, "logs", "dangling")
+	if err := os.Symlink(filepath.Join(outside, "missing"), link); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+
+	err := NewGuard(root).Remove(filepath.Join(link, "file.log"))
+	if !errors.Is(err, ErrSymlinkEscape) {
+		t.Fatalf("悬空符号链接下的路径应被拒绝, 实际: %v", err)
+	}
+}
+
+func TestGuard_SymlinkedRoot(t *testing.T) {
+	root, _ := setupRoot(t)
+	alias := filepath.Join(filepath.Dir(root), "alias")
+	if err := os.Symlink(root, alias); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+	file := filepath.Join(alias, "logs", "main.log")
+	os.WriteFile(file, []byte("x"), 0644)
+
+	if err := NewGuard(alias).Check(file); err != nil {
+		t.Errorf("根目录本身是符号链接时，其中的文件应允许操作: %v", err)
+	}
+}
+
+func TestGuard_Rename(t *testing.T) {
+	root, outside := setupRoot(t)
+	src := filepath.Join(root, "logs", "main.log")
+	os.WriteFile(src, []byte("x"), 0644)
+
+	g := NewGuard(root)
+	if err := g.Rename(src, filepath.Join(outside, "main.log")); !errors.Is(err, ErrOutsideRoot) {
+		t.Fatalf("移动到根目录之外应被拒绝, 实际: %v", err)
+	}
+	if err := g.Rename(src, filepath.Join(root, "main.log")); err != nil {
+		t.Fatalf("根目录内的移动应允许: %v", err)
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	base := t.TempDir()
+
+	good := map[string]string{
+		"a.txt":     filepath.Join(base, "a.txt"),
+		"dir/b.txt": filepath.Join(base, "dir", "b.txt"),
+	}
+	for name, want := range good {
+		got, err := SafeJoin(base, name)
+		if err != nil || got != want {
+			t.Errorf("SafeJoin(%q) = %q, %v, want %q", name, got, err, want)
+		}
+	}
+
+	// 包含 .. 的条目即使不逃逸也拒绝
+	bad := []string{
+		"",
+		"../evil.txt",
+		"dir/../../evil.txt",
+		"/etc/passwd",
+		`..\evil.txt`,
+		`dir\..\..\evil.txt`,
+		`C:\Windows\evil.txt`,
+		"C:evil.txt",
+		"./dir/../c.txt",
+	}
+	for _, name := range bad {
+		if _, err := SafeJoin(base, name); !errors.Is(err, ErrUnsafeArchivePath) {
+			t.Errorf("SafeJoin(%q) 应被拒绝, 实际: %v", name, err)
+		}
+	}
+}
+
+func TestCheckExtractTarget_SymlinkedDir(t *testing.T) {
+	root, outside := setupRoot(t)
+	link := filepath.Join(root, "logs", "escape")
+	if err := os.Symlink(outside, link); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+
+	if err := CheckExtractTarget(root, filepath.Join(root, "logs", "ok.txt")); err != nil {
+		t.Errorf("普通目标应允许: %v", err)
+	}
+	if err := CheckExtractTarget(root, filepath.Join(link, "evil.txt")); !errors.Is(err, ErrSymlinkEscape) {
+		t.Errorf("经符号链接目录的目标应被拒绝, 实际: %v", err)
+	}
+	if err := CheckExtractTarget(root, link); !errors.Is(err, ErrSymlinkEscape) {
+		t.Errorf("覆盖已有的符号链接应被拒绝, 实际: %v", err)
+	}
+}
+
+func TestWalk_SkipsSymlinks(t *testing.T) {
+	root, outside := setupRoot(t)
+	os.WriteFile(filepath.Join(root, "a.log"), []byte("x"), 0644)
+	os.WriteFile(filepath.Join(outside, "secret"), []byte("x"), 0644)
+	if err := os.Symlink(outside, filepath.Join(root, "link")); err != nil {
+		t.Skip("无法创建符号链接")
+	}
+
+	var seen []string
+	Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			seen = append(seen, filepath.Base(path))
+		}
+		return nil
+	})
+	if len(seen) != 1 || seen[0] != "a.log" {
+		t.Errorf("应只遍历 a.log，实际 %v", seen)
+	}
+}
@@ -6,17 +6,33 @@ import (
 	"os"
 	"time"
 
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/tokens"
 	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
 )
 
 // ChatParser 解析 .chat 文件
-type ChatParser struct{}
+type ChatParser struct {
+	estimator tokens.Estimator // 为空时使用 tokens.Default()
+}
 
 // NewChatParser 创建新的解析器
 func NewChatParser() *ChatParser {
 	return &ChatParser{}
 }
 
+// SetEstimator 设置 token 估算器
+func (cp *ChatParser) SetEstimator(e tokens.Estimator) {
+	cp.estimator = e
+}
+
+// Estimator 返回使用的 token 估算器
+func (cp *ChatParser) Estimator() tokens.Estimator {
+	if cp.estimator != nil {
+		return cp.estimator
+	}
+	return tokens.Default()
+}
+
 // ParseChatFile 解析单个 chat 文件
 func (cp *ChatParser) ParseChatFile(path string) (*types.ChatFileInfo, error) {
 	// 获取文件信息
@@ -37,19 +53,28 @@ func (cp *ChatParser) ParseChatFile(path string) (*types.ChatFileInfo, error) {
 		return nil, fmt.Errorf("解析 JSON 失败: %v", err)
 	}
 
-	// 统计消息
+	return cp.buildInfo(&chatFile, path, fileInfo.Size(), fileInfo.ModTime()), nil
+}
+
+// buildInfo 统计消息数量和估算的 token 数
+func (cp *ChatParser) buildInfo(chatFile *types.ChatFile, path string, size int64, modTime time.Time) *types.ChatFileInfo {
 	humanCount, botCount, toolCount := cp.CountMessages(chatFile.Chat)
+	count := tokens.CountMessages(cp.Estimator(), chatFile.Chat)
 
 	return &types.ChatFileInfo{
 		Path:          path,
-		Size:          fileInfo.Size(),
-		ModTime:       fileInfo.ModTime(),
+		Size:          size,
+		ModTime:       modTime,
 		MessageCount:  len(chatFile.Chat),
 		HumanMessages: humanCount,
 		BotMessages:   botCount,
 		ToolMessages:  toolCount,
+		Tokens:        count.Total,
+		HumanTokens:   count.Human,
+		BotTokens:     count.Bot,
+		ToolTokens:    count.Tool,
 		Metadata:      chatFile.Metadata,
-	}, nil
+	}
 }
 
 // CountMessages 统计消息数量
@@ -85,16 +110,5 @@ func (cp *ChatParser) ParseChatFileFromBytes(data []byte, path string, size int6
 		return nil, fmt.Errorf("解析 JSON 失败: %v", err)
 	}
 
-	humanCount, botCount, toolCount := cp.CountMessages(chatFile.Chat)
-
-	return &types.ChatFileInfo{
-		Path:          path,
-		Size:          size,
-		ModTime:       modTime,
-		MessageCount:  len(chatFile.Chat),
-		HumanMessages: humanCount,
-		BotMessages:   botCount,
-		ToolMessages:  toolCount,
-		Metadata:      chatFile.Metadata,
-	}, nil
+	return cp.buildInfo(&chatFile, path, size, modTime), nil
 }
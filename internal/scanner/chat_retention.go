@@ -0,0 +1,144 @@
+package scanner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// FindConversations 按保留规则查找可清理的对话，并记录每个对话被选中的原因
+// 设置 KeepLast 时，每个工作区按元数据的 endTime 排序（缺失时使用修改时间），
+// 最新的 N 个对话始终保留，其余对话再按 AgeDays 和 SizeBytes 判断
+func (cs *ChatScanner) FindConversations(rule types.ChatRetention) ([]types.CleanableConversation, error) {
+	if cs.basePath == "" {
+		if _, err := cs.FindKiroAgentPath(); err != nil {
+			return nil, err
+		}
+	}
+
+	var cutoff time.Time
+	if rule.AgeDays > 0 {
+		cutoff = time.Now().AddDate(0, 0, -rule.AgeDays)
+	}
+
+	entries, err := os.ReadDir(cs.basePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var cleanable []types.CleanableConversation
+	for _, entry := range entries {
+		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") || specialDirs[entry.Name()] {
+			continue
+		}
+
+		chats := cs.workspaceChats(entry.Name(), rule.KeepLast > 0)
+		if rule.KeepLast > 0 {
+			rankChats(chats)
+		}
+		for _, chat := range chats {
+			if rule.KeepLast > 0 && chat.Rank <= rule.KeepLast {
+				continue
+			}
+			if chat.Reason = chatReason(chat, rule, cutoff); chat.Reason != "" {
+				cleanable = append(cleanable, chat)
+			}
+		}
+	}
+
+	return cleanable, nil
+}
+
+// workspaceChats 列出工作区中的对话文件，withEndTime 为 true 时读取元数据中的结束时间
+func (cs *ChatScanner) workspaceChats(workspace string, withEndTime bool) []types.CleanableConversation {
+	workspacePath := filepath.Join(cs.basePath, workspace)
+	chatFiles, err := os.ReadDir(workspacePath)
+	if err != nil {
+		return nil
+	}
+
+	var chats []types.CleanableConversation
+	for _, chatEntry := range chatFiles {
+		if chatEntry.IsDir() || !strings.HasSuffix(chatEntry.Name(), ".chat") {
+			continue
+		}
+
+		chatPath := filepath.Join(workspacePath, chatEntry.Name())
+		fileInfo, err := os.Stat(chatPath)
+		if err != nil {
+			continue
+		}
+
+		chat := types.CleanableConversation{
+			Path:      chatPath,
+			Size:      fileInfo.Size(),
+			ModTime:   fileInfo.ModTime(),
+			Workspace: workspace,
+			EndTime:   fileInfo.ModTime(),
+		}
+		if withEndTime {
+			if endTime := cs.chatEndTime(chatPath); !endTime.IsZero() {
+				chat.EndTime = endTime
+			}
+		}
+		chats = append(chats, chat)
+	}
+	return chats
+}
+
+// chatEndTime 读取对话元数据中的结束时间，无法读取时返回零值
+func (cs *ChatScanner) chatEndTime(path string) time.Time {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return time.Time{}
+	}
+	var chatFile struct {
+		Metadata types.ChatMetadata `json:"metadata"`
+	}
+	if err := json.Unmarshal(data, &chatFile); err != nil {
+		return time.Time{}
+	}
+	_, endTime := cs.parser.ExtractMetadata(chatFile.Metadata)
+	return endTime
+}
+
+// rankChats 按结束时间从新到旧排名，结束时间相同时依次比较修改时间和路径
+func rankChats(chats []types.CleanableConversation) {
+	sort.SliceStable(chats, func(i, j int) bool {
+		a, b := chats[i], chats[j]
+		if !a.EndTime.Equal(b.EndTime) {
+			return a.EndTime.After(b.EndTime)
+		}
+		if !a.ModTime.Equal(b.ModTime) {
+			return a.ModTime.After(b.ModTime)
+		}
+		return a.Path < b.Path
+	})
+	for i := range chats {
+		chats[i].Rank = i + 1
+	}
+}
+
+// chatReason 返回对话被选中清理的原因，不应清理时返回空
+func chatReason(chat types.CleanableConversation, rule types.ChatRetention, cutoff time.Time) string {
+	switch {
+	case rule.AgeDays == 0:
+		if rule.KeepLast > 0 {
+			return types.ChatReasonBeyondLast
+		}
+		return types.ChatReasonAll
+	case chat.ModTime.Before(cutoff):
+		if rule.KeepLast > 0 {
+			return types.ChatReasonBeyondLast
+		}
+		return types.ChatReasonOld
+	case rule.SizeBytes > 0 && chat.Size > rule.SizeBytes:
+		return types.ChatReasonLarge
+	}
+	return ""
+}
@@ -0,0 +1,119 @@
+package scanner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// writeChat 创建带 endTime 的对话文件并设置修改时间
+func writeChat(t *testing.T, dir, name string, endTime, modTime time.Time) string {
+	t.Helper()
+	content := `{"chat":[]}`
+	if !endTime.IsZero() {
+		content = fmt.Sprintf(`{"chat":[],"metadata":{"endTime":%d}}`, endTime.UnixMilli())
+	}
+	path := createTestFile(t, dir, name, []byte(content))
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("修改文件时间失败: %v", err)
+	}
+	return path
+}
+
+func TestFindConversations_KeepLastPerWorkspace(t *testing.T) {
+	tempDir := createTempDir(t, "test-chat-keep-last")
+	now := time.Now()
+	ws1 := filepath.Join(tempDir, "ws1")
+	ws2 := filepath.Join(tempDir, "ws2")
+
+	// 修改时间与 endTime 顺序相反，排名应以 endTime 为准
+	for i := 0; i < 4; i++ {
+		writeChat(t, ws1, fmt.Sprintf("c%d.chat", i), now.AddDate(0, 0, -10-i), now.AddDate(0, 0, -20+i))
+	}
+	writeChat(t, ws2, "only.chat", time.Time{}, now.AddDate(0, 0, -30))
+
+	scanner := NewChatScanner()
+	scanner.SetBasePath(tempDir)
+
+	chats, err := scanner.FindConversations(types.ChatRetention{KeepLast: 2})
+	if err != nil {
+		t.Fatalf("查找对话失败: %v", err)
+	}
+	if len(chats) != 2 {
+		t.Fatalf("应该找到 2 个对话，实际找到 %d 个", len(chats))
+	}
+	for _, chat := range chats {
+		name := filepath.Base(chat.Path)
+		if name != "c2.chat" && name != "c3.chat" {
+			t.Errorf("应保留 endTime 最新的两个对话，却选中了 %s", name)
+		}
+		if chat.Reason != types.ChatReasonBeyondLast || chat.Workspace != "ws1" || chat.Rank < 3 {
+			t.Errorf("对话信息不正确: %+v", chat)
+		}
+	}
+}
+
+func TestFindConversations_KeepLastWithAge(t *testing.T) {
+	tempDir := createTempDir(t, "test-chat-keep-last-age")
+	now := time.Now()
+	ws := filepath.Join(tempDir, "ws")
+
+	// 最新的 1 个保留；其余中 3 天内修改的也保留
+	writeChat(t, ws, "newest.chat", now, now)
+	writeChat(t, ws, "recent.chat", now.Add(-time.Hour), now.AddDate(0, 0, -3))
+	writeChat(t, ws, "old.chat", now.Add(-2*time.Hour), now.AddDate(0, 0, -10))
+
+	scanner := NewChatScanner()
+	scanner.SetBasePath(tempDir)
+
+	chats, err := scanner.FindConversations(types.ChatRetention{KeepLast: 1, AgeDays: 7})
+	if err != nil {
+		t.Fatalf("查找对话失败: %v", err)
+	}
+	if len(chats) != 1 || filepath.Base(chats[0].Path) != "old.chat" {
+		t.Fatalf("应只选中 old.chat: %+v", chats)
+	}
+	if chats[0].Rank != 3 || chats[0].Reason != types.ChatReasonBeyondLast {
+		t.Errorf("排名或原因不正确: %+v", chats[0])
+	}
+
+	// 不设置 KeepLast 时保持原来的原因
+	chats, _ = scanner.FindConversations(types.ChatRetention{AgeDays: 7})
+	if len(chats) != 1 || chats[0].Reason != types.ChatReasonOld {
+		t.Errorf("只按天数时原因应该是 old: %+v", chats)
+	}
+}
+
+func TestParseConversations(t *testing.T) {
+	tempDir := createTempDir(t, "test-chat-parse")
+	ws := filepath.Join(tempDir, "ws1")
+	createTestFile(t, ws, "a.chat", []byte(`{"chat":[{"role":"human","content":"hi"},{"role":"tool","content":"x"}],"metadata":{"modelId":"claude","workflow":"spec"}}`))
+	createTestFile(t, ws, "broken.chat", []byte(`{not json`))
+
+	scanner := NewChatScanner()
+	scanner.SetBasePath(tempDir)
+
+	chats, err := scanner.ParseConversations()
+	if err != nil {
+		t.Fatalf("解析对话失败: %v", err)
+	}
+	if len(chats) != 2 {
+		t.Fatalf("应该返回 2 个对话，实际 %d 个", len(chats))
+	}
+	for _, chat := range chats {
+		switch filepath.Base(chat.Path) {
+		case "a.chat":
+			if chat.Metadata.ModelID != "claude" || chat.Metadata.Workflow != "spec" || chat.ToolMessages != 1 {
+				t.Errorf("解析结果不正确: %+v", chat)
+			}
+		case "broken.chat":
+			if chat.Size != int64(len(`{not json`)) || chat.MessageCount != 0 {
+				t.Errorf("无法解析的对话应只保留文件信息: %+v", chat)
+			}
+		}
+	}
+}
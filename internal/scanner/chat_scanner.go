@@ -6,8 +6,8 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
-	"time"
 
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
 	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
 )
 
@@ -39,6 +39,16 @@ var specialDirs = map[string]bool{
 func (cs *ChatScanner) FindKiroAgentPath() (string, error) {
 	var basePath string
 
+	// 通过 --install 选择了安装时只使用该安装
+	if install, ok := storage.SelectedInstall(); ok {
+		basePath = agentDir(install.Path)
+		if _, err := os.Stat(basePath); err != nil {
+			return "", fmt.Errorf("Kiro 代理存储路径不存在: %s", basePath)
+		}
+		cs.basePath = basePath
+		return basePath, nil
+	}
+
 	switch runtime.GOOS {
 	case "darwin": // macOS
 		home := os.Getenv("HOME")
@@ -72,8 +82,14 @@ func (cs *ChatScanner) FindKiroAgentPath() (string, error) {
 		basePath = filepath.Join(home, ".config", "kiro", "User", "globalStorage", "kiro.kiroagent")
 	}
 
-	// 检查路径是否存在
+	// 默认位置不存在时使用其他发现的安装（渠道版、Flatpak、--user-data-dir 等）
 	if _, err := os.Stat(basePath); os.IsNotExist(err) {
+		for _, install := range storage.DiscoverInstalls() {
+			if _, err := os.Stat(agentDir(install.Path)); err == nil {
+				cs.basePath = agentDir(install.Path)
+				return cs.basePath, nil
+			}
+		}
 		return "", fmt.Errorf("Kiro 代理存储路径不存在: %s", basePath)
 	}
 
@@ -81,6 +97,11 @@ func (cs *ChatScanner) FindKiroAgentPath() (string, error) {
 	return basePath, nil
 }
 
+// agentDir 返回安装目录下的 kiro.kiroagent 目录
+func agentDir(installPath string) string {
+	return filepath.Join(installPath, "User", "globalStorage", "kiro.kiroagent")
+}
+
 // SetBasePath 设置基础路径（用于测试）
 func (cs *ChatScanner) SetBasePath(path string) {
 	cs.basePath = path
@@ -187,6 +208,7 @@ func (cs *ChatScanner) scanSingleWorkspaceWithProgress(workspaceID, path string,
 		stats.ConversationCount++
 		stats.TotalMessages += chatInfo.MessageCount
 		stats.TotalSize += chatInfo.Size
+		stats.TotalTokens += chatInfo.Tokens
 
 		if chatInfo.ModTime.After(stats.LastActivity) {
 			stats.LastActivity = chatInfo.ModTime
@@ -225,6 +247,7 @@ func (cs *ChatScanner) GetConversationStats() (*types.ConversationStats, error)
 		stats.TotalConversations += ws.ConversationCount
 		stats.TotalMessages += ws.TotalMessages
 		stats.TotalSize += ws.TotalSize
+		stats.TotalTokens += ws.TotalTokens
 
 		if ws.LastActivity.After(stats.LastActivity) {
 			stats.LastActivity = ws.LastActivity
@@ -287,82 +310,29 @@ func (cs *ChatScanner) countMessageTypes(stats *types.ConversationStats) {
 	}
 }
 
-// FindCleanableConversations 查找可清理的对话
-// ageDays=0 表示返回所有会话，sizeBytes=0 表示不按大小过滤
-func (cs *ChatScanner) FindCleanableConversations(ageDays int, sizeBytes int64) ([]types.CleanableConversation, error) {
-	if cs.basePath == "" {
-		if _, err := cs.FindKiroAgentPath(); err != nil {
-			return nil, err
-		}
-	}
-
-	var cleanable []types.CleanableConversation
-	var cutoffTime time.Time
-	if ageDays > 0 {
-		cutoffTime = time.Now().AddDate(0, 0, -ageDays)
-	}
-
-	entries, err := os.ReadDir(cs.basePath)
+// ParseConversations 解析所有工作区的对话文件
+// 无法解析的文件只包含路径、大小和修改时间，仍计入存储统计
+func (cs *ChatScanner) ParseConversations() ([]types.ChatFileInfo, error) {
+	all, err := cs.FindConversations(types.ChatRetention{})
 	if err != nil {
 		return nil, err
 	}
 
-	for _, entry := range entries {
-		if !entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
-			continue
-		}
-
-		// 跳过特殊目录
-		if specialDirs[entry.Name()] {
-			continue
-		}
-
-		workspacePath := filepath.Join(cs.basePath, entry.Name())
-		chatFiles, err := os.ReadDir(workspacePath)
+	chats := make([]types.ChatFileInfo, 0, len(all))
+	for _, c := range all {
+		info, err := cs.parser.ParseChatFile(c.Path)
 		if err != nil {
-			continue
-		}
-
-		for _, chatEntry := range chatFiles {
-			if chatEntry.IsDir() || !strings.HasSuffix(chatEntry.Name(), ".chat") {
-				continue
-			}
-
-			chatPath := filepath.Join(workspacePath, chatEntry.Name())
-			fileInfo, err := os.Stat(chatPath)
-			if err != nil {
-				continue
-			}
-
-			// ageDays=0 表示返回所有会话
-			if ageDays == 0 {
-				cleanable = append(cleanable, types.CleanableConversation{
-					Path:    chatPath,
-					Size:    fileInfo.Size(),
-					ModTime: fileInfo.ModTime(),
-					Reason:  "all",
-				})
-			} else if fileInfo.ModTime().Before(cutoffTime) {
-				// 检查是否符合年龄条件
-				cleanable = append(cleanable, types.CleanableConversation{
-					Path:    chatPath,
-					Size:    fileInfo.Size(),
-					ModTime: fileInfo.ModTime(),
-					Reason:  "old",
-				})
-			} else if sizeBytes > 0 && fileInfo.Size() > sizeBytes {
-				// 检查是否符合大小条件
-				cleanable = append(cleanable, types.CleanableConversation{
-					Path:    chatPath,
-					Size:    fileInfo.Size(),
-					ModTime: fileInfo.ModTime(),
-					Reason:  "large",
-				})
-			}
+			info = &types.ChatFileInfo{Path: c.Path, Size: c.Size, ModTime: c.ModTime}
 		}
+		chats = append(chats, *info)
 	}
+	return chats, nil
+}
 
-	return cleanable, nil
+// FindCleanableConversations 查找可清理的对话
+// ageDays=0 表示返回所有会话，sizeBytes=0 表示不按大小过滤
+func (cs *ChatScanner) FindCleanableConversations(ageDays int, sizeBytes int64) ([]types.CleanableConversation, error) {
+	return cs.FindConversations(types.ChatRetention{AgeDays: ageDays, SizeBytes: sizeBytes})
 }
 
 // CalculateSpaceSavings 计算潜在节省空间
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/database"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/orphans"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
 	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
 	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
 )
@@ -23,6 +25,7 @@ type FileScanner struct {
 	detector    *storage.StorageDetector
 	pathFinder  *storage.PathFinder
 	kiroPaths   []string
+	noArtifacts bool // 不做快照和差异内容的引用分析
 }
 
 // NewFileScanner 创建新的文件扫描器
@@ -33,6 +36,12 @@ func NewFileScanner() *FileScanner {
 	}
 }
 
+// SetArtifactAnalysis 设置是否按引用关系分类快照和差异内容（默认开启）
+// 分析需要读取所有对话和会话文件，只需要文件列表的调用方可以关闭
+func (fs *FileScanner) SetArtifactAnalysis(enabled bool) {
+	fs.noArtifacts = !enabled
+}
+
 // Scan 扫描文件（向后兼容）
 func (fs *FileScanner) Scan() ([]types.FileInfo, error) {
 	return fs.ScanWithProgress(nil)
@@ -87,9 +96,12 @@ func (fs *FileScanner) scanPathWithProgress(path string, progress *types.ScanPro
 	if err != nil {
 		return nil, err
 	}
+	if !fs.noArtifacts {
+		classifyArtifacts(path, fileTypes)
+	}
 	
 	// 遍历目录
-	err = filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+	err = safety.Walk(path, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // 跳过无法访问的文件
 		}
@@ -143,6 +155,31 @@ func (fs *FileScanner) scanPathWithProgress(path string, progress *types.ScanPro
 	return files, err
 }
 
+// classifyArtifacts 按引用关系重新分类 kiro.kiroagent 下的快照和差异内容：
+// 孤立的标记为 TypeOrphan，仍被引用的作为用户数据保留。分析失败时保持按路径的分类
+func classifyArtifacts(path string, fileTypes map[string]types.FileType) {
+	agentPath := filepath.Join(path, "User", "globalStorage", "kiro.kiroagent")
+	if _, err := os.Stat(agentPath); err != nil {
+		return
+	}
+	report, err := orphans.Analyze(agentPath, orphans.Options{})
+	if err != nil || len(report.Artifacts) == 0 {
+		return
+	}
+	for filePath, fileType := range fileTypes {
+		if fileType != types.TypeCache {
+			continue
+		}
+		if artifact, ok := report.Find(filePath); ok {
+			if artifact.Orphaned() {
+				fileTypes[filePath] = types.TypeOrphan
+			} else {
+				fileTypes[filePath] = types.TypeDatabase
+			}
+		}
+	}
+}
+
 // fileTypeToString 将文件类型转换为字符串
 func fileTypeToString(ft types.FileType) string {
 	switch ft {
@@ -156,6 +193,10 @@ func fileTypeToString(ft types.FileType) string {
 		return "index"
 	case types.TypeBackup:
 		return "history"
+	case types.TypeCrash:
+		return "crash"
+	case types.TypeOrphan:
+		return "orphan"
 	case types.TypeDatabase:
 		return "database"
 	case types.TypeConfig:
@@ -224,6 +265,10 @@ func getFileTypeName(fileType types.FileType) string {
 		return "图片"
 	case types.TypeBackup:
 		return "备份"
+	case types.TypeCrash:
+		return "崩溃报告"
+	case types.TypeOrphan:
+		return "孤立产物"
 	default:
 		return "其他"
 	}
@@ -179,10 +179,10 @@ func TestFileTypeDetection_Temp(t *testing.T) {
 		t.Errorf("file.temp 应该是 TypeTemp，实际是 %v", ft)
 	}
 	
-	// 检查 crashpad 目录
+	// 检查 crashpad 目录（崩溃报告单独分类，不再视为临时文件）
 	crashPath := filepath.Join(tempDir, "crashpad", "crash")
-	if ft, ok := fileTypes[crashPath]; !ok || ft != types.TypeTemp {
-		t.Errorf("crashpad/crash 应该是 TypeTemp，实际是 %v", ft)
+	if ft, ok := fileTypes[crashPath]; !ok || ft != types.TypeCrash {
+		t.Errorf("crashpad/crash 应该是 TypeCrash，实际是 %v", ft)
 	}
 }
 
@@ -83,6 +83,7 @@ func TestFileTypeToString(t *testing.T) {
 		{types.TypeBackup, "history"},
 		{types.TypeDatabase, "database"},
 		{types.TypeConfig, "config"},
+		{types.TypeCrash, "crash"},
 		{types.TypeUnknown, "other"},
 	}
 	
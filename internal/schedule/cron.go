@@ -0,0 +1,165 @@
+package schedule
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cronExpr 各执行频率对应的 cron 表达式
+var cronExpr = map[string]string{
+	CadenceHourly:  "0 * * * *",
+	CadenceDaily:   "0 3 * * *",
+	CadenceWeekly:  "0 3 * * 1",
+	CadenceMonthly: "0 3 1 * *",
+}
+
+// Cron crontab 后端
+type Cron struct {
+	run Runner
+}
+
+// NewCron 创建 crontab 后端
+func NewCron(run Runner) *Cron {
+	return &Cron{run: run}
+}
+
+// Name 返回后端名称
+func (c *Cron) Name() string {
+	return "cron"
+}
+
+// CronLine 生成 crontab 条目（带标记注释，便于识别和删除）
+// cron 会把未转义的 % 换成换行，命令中的 % 写作 \%
+func CronLine(spec Spec) string {
+	command := strings.ReplaceAll(spec.Command(), "%", `\%`)
+	return fmt.Sprintf("%s %s >/dev/null 2>&1 %s %s", cronExpr[spec.Cadence], command, CronMarker, spec.Cadence)
+}
+
+// MergeCrontab 用新条目替换 crontab 中已有的本工具条目，保留其他条目
+func MergeCrontab(existing, line string) string {
+	stripped := StripCrontab(existing)
+	if stripped != "" && !strings.HasSuffix(stripped, "\n") {
+		stripped += "\n"
+	}
+	return stripped + line + "\n"
+}
+
+// StripCrontab 删除 crontab 中本工具的条目
+func StripCrontab(existing string) string {
+	var kept []string
+	for _, line := range strings.Split(existing, "\n") {
+		if strings.Contains(line, CronMarker) {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n")
+}
+
+// findCronLine 找到 crontab 中本工具的条目
+func findCronLine(crontab string) string {
+	for _, line := range strings.Split(crontab, "\n") {
+		if strings.Contains(line, CronMarker) {
+			return line
+		}
+	}
+	return ""
+}
+
+// readCrontab 读取当前用户的 crontab，没有 crontab 时返回空
+// 其他错误必须返回：把读取失败当作空 crontab 再写回会清空用户已有的条目
+func (c *Cron) readCrontab() (string, error) {
+	out, err := c.run("", "crontab", "-l")
+	if err != nil {
+		if strings.Contains(err.Error(), "no crontab for") {
+			return "", nil
+		}
+		return "", fmt.Errorf("读取 crontab 失败: %v", err)
+	}
+	return out, nil
+}
+
+// Install 添加或替换 crontab 条目
+func (c *Cron) Install(spec Spec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	existing, err := c.readCrontab()
+	if err != nil {
+		return err
+	}
+	_, err = c.run(MergeCrontab(existing, CronLine(spec)), "crontab", "-")
+	return err
+}
+
+// Remove 删除 crontab 条目
+func (c *Cron) Remove() error {
+	existing, err := c.readCrontab()
+	if err != nil {
+		return err
+	}
+	if findCronLine(existing) == "" {
+		return nil
+	}
+	_, err = c.run(StripCrontab(existing), "crontab", "-")
+	return err
+}
+
+// Status 读取 crontab 条目并计算下次执行时间
+func (c *Cron) Status() (*Status, error) {
+	status := &Status{Backend: c.Name()}
+	crontab, err := c.readCrontab()
+	if err != nil {
+		return nil, err
+	}
+	line := findCronLine(crontab)
+	if line == "" {
+		return status, nil
+	}
+	status.Installed = true
+
+	fields := strings.Fields(line)
+	if len(fields) >= 6 {
+		status.Schedule = strings.Join(fields[:5], " ")
+		command := strings.Join(fields[5:], " ")
+		if i := strings.Index(command, " >/dev/null"); i >= 0 {
+			command = command[:i]
+		}
+		status.Command = strings.ReplaceAll(command, `\%`, "%")
+	}
+	for cadence, expr := range cronExpr {
+		if expr == status.Schedule {
+			status.NextRun = NextRun(cadence, time.Now())
+		}
+	}
+	return status, nil
+}
+
+// NextRun 计算固定执行频率的下次执行时间（与 cronExpr 一致）
+func NextRun(cadence string, now time.Time) time.Time {
+	at3 := time.Date(now.Year(), now.Month(), now.Day(), 3, 0, 0, 0, now.Location())
+	switch cadence {
+	case CadenceHourly:
+		return now.Truncate(time.Hour).Add(time.Hour)
+	case CadenceDaily:
+		if at3.After(now) {
+			return at3
+		}
+		return at3.AddDate(0, 0, 1)
+	case CadenceWeekly:
+		days := (int(time.Monday) - int(now.Weekday()) + 7) % 7
+		next := at3.AddDate(0, 0, days)
+		if !next.After(now) {
+			next = next.AddDate(0, 0, 7)
+		}
+		return next
+	case CadenceMonthly:
+		next := time.Date(now.Year(), now.Month(), 1, 3, 0, 0, 0, now.Location())
+		if !next.After(now) {
+			next = next.AddDate(0, 1, 0)
+		}
+		return next
+	}
+	return time.Time{}
+}
@@ -0,0 +1,113 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// UnitName systemd 单元名（不含扩展名）
+const UnitName = "kiro-cleaner"
+
+// CronMarker crontab 中标记本工具条目的注释
+const CronMarker = "# kiro-cleaner schedule"
+
+// 执行频率
+const (
+	CadenceHourly  = "hourly"
+	CadenceDaily   = "daily"
+	CadenceWeekly  = "weekly"
+	CadenceMonthly = "monthly"
+)
+
+// Cadences 支持的执行频率
+var Cadences = []string{CadenceHourly, CadenceDaily, CadenceWeekly, CadenceMonthly}
+
+// Spec 计划任务定义
+type Spec struct {
+	Binary  string   // kiro-cleaner 可执行文件的绝对路径
+	Args    []string // 参数，如 ["auto"]
+	Cadence string   // 执行频率
+}
+
+// Validate 检查计划任务定义
+func (s Spec) Validate() error {
+	if s.Binary == "" || !filepath.IsAbs(s.Binary) {
+		return fmt.Errorf("可执行文件路径必须是绝对路径: %q", s.Binary)
+	}
+	for _, c := range Cadences {
+		if s.Cadence == c {
+			return nil
+		}
+	}
+	return fmt.Errorf("不支持的执行频率: %s（可选 %s）", s.Cadence, strings.Join(Cadences, ", "))
+}
+
+// Command 返回完整的命令行
+func (s Spec) Command() string {
+	parts := []string{quoteArg(s.Binary)}
+	for _, a := range s.Args {
+		parts = append(parts, quoteArg(a))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Status 计划任务状态
+type Status struct {
+	Backend   string    // systemd 或 cron
+	Installed bool      // 是否已安装
+	Schedule  string    // OnCalendar 表达式或 cron 表达式
+	Command   string    // 执行的命令
+	NextRun   time.Time // 下次执行时间（未知时为零值）
+}
+
+// Backend 计划任务后端
+type Backend interface {
+	Name() string
+	Install(spec Spec) error
+	Remove() error
+	Status() (*Status, error)
+}
+
+// Runner 执行外部命令，返回标准输出（便于测试替换）
+type Runner func(stdin string, name string, args ...string) (string, error)
+
+// ExecRunner 使用 os/exec 执行命令
+func ExecRunner(stdin string, name string, args ...string) (string, error) {
+	cmd := exec.Command(name, args...)
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	out, err := cmd.Output()
+	if err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok && len(exitErr.Stderr) > 0 {
+			return string(out), fmt.Errorf("%s %s: %v: %s", name, strings.Join(args, " "), err, strings.TrimSpace(string(exitErr.Stderr)))
+		}
+		return string(out), fmt.Errorf("%s %s: %v", name, strings.Join(args, " "), err)
+	}
+	return string(out), nil
+}
+
+// Detect 选择可用的后端：优先 systemd 用户定时器，否则使用 crontab
+func Detect() (Backend, error) {
+	if _, err := os.Stat("/run/systemd/system"); err == nil {
+		if _, err := exec.LookPath("systemctl"); err == nil {
+			return NewSystemd(SystemdUserDir(), ExecRunner), nil
+		}
+	}
+	if _, err := exec.LookPath("crontab"); err == nil {
+		return NewCron(ExecRunner), nil
+	}
+	return nil, fmt.Errorf("未找到 systemd 或 crontab，无法安装计划任务")
+}
+
+// quoteArg 必要时为参数加单引号
+func quoteArg(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\$`;&|<>()*?[]#~%") {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
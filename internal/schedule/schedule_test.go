@@ -0,0 +1,234 @@
+package schedule
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeRunner 记录执行的命令，模拟 crontab 的读写
+type fakeRunner struct {
+	calls   []string
+	crontab string
+	noTab   bool
+	readErr error // crontab -l 的其他错误
+}
+
+func (f *fakeRunner) run(stdin string, name string, args ...string) (string, error) {
+	call := name + " " + strings.Join(args, " ")
+	f.calls = append(f.calls, call)
+	switch call {
+	case "crontab -l":
+		if f.readErr != nil {
+			return "", f.readErr
+		}
+		if f.noTab {
+			return "", errors.New("crontab -l: exit status 1: no crontab for me")
+		}
+		return f.crontab, nil
+	case "crontab -":
+		f.crontab = stdin
+		f.noTab = false
+	}
+	if strings.Contains(call, "NextElapseUSecRealtime") {
+		return "@1700000000\n", nil
+	}
+	return "", nil
+}
+
+func testSpec() Spec {
+	return Spec{Binary: "/usr/local/bin/kiro-cleaner", Args: []string{"auto"}, Cadence: CadenceDaily}
+}
+
+func TestSpecValidate(t *testing.T) {
+	if err := testSpec().Validate(); err != nil {
+		t.Fatalf("有效定义不应报错: %v", err)
+	}
+	bad := testSpec()
+	bad.Cadence = "sometimes"
+	if err := bad.Validate(); err == nil {
+		t.Error("未知频率应报错")
+	}
+	bad = testSpec()
+	bad.Binary = "kiro-cleaner"
+	if err := bad.Validate(); err == nil {
+		t.Error("相对路径应报错")
+	}
+}
+
+func TestSpecCommandQuotes(t *testing.T) {
+	spec := Spec{Binary: "/home/me/my tools/kiro-cleaner", Args: []string{"auto", "--profile", "it's"}}
+	got := spec.Command()
+	want := `'/home/me/my tools/kiro-cleaner' auto --profile 'it'\''s'`
+	if got != want {
+		t.Errorf("命令行 = %q, 期望 %q", got, want)
+	}
+}
+
+func TestServiceUnit(t *testing.T) {
+	unit := ServiceUnit(testSpec())
+	if unitValue(unit, "ExecStart") != "/usr/local/bin/kiro-cleaner auto" {
+		t.Errorf("ExecStart 不正确:\n%s", unit)
+	}
+	if unitValue(unit, "SuccessExitStatus") != "2 3" {
+		t.Error("auto 的退出码 2/3 应视为成功")
+	}
+}
+
+func TestTimerUnit(t *testing.T) {
+	spec := testSpec()
+	spec.Cadence = CadenceWeekly
+	unit := TimerUnit(spec)
+	if unitValue(unit, "OnCalendar") != "Mon *-*-* 03:00:00" {
+		t.Errorf("OnCalendar 不正确:\n%s", unit)
+	}
+	if unitValue(unit, "Persistent") != "true" {
+		t.Error("错过的运行应在开机后补跑")
+	}
+}
+
+func TestSystemdInstallRemove(t *testing.T) {
+	dir := t.TempDir()
+	runner := &fakeRunner{}
+	sd := NewSystemd(dir, runner.run)
+
+	if err := sd.Install(testSpec()); err != nil {
+		t.Fatalf("安装失败: %v", err)
+	}
+	for _, path := range []string{sd.ServicePath(), sd.TimerPath()} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("单元文件未写入: %s", path)
+		}
+	}
+	if runner.calls[len(runner.calls)-1] != "systemctl --user enable --now kiro-cleaner.timer" {
+		t.Errorf("应启用定时器, 实际调用: %v", runner.calls)
+	}
+
+	status, err := sd.Status()
+	if err != nil {
+		t.Fatalf("读取状态失败: %v", err)
+	}
+	if !status.Installed || status.Schedule != "*-*-* 03:00:00" {
+		t.Errorf("状态不正确: %+v", status)
+	}
+	if !status.NextRun.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("下次运行时间 = %v", status.NextRun)
+	}
+
+	if err := sd.Remove(); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, UnitName+".timer")); !os.IsNotExist(err) {
+		t.Error("timer 单元应被删除")
+	}
+	status, _ = sd.Status()
+	if status.Installed {
+		t.Error("删除后不应显示已安装")
+	}
+}
+
+func TestMergeCrontabKeepsOtherEntries(t *testing.T) {
+	existing := "MAILTO=me\n0 1 * * * backup.sh\n0 * * * * /old/kiro-cleaner auto " + CronMarker + " hourly\n"
+	line := CronLine(testSpec())
+	merged := MergeCrontab(existing, line)
+
+	if !strings.Contains(merged, "0 1 * * * backup.sh") || !strings.Contains(merged, "MAILTO=me") {
+		t.Errorf("其他条目应保留:\n%s", merged)
+	}
+	if strings.Contains(merged, "/old/kiro-cleaner") {
+		t.Errorf("旧条目应被替换:\n%s", merged)
+	}
+	if strings.Count(merged, CronMarker) != 1 {
+		t.Errorf("应只有一条本工具的条目:\n%s", merged)
+	}
+	if StripCrontab(merged) != "MAILTO=me\n0 1 * * * backup.sh\n" {
+		t.Errorf("删除后内容不正确: %q", StripCrontab(merged))
+	}
+}
+
+func TestCronInstallStatusRemove(t *testing.T) {
+	runner := &fakeRunner{noTab: true}
+	c := NewCron(runner.run)
+
+	if err := c.Install(testSpec()); err != nil {
+		t.Fatalf("安装失败: %v", err)
+	}
+	status, err := c.Status()
+	if err != nil {
+		t.Fatalf("读取状态失败: %v", err)
+	}
+	if !status.Installed || status.Schedule != "0 3 * * *" {
+		t.Errorf("状态不正确: %+v", status)
+	}
+	if status.Command != "/usr/local/bin/kiro-cleaner auto" {
+		t.Errorf("命令 = %q", status.Command)
+	}
+	if status.NextRun.IsZero() {
+		t.Error("应计算下次运行时间")
+	}
+
+	if err := c.Remove(); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if strings.Contains(runner.crontab, CronMarker) {
+		t.Error("条目应被删除")
+	}
+}
+
+func TestCronKeepsCrontabWhenReadFails(t *testing.T) {
+	existing := "0 1 * * * backup.sh\n"
+	runner := &fakeRunner{crontab: existing, readErr: errors.New("crontab -l: exit status 1: cannot connect")}
+	c := NewCron(runner.run)
+
+	if err := c.Install(testSpec()); err == nil {
+		t.Error("读取失败时安装应报错")
+	}
+	if err := c.Remove(); err == nil {
+		t.Error("读取失败时删除应报错")
+	}
+	if runner.crontab != existing {
+		t.Errorf("读取失败时不应改写 crontab: %q", runner.crontab)
+	}
+}
+
+func TestCronLineEscapesPercent(t *testing.T) {
+	spec := testSpec()
+	spec.Args = []string{"auto", "--profile", "50%"}
+	line := CronLine(spec)
+	if !strings.Contains(line, `'50\%'`) {
+		t.Errorf("%% 应转义: %q", line)
+	}
+
+	runner := &fakeRunner{crontab: line + "\n"}
+	status, err := NewCron(runner.run).Status()
+	if err != nil {
+		t.Fatalf("读取状态失败: %v", err)
+	}
+	if !strings.HasSuffix(status.Command, "'50%'") {
+		t.Errorf("状态中的命令应还原 %%: %q", status.Command)
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	// 2024-01-03 是周三
+	now := time.Date(2024, 1, 3, 10, 30, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		CadenceHourly:  time.Date(2024, 1, 3, 11, 0, 0, 0, time.UTC),
+		CadenceDaily:   time.Date(2024, 1, 4, 3, 0, 0, 0, time.UTC),
+		CadenceWeekly:  time.Date(2024, 1, 8, 3, 0, 0, 0, time.UTC),
+		CadenceMonthly: time.Date(2024, 2, 1, 3, 0, 0, 0, time.UTC),
+	}
+	for cadence, want := range cases {
+		if got := NextRun(cadence, now); !got.Equal(want) {
+			t.Errorf("%s: 下次运行 = %v, 期望 %v", cadence, got, want)
+		}
+	}
+
+	early := time.Date(2024, 1, 1, 1, 0, 0, 0, time.UTC) // 周一凌晨
+	if got := NextRun(CadenceWeekly, early); !got.Equal(time.Date(2024, 1, 1, 3, 0, 0, 0, time.UTC)) {
+		t.Errorf("当天 3 点前应在当天运行: %v", got)
+	}
+}
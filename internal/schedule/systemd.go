@@ -0,0 +1,162 @@
+package schedule
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// onCalendar 各执行频率对应的 systemd OnCalendar 表达式
+var onCalendar = map[string]string{
+	CadenceHourly:  "hourly",
+	CadenceDaily:   "*-*-* 03:00:00",
+	CadenceWeekly:  "Mon *-*-* 03:00:00",
+	CadenceMonthly: "*-*-01 03:00:00",
+}
+
+// Systemd systemd 用户定时器后端
+type Systemd struct {
+	dir string
+	run Runner
+}
+
+// SystemdUserDir 返回 systemd 用户单元目录
+func SystemdUserDir() string {
+	if dir := os.Getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "systemd", "user")
+	}
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".config", "systemd", "user")
+}
+
+// NewSystemd 创建 systemd 后端
+func NewSystemd(dir string, run Runner) *Systemd {
+	return &Systemd{dir: dir, run: run}
+}
+
+// Name 返回后端名称
+func (s *Systemd) Name() string {
+	return "systemd"
+}
+
+// ServicePath 返回 service 单元路径
+func (s *Systemd) ServicePath() string {
+	return filepath.Join(s.dir, UnitName+".service")
+}
+
+// TimerPath 返回 timer 单元路径
+func (s *Systemd) TimerPath() string {
+	return filepath.Join(s.dir, UnitName+".timer")
+}
+
+// ServiceUnit 生成 service 单元内容
+// auto 用退出码 2/3 表示“已清理”和“Kiro 正在运行”，不应被视为失败
+func ServiceUnit(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	b.WriteString("Description=Kiro Cleaner scheduled cleanup\n\n")
+	b.WriteString("[Service]\n")
+	b.WriteString("Type=oneshot\n")
+	fmt.Fprintf(&b, "ExecStart=%s\n", spec.Command())
+	b.WriteString("SuccessExitStatus=2 3\n")
+	b.WriteString("Nice=10\n")
+	b.WriteString("IOSchedulingClass=idle\n")
+	return b.String()
+}
+
+// TimerUnit 生成 timer 单元内容
+func TimerUnit(spec Spec) string {
+	var b strings.Builder
+	b.WriteString("[Unit]\n")
+	fmt.Fprintf(&b, "Description=Run Kiro Cleaner %s\n\n", spec.Cadence)
+	b.WriteString("[Timer]\n")
+	fmt.Fprintf(&b, "OnCalendar=%s\n", onCalendar[spec.Cadence])
+	b.WriteString("Persistent=true\n")
+	b.WriteString("RandomizedDelaySec=15m\n\n")
+	b.WriteString("[Install]\n")
+	b.WriteString("WantedBy=timers.target\n")
+	return b.String()
+}
+
+// Install 写入 service 和 timer 单元并启用定时器
+func (s *Systemd) Install(spec Spec) error {
+	if err := spec.Validate(); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("创建 systemd 用户目录失败: %v", err)
+	}
+	if err := os.WriteFile(s.ServicePath(), []byte(ServiceUnit(spec)), 0644); err != nil {
+		return fmt.Errorf("写入 service 单元失败: %v", err)
+	}
+	if err := os.WriteFile(s.TimerPath(), []byte(TimerUnit(spec)), 0644); err != nil {
+		return fmt.Errorf("写入 timer 单元失败: %v", err)
+	}
+	if _, err := s.run("", "systemctl", "--user", "daemon-reload"); err != nil {
+		return err
+	}
+	_, err := s.run("", "systemctl", "--user", "enable", "--now", UnitName+".timer")
+	return err
+}
+
+// Remove 停用定时器并删除单元文件
+func (s *Systemd) Remove() error {
+	if _, err := os.Stat(s.TimerPath()); os.IsNotExist(err) {
+		return nil
+	}
+	// 定时器可能已被手动停用，忽略错误
+	s.run("", "systemctl", "--user", "disable", "--now", UnitName+".timer")
+	for _, path := range []string{s.TimerPath(), s.ServicePath()} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除 %s 失败: %v", path, err)
+		}
+	}
+	_, err := s.run("", "systemctl", "--user", "daemon-reload")
+	return err
+}
+
+// Status 读取单元文件和定时器的下次触发时间
+func (s *Systemd) Status() (*Status, error) {
+	status := &Status{Backend: s.Name()}
+	timer, err := os.ReadFile(s.TimerPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return status, nil
+		}
+		return nil, err
+	}
+	status.Installed = true
+	status.Schedule = unitValue(string(timer), "OnCalendar")
+	if service, err := os.ReadFile(s.ServicePath()); err == nil {
+		status.Command = unitValue(string(service), "ExecStart")
+	}
+
+	out, err := s.run("", "systemctl", "--user", "show", UnitName+".timer", "--property=NextElapseUSecRealtime", "--value", "--timestamp=unix")
+	if err == nil {
+		status.NextRun = parseUnixTimestamp(out)
+	}
+	return status, nil
+}
+
+// unitValue 读取单元文件中某个键的值
+func unitValue(unit, key string) string {
+	for _, line := range strings.Split(unit, "\n") {
+		if v, ok := strings.CutPrefix(strings.TrimSpace(line), key+"="); ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// parseUnixTimestamp 解析 systemctl --timestamp=unix 的输出（"@1700000000"）
+func parseUnixTimestamp(out string) time.Time {
+	out = strings.TrimPrefix(strings.TrimSpace(out), "@")
+	sec, err := strconv.ParseInt(out, 10, 64)
+	if err != nil || sec <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(sec, 0)
+}
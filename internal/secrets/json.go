@@ -0,0 +1,121 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// isJSONFile 按扩展名判断是否为 JSON 文件（对话文件 .chat 也是 JSON）
+func isJSONFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".chat", ".json":
+		return true
+	}
+	return false
+}
+
+// ScanJSON 逐个扫描 JSON 中字符串解码后的内容，结果位置换算回原始内容
+// 换算后的范围总是覆盖完整的转义序列，用 Redact 替换后 JSON 仍然有效
+// 内容不是有效的 JSON 时返回错误
+func (s *Scanner) ScanJSON(data []byte) ([]Finding, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var findings []Finding
+	for {
+		prev := int(dec.InputOffset())
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		text, ok := tok.(string)
+		if !ok {
+			continue
+		}
+		// 分隔符和空白中没有引号，第一个引号就是字符串的开头
+		end := int(dec.InputOffset())
+		start := prev + bytes.IndexByte(data[prev:end], '"')
+		findings = append(findings, s.scanString(data[start+1:end-1], start+1, text)...)
+	}
+	for i := range findings {
+		findings[i].Line, findings[i].Column = position(data, findings[i].Offset)
+	}
+	return findings, nil
+}
+
+// scanString 扫描一个字符串的解码内容 text，raw 为它在原始内容中（offset 处）的转义形式
+func (s *Scanner) scanString(raw []byte, offset int, text string) []Finding {
+	found := s.find([]byte(text))
+	if len(found) == 0 {
+		return nil
+	}
+	starts, ends := unescapeMap(raw)
+	if len(starts) != len(text) {
+		// 无法对应（如包含无效的 UTF-8），替换整个字符串
+		return []Finding{{Type: found[0].Type, Offset: offset, Length: len(raw)}}
+	}
+	for i, f := range found {
+		rawStart := starts[f.Offset]
+		rawEnd := ends[f.Offset+f.Length-1]
+		found[i].Offset = offset + rawStart
+		found[i].Length = rawEnd - rawStart
+	}
+	return found
+}
+
+// unescapeMap 计算 JSON 字符串解码后每个字节在转义形式中的来源范围 [starts[i], ends[i])
+// 转义序列（如 \n、é）产生的每个字节都对应整个转义序列；格式不正确时返回 nil
+func unescapeMap(raw []byte) (starts, ends []int) {
+	for i := 0; i < len(raw); {
+		if raw[i] != '\\' {
+			starts = append(starts, i)
+			ends = append(ends, i+1)
+			i++
+			continue
+		}
+		if i+1 >= len(raw) {
+			return nil, nil
+		}
+		n, size := 2, 1
+		if raw[i+1] == 'u' {
+			r, ok := hexRune(raw, i)
+			if !ok {
+				return nil, nil
+			}
+			n = 6
+			if utf16.IsSurrogate(r) {
+				r2, ok := hexRune(raw, i+6)
+				if pair := utf16.DecodeRune(r, r2); ok && pair != utf8.RuneError {
+					r, n = pair, 12
+				} else {
+					r = utf8.RuneError
+				}
+			}
+			size = utf8.RuneLen(r)
+		}
+		for j := 0; j < size; j++ {
+			starts = append(starts, i)
+			ends = append(ends, i+n)
+		}
+		i += n
+	}
+	return starts, ends
+}
+
+// hexRune 解析 raw[i:] 处的 \uXXXX
+func hexRune(raw []byte, i int) (rune, bool) {
+	if i+6 > len(raw) || raw[i] != '\\' || raw[i+1] != 'u' {
+		return 0, false
+	}
+	v, err := strconv.ParseUint(string(raw[i+2:i+6]), 16, 16)
+	if err != nil {
+		return 0, false
+	}
+	return rune(v), true
+}
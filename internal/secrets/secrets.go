@@ -0,0 +1,266 @@
+package secrets
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+)
+
+// 内置检测器类型
+const (
+	TypeAWSAccessKey = "aws-access-key"
+	TypeAWSSecretKey = "aws-secret-key"
+	TypeGitHubToken  = "github-token"
+	TypeJWT          = "jwt"
+	TypePrivateKey   = "private-key"
+	TypeHighEntropy  = "high-entropy"
+)
+
+// DefaultMinEntropy 高熵字符串的默认阈值（每字符比特数）
+const DefaultMinEntropy = 4.5
+
+// RedactedPrefix 替换后的占位符前缀，形如 [REDACTED:github-token]
+const RedactedPrefix = "[REDACTED:"
+
+// Detector 按正则匹配一类敏感信息
+// 正则包含捕获组时只把第一个捕获组当作敏感内容（例如 key=value 中的 value）
+type Detector struct {
+	Type    string
+	Pattern *regexp.Regexp
+}
+
+// DefaultDetectors 返回内置检测器，靠前的优先（重叠时保留靠前检测器的结果）
+func DefaultDetectors() []Detector {
+	return []Detector{
+		{TypePrivateKey, regexp.MustCompile(`-----BEGIN[ A-Z0-9]*PRIVATE KEY-----(?:[\s\S]*?-----END[ A-Z0-9]*PRIVATE KEY-----)?`)},
+		{TypeAWSAccessKey, regexp.MustCompile(`\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`)},
+		{TypeAWSSecretKey, regexp.MustCompile(`(?i)aws.{0,20}?(?:secret|private).{0,20}?[=:"'\s\\]+([A-Za-z0-9/+]{40})\b`)},
+		{TypeGitHubToken, regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)},
+		{TypeJWT, regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	}
+}
+
+// entropyCandidate 高熵检测的候选字符串（base64/hex/url-safe 字符）
+// 超过 256 个字符的通常是内嵌的图片或文件数据，不当作密钥
+var entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/=_-]{32,}`)
+
+const maxEntropyLen = 256
+
+// Finding 一处检测到的敏感信息（不包含内容本身）
+type Finding struct {
+	Type   string `json:"type"`
+	Line   int    `json:"line"`   // 从 1 开始
+	Column int    `json:"column"` // 从 1 开始，按字节
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+// Scanner 敏感信息扫描器
+type Scanner struct {
+	detectors  []Detector
+	minEntropy float64
+}
+
+// NewScanner 创建扫描器，custom 为自定义的 类型名 -> 正则
+// minEntropy 为 0 时不检测高熵字符串
+func NewScanner(custom map[string]string, minEntropy float64) (*Scanner, error) {
+	s := &Scanner{detectors: DefaultDetectors(), minEntropy: minEntropy}
+
+	names := make([]string, 0, len(custom))
+	for name := range custom {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		re, err := regexp.Compile(custom[name])
+		if err != nil {
+			return nil, fmt.Errorf("自定义规则 %s 的正则无效: %v", name, err)
+		}
+		s.detectors = append(s.detectors, Detector{Type: name, Pattern: re})
+	}
+	return s, nil
+}
+
+// Scan 扫描内容，返回按位置排序且互不重叠的结果
+func (s *Scanner) Scan(data []byte) []Finding {
+	kept := s.find(data)
+	for i := range kept {
+		kept[i].Line, kept[i].Column = position(data, kept[i].Offset)
+	}
+	return kept
+}
+
+// find 返回按位置排序且互不重叠的结果（不含行列）
+func (s *Scanner) find(data []byte) []Finding {
+	var spans []Finding
+	for _, d := range s.detectors {
+		for _, m := range d.Pattern.FindAllSubmatchIndex(data, -1) {
+			start, end := m[0], m[1]
+			if len(m) >= 4 && m[2] >= 0 {
+				start, end = m[2], m[3]
+			}
+			start, end = trimEscape(data, start, end)
+			if end > start {
+				spans = append(spans, Finding{Type: d.Type, Offset: start, Length: end - start})
+			}
+		}
+	}
+	if s.minEntropy > 0 {
+		for _, m := range entropyCandidate.FindAllIndex(data, -1) {
+			start, end := trimEscape(data, m[0], m[1])
+			token := data[start:end]
+			if len(token) > maxEntropyLen || !mixed(token) || entropy(token) < s.minEntropy {
+				continue
+			}
+			spans = append(spans, Finding{Type: TypeHighEntropy, Offset: start, Length: end - start})
+		}
+	}
+
+	// 先出现的检测器优先，然后去掉与已保留结果重叠的部分
+	var kept []Finding
+	for _, f := range spans {
+		overlap := false
+		for _, k := range kept {
+			if f.Offset < k.Offset+k.Length && k.Offset < f.Offset+f.Length {
+				overlap = true
+				break
+			}
+		}
+		if !overlap {
+			kept = append(kept, f)
+		}
+	}
+	sort.Slice(kept, func(i, j int) bool { return kept[i].Offset < kept[j].Offset })
+	return kept
+}
+
+// trimEscape 不让结果从转义字符（如 \n 中的 n）开始，也不以反斜杠结束
+// 否则替换后会留下不完整的转义序列，破坏 JSON 等文件格式
+func trimEscape(data []byte, start, end int) (int, int) {
+	if start > 0 && start < end && data[start-1] == '\\' {
+		start++
+	}
+	if end > start && data[end-1] == '\\' {
+		end--
+	}
+	return start, end
+}
+
+// ScanFile 扫描文件，.chat 和 .json 文件按 JSON 字符串的解码内容扫描
+func (s *Scanner) ScanFile(path string) ([]Finding, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+	return s.scanFileData(path, data), nil
+}
+
+// scanFileData 按文件类型扫描，JSON 无法解析时按普通文本扫描
+func (s *Scanner) scanFileData(path string, data []byte) []Finding {
+	if isJSONFile(path) {
+		if findings, err := s.ScanJSON(data); err == nil {
+			return findings
+		}
+	}
+	return s.Scan(data)
+}
+
+// Redact 将结果位置的内容替换为 [REDACTED:<type>]
+// findings 必须来自对同一 data 的 Scan
+func Redact(data []byte, findings []Finding) []byte {
+	var out bytes.Buffer
+	last := 0
+	for _, f := range findings {
+		if f.Offset < last || f.Offset+f.Length > len(data) {
+			continue
+		}
+		out.Write(data[last:f.Offset])
+		out.WriteString(RedactedPrefix + safeType(f.Type) + "]")
+		last = f.Offset + f.Length
+	}
+	out.Write(data[last:])
+	return out.Bytes()
+}
+
+// RedactFile 重新扫描文件并原子替换其中的敏感信息，保留修改时间
+// 返回替换的数量
+func (s *Scanner) RedactFile(path string) (int, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("读取文件失败: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("读取文件失败: %v", err)
+	}
+	findings := s.scanFileData(path, data)
+	if len(findings) == 0 {
+		return 0, nil
+	}
+	out := Redact(data, findings)
+	// 原本有效的 JSON 替换后必须仍然有效，否则不写回
+	if isJSONFile(path) && json.Valid(data) && !json.Valid(out) {
+		return 0, fmt.Errorf("替换后 %s 不再是有效的 JSON，未修改", path)
+	}
+	if err := utils.WriteFileAtomic(path, out, info.Mode().Perm()); err != nil {
+		return 0, err
+	}
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+	return len(findings), nil
+}
+
+// position 计算偏移量所在的行和列
+func position(data []byte, offset int) (int, int) {
+	line := 1 + bytes.Count(data[:offset], []byte("\n"))
+	start := bytes.LastIndexByte(data[:offset], '\n') + 1
+	return line, offset - start + 1
+}
+
+// safeType 占位符中只保留字母、数字、- 和 _，避免破坏 JSON 等文件格式
+func safeType(t string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' {
+			return r
+		}
+		return -1
+	}, t)
+}
+
+// mixed 同时包含字母和数字（排除纯单词和纯数字）
+func mixed(token []byte) bool {
+	var letter, digit bool
+	for _, c := range token {
+		switch {
+		case c >= '0' && c <= '9':
+			digit = true
+		case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+			letter = true
+		}
+	}
+	return letter && digit
+}
+
+// entropy 计算香农熵（每字符比特数）
+func entropy(token []byte) float64 {
+	var counts [256]int
+	for _, c := range token {
+		counts[c]++
+	}
+	var h float64
+	n := float64(len(token))
+	for _, c := range counts {
+		if c == 0 {
+			continue
+		}
+		p := float64(c) / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
@@ -0,0 +1,453 @@
+package sessions
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+)
+
+// SessionsDirName 会话目录名（位于 kiro.kiroagent 下）
+const SessionsDirName = "workspace-sessions"
+
+// IndexFileName 会话索引文件名
+const IndexFileName = "sessions.json"
+
+// Session 单个会话
+type Session struct {
+	ID        string    `json:"id"`         // 会话ID（<uuid>.json 的文件名）
+	Title     string    `json:"title"`      // 会话标题
+	Dir       string    `json:"dir"`        // 所在工作区会话目录
+	Path      string    `json:"path"`       // <uuid>.json 文件路径
+	Size      int64     `json:"size"`       // 文件大小
+	ModTime   time.Time `json:"mod_time"`   // 文件修改时间
+	CreatedAt time.Time `json:"created_at"` // 索引中记录的创建时间
+	Indexed   bool      `json:"indexed"`    // 是否登记在 sessions.json 中
+	Missing   bool      `json:"missing"`    // 索引中存在但文件缺失
+}
+
+// LastActivity 返回会话最后活动时间
+func (s Session) LastActivity() time.Time {
+	if s.ModTime.After(s.CreatedAt) {
+		return s.ModTime
+	}
+	return s.CreatedAt
+}
+
+// Age 返回会话距今的时长
+func (s Session) Age() time.Duration {
+	return time.Since(s.LastActivity())
+}
+
+// WorkspaceSessions 单个工作区的会话集合
+type WorkspaceSessions struct {
+	Dir       string    `json:"dir"`        // workspace-sessions/<base64_path> 目录
+	Workspace string    `json:"workspace"`  // 解码后的工作区路径
+	IndexPath string    `json:"index_path"` // sessions.json 路径
+	IndexSize int64     `json:"index_size"` // sessions.json 大小
+	Sessions  []Session `json:"sessions"`   // 会话列表
+	TotalSize int64     `json:"total_size"` // 会话文件总大小（含索引）
+}
+
+// DeleteResult 删除结果
+type DeleteResult struct {
+	Deleted      int      `json:"deleted"`       // 删除的会话文件数
+	FreedBytes   int64    `json:"freed_bytes"`   // 释放的字节数
+	IndexUpdated int      `json:"index_updated"` // 改写的索引文件数
+	IndexBackups []string `json:"index_backups"` // 索引备份路径
+	Errors       []error  `json:"-"`             // 错误列表
+}
+
+// Manager 会话管理器
+type Manager struct {
+	basePath  string
+	backupMgr *backup.BackupManager
+}
+
+// NewManager 创建会话管理器，kiroAgentPath 为 kiro.kiroagent 目录
+func NewManager(kiroAgentPath string) *Manager {
+	return &Manager{
+		basePath: filepath.Join(kiroAgentPath, SessionsDirName),
+	}
+}
+
+// SetBackupManager 设置用于备份 sessions.json 的备份管理器
+func (m *Manager) SetBackupManager(bm *backup.BackupManager) {
+	m.backupMgr = bm
+}
+
+// BasePath 返回 workspace-sessions 目录
+func (m *Manager) BasePath() string {
+	return m.basePath
+}
+
+// Scan 扫描所有工作区的会话
+func (m *Manager) Scan() ([]WorkspaceSessions, error) {
+	entries, err := os.ReadDir(m.basePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取会话目录失败: %v", err)
+	}
+
+	var result []WorkspaceSessions
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		ws, err := m.scanWorkspace(filepath.Join(m.basePath, entry.Name()))
+		if err != nil {
+			// 跳过无法解析的工作区，继续处理其他
+			continue
+		}
+		if len(ws.Sessions) > 0 || ws.IndexSize > 0 {
+			result = append(result, *ws)
+		}
+	}
+
+	return result, nil
+}
+
+// scanWorkspace 扫描单个工作区会话目录
+func (m *Manager) scanWorkspace(dir string) (*WorkspaceSessions, error) {
+	ws := &WorkspaceSessions{
+		Dir:       dir,
+		Workspace: DecodeWorkspacePath(filepath.Base(dir)),
+		IndexPath: filepath.Join(dir, IndexFileName),
+	}
+
+	// 读取索引
+	idx, err := readIndex(ws.IndexPath)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := os.Stat(ws.IndexPath); err == nil {
+		ws.IndexSize = info.Size()
+		ws.TotalSize += info.Size()
+	}
+
+	indexed := make(map[string]bool)
+	for _, e := range idx.entries {
+		id := entryID(e)
+		if id == "" || filepath.Base(id) != id {
+			// 忽略空ID和包含路径分隔符的异常条目
+			continue
+		}
+		indexed[id] = true
+
+		s := Session{
+			ID:        id,
+			Title:     entryString(e, "title"),
+			Dir:       dir,
+			Path:      filepath.Join(dir, id+".json"),
+			CreatedAt: entryTime(e, "dateCreated"),
+			Indexed:   true,
+		}
+		if info, err := os.Stat(s.Path); err == nil {
+			s.Size = info.Size()
+			s.ModTime = info.ModTime()
+		} else {
+			s.Missing = true
+		}
+		ws.Sessions = append(ws.Sessions, s)
+		ws.TotalSize += s.Size
+	}
+
+	// 未登记在索引中的会话文件
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		name := f.Name()
+		if f.IsDir() || name == IndexFileName || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		id := strings.TrimSuffix(name, ".json")
+		if indexed[id] {
+			continue
+		}
+		info, err := f.Info()
+		if err != nil {
+			continue
+		}
+		ws.Sessions = append(ws.Sessions, Session{
+			ID:      id,
+			Dir:     dir,
+			Path:    filepath.Join(dir, name),
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		ws.TotalSize += info.Size()
+	}
+
+	// 按最后活动时间排序（新的在前）
+	sort.Slice(ws.Sessions, func(i, j int) bool {
+		return ws.Sessions[i].LastActivity().After(ws.Sessions[j].LastActivity())
+	})
+
+	return ws, nil
+}
+
+// FindOld 查找超过指定天数未活动的会话
+// 索引中登记但文件已缺失的条目也会返回，删除时只从索引中移除
+func (m *Manager) FindOld(olderThanDays int) ([]Session, error) {
+	workspaces, err := m.Scan()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().AddDate(0, 0, -olderThanDays)
+	var old []Session
+	for _, ws := range workspaces {
+		for _, s := range ws.Sessions {
+			if s.Missing || s.LastActivity().Before(cutoff) {
+				old = append(old, s)
+			}
+		}
+	}
+	return old, nil
+}
+
+// Delete 删除会话并同步改写 sessions.json
+// 先备份并原子改写索引，再删除会话文件，保证索引不会引用不存在的文件
+func (m *Manager) Delete(sessions []Session) (*DeleteResult, error) {
+	result := &DeleteResult{}
+
+	// 按工作区目录分组
+	byDir := make(map[string][]Session)
+	var dirs []string
+	for _, s := range sessions {
+		if _, ok := byDir[s.Dir]; !ok {
+			dirs = append(dirs, s.Dir)
+		}
+		byDir[s.Dir] = append(byDir[s.Dir], s)
+	}
+	sort.Strings(dirs)
+
+	guard := safety.NewGuard(m.basePath)
+	for _, dir := range dirs {
+		group := byDir[dir]
+		indexPath := filepath.Join(dir, IndexFileName)
+		if err := guard.Check(indexPath); err != nil {
+			result.Errors = append(result.Errors, err)
+			continue
+		}
+
+		remove := make(map[string]bool)
+		needRewrite := false
+		for _, s := range group {
+			remove[s.ID] = true
+			if s.Indexed {
+				needRewrite = true
+			}
+		}
+
+		if needRewrite {
+			backupPath, err := m.rewriteIndex(indexPath, remove)
+			if err != nil {
+				// 索引改写失败时不删除该工作区的任何文件
+				result.Errors = append(result.Errors, fmt.Errorf("改写索引 %s 失败: %v", indexPath, err))
+				continue
+			}
+			result.IndexUpdated++
+			if backupPath != "" {
+				result.IndexBackups = append(result.IndexBackups, backupPath)
+			}
+		}
+
+		for _, s := range group {
+			if s.Missing {
+				continue
+			}
+			if err := guard.Remove(s.Path); err != nil && !os.IsNotExist(err) {
+				result.Errors = append(result.Errors, fmt.Errorf("删除会话 %s 失败: %v", s.Path, err))
+				continue
+			}
+			result.Deleted++
+			result.FreedBytes += s.Size
+		}
+	}
+
+	return result, nil
+}
+
+// rewriteIndex 从索引中移除指定会话，返回索引备份路径
+func (m *Manager) rewriteIndex(indexPath string, remove map[string]bool) (string, error) {
+	idx, err := readIndex(indexPath)
+	if err != nil {
+		return "", err
+	}
+	if !idx.exists {
+		return "", nil
+	}
+
+	var kept []map[string]json.RawMessage
+	for _, e := range idx.entries {
+		if remove[entryID(e)] {
+			continue
+		}
+		kept = append(kept, e)
+	}
+	if len(kept) == len(idx.entries) {
+		return "", nil
+	}
+
+	// 改写前备份原索引
+	var backupPath string
+	if m.backupMgr != nil {
+		backupPath, err = m.backupMgr.BackupFile(indexPath)
+		if err != nil {
+			return "", fmt.Errorf("备份索引失败: %v", err)
+		}
+	}
+
+	data, err := idx.marshal(kept)
+	if err != nil {
+		return "", err
+	}
+	if err := utils.WriteFileAtomic(indexPath, data, 0644); err != nil {
+		return "", err
+	}
+
+	return backupPath, nil
+}
+
+// sessionIndex 解析后的 sessions.json
+// 支持顶层数组和 {"sessions": [...]} 两种格式，改写时保留原格式和未知字段
+type sessionIndex struct {
+	exists  bool
+	wrapped map[string]json.RawMessage
+	entries []map[string]json.RawMessage
+}
+
+// readIndex 读取 sessions.json，文件不存在时返回空索引
+func readIndex(path string) (*sessionIndex, error) {
+	idx := &sessionIndex{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return idx, nil
+		}
+		return nil, fmt.Errorf("读取索引失败: %v", err)
+	}
+	idx.exists = true
+
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "" {
+		return idx, nil
+	}
+
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &idx.entries); err != nil {
+			return nil, fmt.Errorf("解析索引失败: %v", err)
+		}
+		return idx, nil
+	}
+
+	if err := json.Unmarshal(data, &idx.wrapped); err != nil {
+		return nil, fmt.Errorf("解析索引失败: %v", err)
+	}
+	if raw, ok := idx.wrapped["sessions"]; ok {
+		if err := json.Unmarshal(raw, &idx.entries); err != nil {
+			return nil, fmt.Errorf("解析索引失败: %v", err)
+		}
+	}
+	return idx, nil
+}
+
+// marshal 按原格式序列化索引
+func (idx *sessionIndex) marshal(entries []map[string]json.RawMessage) ([]byte, error) {
+	if entries == nil {
+		entries = []map[string]json.RawMessage{}
+	}
+	if idx.wrapped == nil {
+		return json.Marshal(entries)
+	}
+
+	raw, err := json.Marshal(entries)
+	if err != nil {
+		return nil, err
+	}
+	idx.wrapped["sessions"] = raw
+	return json.Marshal(idx.wrapped)
+}
+
+// entryID 获取索引条目的会话ID
+func entryID(e map[string]json.RawMessage) string {
+	if id := entryString(e, "sessionId"); id != "" {
+		return id
+	}
+	return entryString(e, "id")
+}
+
+// entryString 获取索引条目的字符串字段
+func entryString(e map[string]json.RawMessage, key string) string {
+	raw, ok := e[key]
+	if !ok {
+		return ""
+	}
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return ""
+	}
+	return s
+}
+
+// entryTime 获取索引条目的时间字段（毫秒时间戳，可能为数字或字符串）
+func entryTime(e map[string]json.RawMessage, key string) time.Time {
+	raw, ok := e[key]
+	if !ok {
+		return time.Time{}
+	}
+
+	var ms int64
+	if err := json.Unmarshal(raw, &ms); err == nil && ms > 0 {
+		return time.UnixMilli(ms)
+	}
+
+	var s string
+	if err := json.Unmarshal(raw, &s); err != nil || s == "" {
+		return time.Time{}
+	}
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil && n > 0 {
+		return time.UnixMilli(n)
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t
+	}
+	return time.Time{}
+}
+
+// DecodeWorkspacePath 解码 base64 编码的工作区目录名，无法解码时原样返回
+func DecodeWorkspacePath(name string) string {
+	encodings := []*base64.Encoding{
+		base64.RawURLEncoding,
+		base64.URLEncoding,
+		base64.StdEncoding,
+		base64.RawStdEncoding,
+	}
+	for _, enc := range encodings {
+		decoded, err := enc.DecodeString(name)
+		if err != nil || !utf8.Valid(decoded) {
+			continue
+		}
+		// 只接受看起来像路径的结果
+		if path := string(decoded); strings.ContainsAny(path, `/\`) {
+			return path
+		}
+	}
+	return name
+}
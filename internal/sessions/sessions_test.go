@@ -0,0 +1,178 @@
+package sessions
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// createSessionsStructure 创建模拟的 workspace-sessions 结构，返回工作区会话目录
+func createSessionsStructure(t *testing.T, agentPath string) string {
+	t.Helper()
+	wsDir := filepath.Join(agentPath, SessionsDirName, base64.StdEncoding.EncodeToString([]byte("/home/dev/project")))
+	if err := os.MkdirAll(wsDir, 0755); err != nil {
+		t.Fatalf("创建会话目录失败: %v", err)
+	}
+
+	index := `[
+		{"sessionId": "old-1", "title": "Old session", "dateCreated": "1600000000000", "extra": {"keep": true}},
+		{"sessionId": "new-1", "title": "New session", "dateCreated": 1900000000000},
+		{"sessionId": "gone-1", "title": "Missing file"}
+	]`
+	if err := os.WriteFile(filepath.Join(wsDir, IndexFileName), []byte(index), 0644); err != nil {
+		t.Fatalf("写入索引失败: %v", err)
+	}
+
+	for _, id := range []string{"old-1", "new-1", "orphan-1"} {
+		path := filepath.Join(wsDir, id+".json")
+		if err := os.WriteFile(path, []byte(`{"history":[{"promptLogs":[]}]}`), 0644); err != nil {
+			t.Fatalf("写入会话失败: %v", err)
+		}
+	}
+
+	// 旧会话和未登记会话设置为 90 天前
+	oldTime := time.Now().AddDate(0, 0, -90)
+	os.Chtimes(filepath.Join(wsDir, "old-1.json"), oldTime, oldTime)
+	os.Chtimes(filepath.Join(wsDir, "orphan-1.json"), oldTime, oldTime)
+
+	return wsDir
+}
+
+func TestManager_Scan(t *testing.T) {
+	agentPath := t.TempDir()
+	createSessionsStructure(t, agentPath)
+
+	workspaces, err := NewManager(agentPath).Scan()
+	if err != nil {
+		t.Fatalf("扫描失败: %v", err)
+	}
+	if len(workspaces) != 1 {
+		t.Fatalf("应该有 1 个工作区，实际 %d", len(workspaces))
+	}
+
+	ws := workspaces[0]
+	if ws.Workspace != "/home/dev/project" {
+		t.Errorf("工作区路径解码错误: %s", ws.Workspace)
+	}
+	if len(ws.Sessions) != 4 {
+		t.Fatalf("应该有 4 个会话，实际 %d", len(ws.Sessions))
+	}
+
+	states := make(map[string]Session)
+	for _, s := range ws.Sessions {
+		states[s.ID] = s
+	}
+	if !states["gone-1"].Missing {
+		t.Error("gone-1 应该标记为缺失")
+	}
+	if states["orphan-1"].Indexed {
+		t.Error("orphan-1 不应该标记为已登记")
+	}
+	if states["new-1"].CreatedAt.IsZero() {
+		t.Error("new-1 应该解析出创建时间")
+	}
+}
+
+func TestManager_DeleteKeepsIndexConsistent(t *testing.T) {
+	agentPath := t.TempDir()
+	wsDir := createSessionsStructure(t, agentPath)
+
+	mgr := NewManager(agentPath)
+	mgr.SetBackupManager(backup.NewBackupManager(&types.BackupConfig{Path: t.TempDir()}))
+
+	old, err := mgr.FindOld(30)
+	if err != nil {
+		t.Fatalf("查找旧会话失败: %v", err)
+	}
+	// old-1（过期）、orphan-1（过期未登记）、gone-1（缺失）
+	if len(old) != 3 {
+		t.Fatalf("应该找到 3 个旧会话，实际 %d", len(old))
+	}
+
+	result, err := mgr.Delete(old)
+	if err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+	if len(result.Errors) > 0 {
+		t.Fatalf("删除出错: %v", result.Errors)
+	}
+	if result.Deleted != 2 {
+		t.Errorf("应该删除 2 个文件，实际 %d", result.Deleted)
+	}
+	if result.IndexUpdated != 1 || len(result.IndexBackups) != 1 {
+		t.Errorf("应该改写并备份 1 个索引: %+v", result)
+	}
+
+	// 索引只保留 new-1，且保留条目原有字段
+	data, err := os.ReadFile(filepath.Join(wsDir, IndexFileName))
+	if err != nil {
+		t.Fatalf("读取索引失败: %v", err)
+	}
+	var entries []map[string]interface{}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		t.Fatalf("解析改写后的索引失败: %v", err)
+	}
+	if len(entries) != 1 || entries[0]["sessionId"] != "new-1" {
+		t.Errorf("索引内容错误: %s", data)
+	}
+
+	// 索引中的每个条目都必须有对应文件
+	for _, e := range entries {
+		id := e["sessionId"].(string)
+		if _, err := os.Stat(filepath.Join(wsDir, id+".json")); err != nil {
+			t.Errorf("索引引用了不存在的文件: %s", id)
+		}
+	}
+
+	// 备份内容应为原始索引
+	backupData, err := os.ReadFile(result.IndexBackups[0])
+	if err != nil {
+		t.Fatalf("读取备份失败: %v", err)
+	}
+	if !json.Valid(backupData) || len(backupData) <= len(data) {
+		t.Error("备份应该是改写前的原始索引")
+	}
+}
+
+func TestManager_WrappedIndexFormat(t *testing.T) {
+	agentPath := t.TempDir()
+	wsDir := filepath.Join(agentPath, SessionsDirName, "ws")
+	os.MkdirAll(wsDir, 0755)
+	os.WriteFile(filepath.Join(wsDir, IndexFileName),
+		[]byte(`{"version": 2, "sessions": [{"id": "a"}, {"id": "b"}]}`), 0644)
+	os.WriteFile(filepath.Join(wsDir, "a.json"), []byte(`{}`), 0644)
+	os.WriteFile(filepath.Join(wsDir, "b.json"), []byte(`{}`), 0644)
+
+	mgr := NewManager(agentPath)
+	if _, err := mgr.Delete([]Session{{ID: "a", Dir: wsDir, Path: filepath.Join(wsDir, "a.json"), Indexed: true}}); err != nil {
+		t.Fatalf("删除失败: %v", err)
+	}
+
+	data, _ := os.ReadFile(filepath.Join(wsDir, IndexFileName))
+	var wrapped struct {
+		Version  int                 `json:"version"`
+		Sessions []map[string]string `json:"sessions"`
+	}
+	if err := json.Unmarshal(data, &wrapped); err != nil {
+		t.Fatalf("解析改写后的索引失败: %v", err)
+	}
+	if wrapped.Version != 2 || len(wrapped.Sessions) != 1 || wrapped.Sessions[0]["id"] != "b" {
+		t.Errorf("应保留包装格式和其他字段: %s", data)
+	}
+}
+
+func TestDecodeWorkspacePath(t *testing.T) {
+	encoded := base64.RawURLEncoding.EncodeToString([]byte("/Users/dev/my-app"))
+	if got := DecodeWorkspacePath(encoded); got != "/Users/dev/my-app" {
+		t.Errorf("DecodeWorkspacePath = %q", got)
+	}
+	if got := DecodeWorkspacePath("not-base64!"); got != "not-base64!" {
+		t.Errorf("无法解码时应原样返回: %q", got)
+	}
+}
@@ -0,0 +1,169 @@
+package slim
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+)
+
+// MarkerPrefix 被裁剪的工具输出末尾的标记前缀，已带标记的消息不会再次裁剪
+const MarkerPrefix = "[kiro-cleaner: trimmed"
+
+const (
+	// DefaultThreshold 超过该大小的工具消息会被裁剪
+	DefaultThreshold = 4 * 1024
+	// DefaultKeep 裁剪时保留的开头字节数
+	DefaultKeep = 512
+)
+
+// Options 裁剪选项
+type Options struct {
+	Threshold int // 工具消息内容超过该字节数时裁剪
+	Keep      int // 保留开头的字节数，0 表示整条替换为标记
+}
+
+// Result 单个对话文件的裁剪结果
+type Result struct {
+	Path    string
+	Before  int64 // 裁剪前大小
+	After   int64 // 裁剪后大小
+	Trimmed int   // 被裁剪的工具消息数
+}
+
+// Saved 返回节省的字节数
+func (r Result) Saved() int64 {
+	return r.Before - r.After
+}
+
+// Slim 裁剪 .chat JSON 中过大的工具消息，human/bot 消息和其他字段保持不变
+// 没有需要裁剪的消息时返回原数据
+func Slim(data []byte, opts Options) ([]byte, int, error) {
+	var file map[string]json.RawMessage
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, 0, fmt.Errorf("解析对话文件失败: %v", err)
+	}
+	rawChat, ok := file["chat"]
+	if !ok {
+		return data, 0, nil
+	}
+	var messages []map[string]json.RawMessage
+	if err := json.Unmarshal(rawChat, &messages); err != nil {
+		return nil, 0, fmt.Errorf("解析消息列表失败: %v", err)
+	}
+
+	trimmed := 0
+	for _, msg := range messages {
+		var role string
+		json.Unmarshal(msg["role"], &role)
+		if role != "tool" {
+			continue
+		}
+		content, ok := trimContent(msg["content"], opts)
+		if !ok {
+			continue
+		}
+		encoded, err := marshal(content)
+		if err != nil {
+			return nil, 0, err
+		}
+		msg["content"] = encoded
+		trimmed++
+	}
+	if trimmed == 0 {
+		return data, 0, nil
+	}
+
+	encoded, err := marshal(messages)
+	if err != nil {
+		return nil, 0, err
+	}
+	file["chat"] = encoded
+	out, err := marshal(file)
+	if err != nil {
+		return nil, 0, err
+	}
+	return out, trimmed, nil
+}
+
+// trimContent 返回裁剪后的内容，不需要裁剪时返回 false
+// 非字符串内容（如结构化的工具结果）按原始 JSON 大小判断，超过阈值时整条替换
+func trimContent(raw json.RawMessage, opts Options) (string, bool) {
+	if len(raw) <= opts.Threshold {
+		return "", false
+	}
+	var content string
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return marker(len(raw)), true
+	}
+	if len(content) <= opts.Threshold || strings.Contains(content, MarkerPrefix) {
+		return "", false
+	}
+	if opts.Keep <= 0 {
+		return marker(len(content)), true
+	}
+	if opts.Keep >= len(content) {
+		return "", false
+	}
+	// 不在多字节字符中间截断
+	cut := opts.Keep
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	head := content[:cut]
+	return head + "\n" + marker(len(content)-len(head)), true
+}
+
+func marker(removed int) string {
+	return fmt.Sprintf("%s %d bytes of tool output]", MarkerPrefix, removed)
+}
+
+// marshal 编码 JSON，不转义 HTML 字符以免改动消息内容
+func marshal(v interface{}) (json.RawMessage, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, fmt.Errorf("编码对话文件失败: %v", err)
+	}
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// File 计算文件裁剪后的结果，write 为 true 时原子写回并保留修改时间
+func File(path string, opts Options, write bool) (Result, error) {
+	result := Result{Path: path}
+	info, err := os.Stat(path)
+	if err != nil {
+		return result, fmt.Errorf("读取对话文件失败: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return result, fmt.Errorf("读取对话文件失败: %v", err)
+	}
+	result.Before = int64(len(data))
+	result.After = result.Before
+
+	out, trimmed, err := Slim(data, opts)
+	if err != nil {
+		return result, err
+	}
+	if trimmed == 0 || len(out) >= len(data) {
+		return result, nil
+	}
+	result.Trimmed = trimmed
+	result.After = int64(len(out))
+	if !write {
+		return result, nil
+	}
+
+	if err := utils.WriteFileAtomic(path, out, info.Mode().Perm()); err != nil {
+		return Result{Path: path, Before: result.Before, After: result.Before}, err
+	}
+	// 保留修改时间，避免裁剪过的对话在按时间清理时被当作新对话
+	os.Chtimes(path, info.ModTime(), info.ModTime())
+	return result, nil
+}
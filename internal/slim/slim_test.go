@@ -0,0 +1,111 @@
+package slim
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func chatJSON(t *testing.T, messages ...map[string]interface{}) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{
+		"executionId": "abc",
+		"chat":        messages,
+		"metadata":    map[string]interface{}{"modelId": "claude"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func TestSlimTrimsOnlyLargeToolMessages(t *testing.T) {
+	big := strings.Repeat("x", 5000)
+	data := chatJSON(t,
+		map[string]interface{}{"role": "human", "content": big},
+		map[string]interface{}{"role": "tool", "content": big, "toolUseId": "t1"},
+		map[string]interface{}{"role": "tool", "content": "small <output>"},
+		map[string]interface{}{"role": "bot", "content": big},
+	)
+
+	out, trimmed, err := Slim(data, Options{Threshold: 1000, Keep: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trimmed != 1 {
+		t.Fatalf("应裁剪 1 条消息，实际 %d", trimmed)
+	}
+
+	var file struct {
+		ExecutionID string                   `json:"executionId"`
+		Chat        []map[string]interface{} `json:"chat"`
+	}
+	if err := json.Unmarshal(out, &file); err != nil {
+		t.Fatal(err)
+	}
+	if file.ExecutionID != "abc" || len(file.Chat) != 4 {
+		t.Fatalf("其他字段不应改变: %+v", file)
+	}
+	if file.Chat[0]["content"] != big || file.Chat[3]["content"] != big {
+		t.Error("human/bot 消息不应被裁剪")
+	}
+	tool := file.Chat[1]["content"].(string)
+	if !strings.HasPrefix(tool, strings.Repeat("x", 100)+"\n"+MarkerPrefix) || file.Chat[1]["toolUseId"] != "t1" {
+		t.Errorf("工具消息裁剪不正确: %q", tool)
+	}
+	if file.Chat[2]["content"] != "small <output>" {
+		t.Errorf("小的工具消息不应改变: %v", file.Chat[2]["content"])
+	}
+
+	// 再次裁剪不应有变化
+	if _, again, _ := Slim(out, Options{Threshold: 100, Keep: 100}); again != 0 {
+		t.Errorf("已裁剪的消息不应再次裁剪，实际 %d", again)
+	}
+}
+
+func TestSlimDropAndUTF8(t *testing.T) {
+	data := chatJSON(t, map[string]interface{}{"role": "tool", "content": strings.Repeat("中", 500)})
+
+	out, _, err := Slim(data, Options{Threshold: 100, Keep: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !json.Valid(out) || !strings.Contains(string(out), `"中中中\n`+MarkerPrefix) {
+		t.Errorf("应在字符边界截断: %s", out)
+	}
+
+	out, _, _ = Slim(data, Options{Threshold: 100})
+	if !strings.Contains(string(out), `"content":"`+MarkerPrefix+" 1500 bytes") {
+		t.Errorf("Keep 为 0 时应整条替换: %s", out)
+	}
+}
+
+func TestFileKeepsModTime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "a.chat")
+	data := chatJSON(t, map[string]interface{}{"role": "tool", "content": strings.Repeat("y", 3000)})
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-48 * time.Hour).Truncate(time.Second)
+	os.Chtimes(path, old, old)
+
+	preview, err := File(path, Options{Threshold: 1000}, false)
+	if err != nil || preview.Trimmed != 1 || preview.Saved() <= 0 {
+		t.Fatalf("预览结果不正确: %+v, %v", preview, err)
+	}
+	if after, _ := os.ReadFile(path); len(after) != len(data) {
+		t.Fatal("预览不应修改文件")
+	}
+
+	result, err := File(path, Options{Threshold: 1000}, true)
+	if err != nil || result != preview {
+		t.Fatalf("写入结果与预览不一致: %+v, %v", result, err)
+	}
+	info, _ := os.Stat(path)
+	if info.Size() != result.After || !info.ModTime().Equal(old) {
+		t.Errorf("应写入裁剪后的内容并保留修改时间: %d %v", info.Size(), info.ModTime())
+	}
+}
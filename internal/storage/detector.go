@@ -7,6 +7,7 @@ import (
 	"runtime"
 	"strings"
 
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/safety"
 	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
 )
 
@@ -35,16 +36,19 @@ func NewStorageDetector() *StorageDetector {
 }
 
 // FindKiroPaths 查找Kiro的存储路径
+// 返回所有发现的安装（见 DiscoverInstalls），UseInstall 选择了安装时只返回该安装
+// 选中的安装目录已不存在时返回错误，不退回到其他安装
 func (sd *StorageDetector) FindKiroPaths() ([]string, error) {
-	var paths []string
-	
-	// 常见存储位置
-	commonPaths := sd.pathFinder.getCommonPaths()
-	
-	for _, path := range commonPaths {
-		if sd.pathExists(path) {
-			paths = append(paths, path)
+	if install, ok := SelectedInstall(); ok {
+		if info, err := os.Stat(install.Path); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("选中的 Kiro 安装 %s 已不存在: %s", install.Label, install.Path)
 		}
+		return []string{install.Path}, nil
+	}
+	
+	var paths []string
+	for _, install := range DiscoverInstalls() {
+		paths = append(paths, install.Path)
 	}
 	
 	return paths, nil
@@ -97,7 +101,7 @@ func (sd *StorageDetector) pathExists(path string) bool {
 func (sd *StorageDetector) DetectFileTypes(path string) (map[string]types.FileType, error) {
 	fileTypes := make(map[string]types.FileType)
 	
-	err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+	err := safety.Walk(path, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // 跳过无法访问的文件
 		}
@@ -106,104 +110,7 @@ func (sd *StorageDetector) DetectFileTypes(path string) (map[string]types.FileTy
 			return nil
 		}
 		
-		ext := strings.ToLower(filepath.Ext(filePath))
-		name := strings.ToLower(info.Name())
-		pathLower := strings.ToLower(filePath)
-		
-		switch {
-		// 数据库文件
-		case ext == ".db" || ext == ".sqlite" || ext == ".sqlite3" || ext == ".vscdb":
-			// 区分索引数据库和普通数据库
-			if strings.Contains(pathLower, "/index/") || strings.Contains(pathLower, "lancedb") {
-				fileTypes[filePath] = types.TypeIndex
-			} else {
-				fileTypes[filePath] = types.TypeDatabase
-			}
-		case ext == ".chat":
-			fileTypes[filePath] = types.TypeDatabase
-		
-		// 索引文件（向量数据库等）
-		case strings.Contains(pathLower, "/index/") || 
-			strings.Contains(pathLower, "lancedb") ||
-			ext == ".lance" || ext == ".manifest" || ext == ".txn":
-			fileTypes[filePath] = types.TypeIndex
-			
-		// 代码差异/历史版本缓存（kiroagent 目录下非 .chat 文件）
-		case strings.Contains(pathLower, "kiro.kiroagent") && 
-			!strings.HasSuffix(name, ".chat") &&
-			!strings.Contains(pathLower, "/index/") &&
-			!strings.Contains(pathLower, "lancedb") &&
-			!strings.Contains(pathLower, "workspace-sessions") &&
-			!strings.Contains(pathLower, "dev_data") &&
-			!strings.Contains(pathLower, "/default/"):
-			fileTypes[filePath] = types.TypeCache
-			
-		// 配置文件（不应删除）
-		case name == "config.json" || name == "settings.json" || name == "mcp.json" ||
-			name == "preferences" || name == "machineid" || name == "machineid.json" ||
-			name == "languagepacks.json" || name == "code.lock":
-			fileTypes[filePath] = types.TypeConfig
-			
-		// 日志文件
-		case ext == ".log" || strings.Contains(pathLower, "/logs/") || strings.Contains(name, ".log"):
-			fileTypes[filePath] = types.TypeLog
-			
-		// 临时文件和崩溃报告
-		case ext == ".tmp" || ext == ".temp" || name == "temp" ||
-			strings.Contains(pathLower, "crashpad") ||
-			strings.HasPrefix(name, ".dev.kiro.desktop") ||
-			name == "code.lock" || ext == ".sock":
-			fileTypes[filePath] = types.TypeTemp
-			
-		// Electron/Chrome 数据文件（可清理但可能影响登录）
-		case name == "cookies" || name == "cookies-journal" ||
-			name == "dips" || name == "dips-wal" ||
-			name == "sharedstorage" || name == "sharedstorage-wal" ||
-			name == "trust tokens" || name == "trust tokens-journal" ||
-			name == "network persistent state" || name == "transportsecurity":
-			fileTypes[filePath] = types.TypeCache
-			
-		// 缓存文件（各种缓存目录）
-		case strings.Contains(pathLower, "cache") ||
-			strings.Contains(pathLower, "cacheddata") ||
-			strings.Contains(pathLower, "cachedprofilesdata") ||
-			strings.Contains(pathLower, "gpucache") ||
-			strings.Contains(pathLower, "dawnwebgpucache") ||
-			strings.Contains(pathLower, "dawngraphitecache") ||
-			strings.Contains(pathLower, "code cache") ||
-			strings.Contains(pathLower, "service worker") ||
-			strings.Contains(pathLower, "local storage") ||
-			strings.Contains(pathLower, "webstorage") ||
-			strings.Contains(pathLower, "session storage") ||
-			strings.Contains(pathLower, "blob_storage") ||
-			strings.Contains(pathLower, "shared dictionary") ||
-			strings.Contains(pathLower, "leveldb") ||
-			ext == ".ldb" || ext == ".sst": // LevelDB 文件
-			fileTypes[filePath] = types.TypeCache
-			
-		// 历史文件
-		case strings.Contains(pathLower, "history"):
-			fileTypes[filePath] = types.TypeBackup
-			
-		// 图片文件
-		case ext == ".jpg" || ext == ".jpeg" || ext == ".png" || ext == ".gif" || ext == ".webp":
-			fileTypes[filePath] = types.TypeImage
-			
-		// 备份文件
-		case ext == ".zip" || ext == ".tar" || ext == ".gz" || strings.Contains(name, "backup"):
-			fileTypes[filePath] = types.TypeBackup
-			
-		// 其他 JSON/配置文件
-		case ext == ".json" || ext == ".xml" || ext == ".yaml" || ext == ".yml":
-			if strings.Contains(name, "session") {
-				fileTypes[filePath] = types.TypeDatabase
-			} else {
-				fileTypes[filePath] = types.TypeConfig
-			}
-			
-		default:
-			fileTypes[filePath] = types.TypeUnknown
-		}
+		fileTypes[filePath] = ClassifyFile(filePath)
 		
 		return nil
 	})
@@ -211,11 +118,116 @@ func (sd *StorageDetector) DetectFileTypes(path string) (map[string]types.FileTy
 	return fileTypes, err
 }
 
+// ClassifyFile 根据路径判断文件类型（扫描和 watch 共用）
+func ClassifyFile(filePath string) types.FileType {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	name := strings.ToLower(filepath.Base(filePath))
+	pathLower := strings.ToLower(filePath)
+	
+	switch {
+	// 数据库文件
+	case ext == ".db" || ext == ".sqlite" || ext == ".sqlite3" || ext == ".vscdb":
+		// 区分索引数据库和普通数据库
+		if strings.Contains(pathLower, "/index/") || strings.Contains(pathLower, "lancedb") {
+			return types.TypeIndex
+		} else {
+			return types.TypeDatabase
+		}
+	case ext == ".chat":
+		return types.TypeDatabase
+	
+	// 索引文件（向量数据库等）
+	case strings.Contains(pathLower, "/index/") || 
+		strings.Contains(pathLower, "lancedb") ||
+		ext == ".lance" || ext == ".manifest" || ext == ".txn":
+		return types.TypeIndex
+		
+	// 代码差异/历史版本缓存（kiroagent 目录下非 .chat 文件）
+	case strings.Contains(pathLower, "kiro.kiroagent") && 
+		!strings.HasSuffix(name, ".chat") &&
+		!strings.Contains(pathLower, "/index/") &&
+		!strings.Contains(pathLower, "lancedb") &&
+		!strings.Contains(pathLower, "workspace-sessions") &&
+		!strings.Contains(pathLower, "dev_data") &&
+		!strings.Contains(pathLower, "/default/"):
+		return types.TypeCache
+		
+	// 配置文件（不应删除）
+	case name == "config.json" || name == "settings.json" || name == "mcp.json" ||
+		name == "preferences" || name == "machineid" || name == "machineid.json" ||
+		name == "languagepacks.json" || name == "code.lock":
+		return types.TypeConfig
+		
+	// 日志文件
+	case ext == ".log" || strings.Contains(pathLower, "/logs/") || strings.Contains(name, ".log"):
+		return types.TypeLog
+		
+	// 崩溃报告（由 crashpad 模块按保留策略处理）
+	case strings.Contains(pathLower, "crashpad"):
+		return types.TypeCrash
+		
+	// 临时文件
+	case ext == ".tmp" || ext == ".temp" || name == "temp" ||
+		strings.HasPrefix(name, ".dev.kiro.desktop") ||
+		name == "code.lock" || ext == ".sock":
+		return types.TypeTemp
+		
+	// Electron/Chrome 数据文件（可清理但可能影响登录）
+	case name == "cookies" || name == "cookies-journal" ||
+		name == "dips" || name == "dips-wal" ||
+		name == "sharedstorage" || name == "sharedstorage-wal" ||
+		name == "trust tokens" || name == "trust tokens-journal" ||
+		name == "network persistent state" || name == "transportsecurity":
+		return types.TypeCache
+		
+	// 缓存文件（各种缓存目录）
+	case strings.Contains(pathLower, "cache") ||
+		strings.Contains(pathLower, "cacheddata") ||
+		strings.Contains(pathLower, "cachedprofilesdata") ||
+		strings.Contains(pathLower, "gpucache") ||
+		strings.Contains(pathLower, "dawnwebgpucache") ||
+		strings.Contains(pathLower, "dawngraphitecache") ||
+		strings.Contains(pathLower, "code cache") ||
+		strings.Contains(pathLower, "service worker") ||
+		strings.Contains(pathLower, "local storage") ||
+		strings.Contains(pathLower, "webstorage") ||
+		strings.Contains(pathLower, "session storage") ||
+		strings.Contains(pathLower, "blob_storage") ||
+		strings.Contains(pathLower, "shared dictionary") ||
+		strings.Contains(pathLower, "leveldb") ||
+		ext == ".ldb" || ext == ".sst": // LevelDB 文件
+		return types.TypeCache
+		
+	// 历史文件
+	case strings.Contains(pathLower, "history"):
+		return types.TypeBackup
+		
+	// 图片文件
+	case ext == ".jpg" || ext == ".jpeg" || ext == ".png" || ext == ".gif" || ext == ".webp":
+		return types.TypeImage
+		
+	// 备份文件
+	case ext == ".zip" || ext == ".tar" || ext == ".gz" || strings.Contains(name, "backup"):
+		return types.TypeBackup
+		
+	// 其他 JSON/配置文件
+	case ext == ".json" || ext == ".xml" || ext == ".yaml" || ext == ".yml":
+		if strings.Contains(name, "session") {
+			return types.TypeDatabase
+		} else {
+			return types.TypeConfig
+		}
+		
+	default:
+		return types.TypeUnknown
+	}
+}
+
 // GetDirectorySize 获取目录大小
 func (sd *StorageDetector) GetDirectorySize(path string) (int64, error) {
 	var totalSize int64
 	
-	err := filepath.Walk(path, func(filePath string, info os.FileInfo, err error) error {
+	err := safety.Walk(path, func(filePath string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // 跳过无法访问的文件
 		}
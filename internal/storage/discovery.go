@@ -0,0 +1,338 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+)
+
+// UserDataDirEnv 指定 Kiro 用户数据目录的环境变量
+const UserDataDirEnv = "KIRO_USER_DATA_DIR"
+
+// PortableEnv 便携版 Kiro 的数据目录（与 VS Code 的 VSCODE_PORTABLE 约定一致）
+const PortableEnv = "KIRO_PORTABLE"
+
+// Install 一个 Kiro 安装的用户数据目录
+type Install struct {
+	Label  string `json:"label"`  // 唯一标签，用于 --install 选择
+	Source string `json:"source"` // 发现来源（default、channel、flatpak、snap、env、process、portable、config 等）
+	Path   string `json:"path"`
+}
+
+// Candidate 发现来源给出的候选目录，不存在的目录会被忽略
+type Candidate struct {
+	Label string // 建议的标签，重复时自动加序号
+	Path  string
+}
+
+// Source 安装发现来源
+type Source interface {
+	Name() string
+	Candidates() []Candidate
+}
+
+// SourceFunc 用函数实现的发现来源
+type SourceFunc struct {
+	SourceName string
+	Find       func() []Candidate
+}
+
+// Name 返回来源名称
+func (s SourceFunc) Name() string {
+	return s.SourceName
+}
+
+// Candidates 返回候选目录
+func (s SourceFunc) Candidates() []Candidate {
+	return s.Find()
+}
+
+var (
+	discoveryMu sync.Mutex
+	sources     []Source
+	selected    *Install // UseInstall 选择的安装
+)
+
+func init() {
+	pf := NewPathFinder()
+	sources = []Source{
+		SourceFunc{"default", pf.defaultCandidates},
+		SourceFunc{"channel", pf.channelCandidates},
+		SourceFunc{"flatpak", pf.flatpakCandidates},
+		SourceFunc{"snap", pf.snapCandidates},
+		SourceFunc{"env", envCandidates},
+		SourceFunc{"portable", portableCandidates},
+		SourceFunc{"process", processCandidates},
+	}
+}
+
+// RegisterSource 添加发现来源，结果按注册顺序排在内置来源之后
+func RegisterSource(s Source) {
+	discoveryMu.Lock()
+	defer discoveryMu.Unlock()
+	sources = append(sources, s)
+}
+
+// DiscoverInstalls 返回所有存在的 Kiro 安装，同一目录只出现一次（以最先发现的来源为准）
+// 每次调用都重新检查，环境变量和运行中的进程变化后结果随之变化
+func DiscoverInstalls() []Install {
+	discoveryMu.Lock()
+	list := append([]Source(nil), sources...)
+	discoveryMu.Unlock()
+
+	discovered := []Install{}
+	labels := make(map[string]bool)
+	var seen []os.FileInfo
+	for _, source := range list {
+		for _, c := range source.Candidates() {
+			info, err := os.Stat(c.Path)
+			if err != nil || !info.IsDir() || sameAsAny(info, seen) {
+				continue
+			}
+			seen = append(seen, info)
+			discovered = append(discovered, Install{
+				Label:  uniqueLabel(c.Label, labels),
+				Source: source.Name(),
+				Path:   filepath.Clean(c.Path),
+			})
+		}
+	}
+	return discovered
+}
+
+// UseInstall 只使用指定标签的安装（空标签表示全部），之后的 FindKiroPaths 只返回该安装
+// 标签只在选择时解析一次：之后进程退出等原因使来源消失时，范围也不会扩大到其他安装
+func UseInstall(label string) error {
+	var install *Install
+	if label != "" {
+		in, ok := findInstall(DiscoverInstalls(), label)
+		if !ok {
+			return fmt.Errorf("未找到标签为 %s 的 Kiro 安装（可选 %s）", label, strings.Join(installLabels(), ", "))
+		}
+		install = &in
+	}
+	discoveryMu.Lock()
+	selected = install
+	discoveryMu.Unlock()
+	return nil
+}
+
+// SelectedInstall 返回 UseInstall 选择的安装
+func SelectedInstall() (Install, bool) {
+	discoveryMu.Lock()
+	defer discoveryMu.Unlock()
+	if selected == nil {
+		return Install{}, false
+	}
+	return *selected, true
+}
+
+func findInstall(installs []Install, label string) (Install, bool) {
+	for _, in := range installs {
+		if in.Label == label {
+			return in, true
+		}
+	}
+	return Install{}, false
+}
+
+func installLabels() []string {
+	var labels []string
+	for _, in := range DiscoverInstalls() {
+		labels = append(labels, in.Label)
+	}
+	sort.Strings(labels)
+	return labels
+}
+
+// uniqueLabel 标签重复时加序号（如 config-2）
+func uniqueLabel(label string, used map[string]bool) string {
+	if label == "" {
+		label = "kiro"
+	}
+	unique := label
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s-%d", label, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// sameAsAny 判断目录是否已经发现过（大小写不敏感的文件系统上 kiro 和 Kiro 是同一目录）
+func sameAsAny(info os.FileInfo, seen []os.FileInfo) bool {
+	for _, s := range seen {
+		if os.SameFile(info, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// configRoot 返回当前系统存放应用数据目录的位置
+func (pf *PathFinder) configRoot() string {
+	switch pf.osType {
+	case "darwin":
+		return filepath.Join(os.Getenv("HOME"), "Library", "Application Support")
+	case "windows":
+		return os.Getenv("APPDATA")
+	case "linux":
+		if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+			return xdg
+		}
+		return filepath.Join(os.Getenv("HOME"), ".config")
+	}
+	return ""
+}
+
+// defaultCandidates 稳定版的默认位置
+func (pf *PathFinder) defaultCandidates() []Candidate {
+	var list []Candidate
+	for _, path := range pf.getCommonPaths() {
+		list = append(list, Candidate{Label: "default", Path: path})
+	}
+	return list
+}
+
+// channelCandidates 同一位置下的其他发布渠道（如 "Kiro - Insiders"、kiro-preview）
+func (pf *PathFinder) channelCandidates() []Candidate {
+	root := pf.configRoot()
+	if root == "" {
+		return nil
+	}
+	var list []Candidate
+	for _, dir := range kiroDirs(root) {
+		if channel := channelName(filepath.Base(dir)); channel != "" {
+			list = append(list, Candidate{Label: channel, Path: dir})
+		}
+	}
+	return list
+}
+
+// flatpakCandidates Flatpak 沙盒中的数据目录（~/.var/app/<id>/config/Kiro）
+func (pf *PathFinder) flatpakCandidates() []Candidate {
+	if pf.osType != "linux" {
+		return nil
+	}
+	apps, _ := filepath.Glob(filepath.Join(os.Getenv("HOME"), ".var", "app", "*"))
+	return sandboxCandidates("flatpak", apps, "config")
+}
+
+// snapCandidates Snap 沙盒中的数据目录（~/snap/<name>/current/.config/Kiro）
+func (pf *PathFinder) snapCandidates() []Candidate {
+	if pf.osType != "linux" {
+		return nil
+	}
+	snaps, _ := filepath.Glob(filepath.Join(os.Getenv("HOME"), "snap", "*"))
+	return sandboxCandidates("snap", snaps, filepath.Join("current", ".config"))
+}
+
+// sandboxCandidates 在名称包含 kiro 的沙盒应用目录中查找 Kiro 数据目录
+func sandboxCandidates(label string, appDirs []string, configDir string) []Candidate {
+	var list []Candidate
+	for _, app := range appDirs {
+		if !strings.Contains(strings.ToLower(filepath.Base(app)), "kiro") {
+			continue
+		}
+		for _, dir := range kiroDirs(filepath.Join(app, configDir)) {
+			name := label
+			if channel := channelName(filepath.Base(dir)); channel != "" {
+				name = label + "-" + channel
+			}
+			list = append(list, Candidate{Label: name, Path: dir})
+		}
+	}
+	return list
+}
+
+// envCandidates KIRO_USER_DATA_DIR 指定的目录
+func envCandidates() []Candidate {
+	if dir := os.Getenv(UserDataDirEnv); dir != "" {
+		return []Candidate{{Label: "env", Path: dir}}
+	}
+	return nil
+}
+
+// portableCandidates 便携版的数据目录：KIRO_PORTABLE/user-data，
+// 以及正在运行的 Kiro 可执行文件旁的 data/user-data
+func portableCandidates() []Candidate {
+	var list []Candidate
+	if dir := os.Getenv(PortableEnv); dir != "" {
+		list = append(list, Candidate{Label: "portable", Path: filepath.Join(dir, "user-data")})
+	}
+	for _, p := range kiroProcesses() {
+		exe := p.Exe
+		if exe == "" && len(p.Cmdline) > 0 && filepath.IsAbs(p.Cmdline[0]) {
+			exe = p.Cmdline[0]
+		}
+		if exe != "" {
+			list = append(list, Candidate{Label: "portable", Path: filepath.Join(filepath.Dir(exe), "data", "user-data")})
+		}
+	}
+	return list
+}
+
+// processCandidates 正在运行的 Kiro 通过 --user-data-dir 指定的目录（命令行仅 Linux 可读）
+func processCandidates() []Candidate {
+	var list []Candidate
+	for _, p := range kiroProcesses() {
+		if dir := UserDataDirArg(p.Cmdline); dir != "" {
+			list = append(list, Candidate{Label: "process", Path: dir})
+		}
+	}
+	return list
+}
+
+func kiroProcesses() []utils.KiroProcess {
+	if runtime.GOOS != "linux" {
+		return nil
+	}
+	_, processes, _ := utils.IsKiroRunning()
+	return processes
+}
+
+// UserDataDirArg 从命令行中读取 --user-data-dir 的值（支持 "--user-data-dir=x" 和 "--user-data-dir x"）
+func UserDataDirArg(args []string) string {
+	const flag = "--user-data-dir"
+	for i, arg := range args {
+		if strings.HasPrefix(arg, flag+"=") {
+			return strings.TrimPrefix(arg, flag+"=")
+		}
+		if arg == flag && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
+
+// kiroDirs 列出目录下名为 kiro 或以 "kiro " / "kiro-" 开头的子目录（不区分大小写，排除 kiro-cleaner）
+func kiroDirs(root string) []string {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+	var dirs []string
+	for _, entry := range entries {
+		name := strings.ToLower(entry.Name())
+		if !entry.IsDir() || strings.HasPrefix(name, "kiro-cleaner") {
+			continue
+		}
+		if name == "kiro" || strings.HasPrefix(name, "kiro ") || strings.HasPrefix(name, "kiro-") {
+			dirs = append(dirs, filepath.Join(root, entry.Name()))
+		}
+	}
+	return dirs
+}
+
+// channelName 返回数据目录名中的发布渠道（"Kiro - Insiders" -> insiders），稳定版返回空
+func channelName(dir string) string {
+	name := strings.ToLower(dir)
+	name = strings.TrimPrefix(name, "kiro")
+	name = strings.Trim(name, " -_")
+	return strings.Join(strings.Fields(strings.NewReplacer("-", " ", "_", " ").Replace(name)), "-")
+}
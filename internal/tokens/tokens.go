@@ -0,0 +1,162 @@
+package tokens
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"unicode"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// Estimator 估算文本的 token 数
+// 实现应当是纯本地计算，可以替换为与具体模型一致的分词器
+type Estimator interface {
+	Name() string
+	Estimate(text string) int
+}
+
+// HeuristicName 内置启发式估算器的名称
+const HeuristicName = "heuristic"
+
+// Heuristic 不依赖网络和词表的启发式估算，接近常见 BPE 分词器的结果：
+// 英文单词约每 4 个字母一个 token，数字约每 3 位一个，
+// 标点和符号各一个，中日韩字符各一个，空白不计
+type Heuristic struct{}
+
+// Name 返回估算器名称
+func (Heuristic) Name() string {
+	return HeuristicName
+}
+
+// Estimate 估算 token 数
+func (Heuristic) Estimate(text string) int {
+	count := 0
+	letters, digits := 0, 0
+	flush := func() {
+		count += (letters + 3) / 4
+		count += (digits + 2) / 3
+		letters, digits = 0, 0
+	}
+	for _, r := range text {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || r == '_'):
+			if digits > 0 {
+				flush()
+			}
+			letters++
+		case unicode.IsDigit(r):
+			if letters > 0 {
+				flush()
+			}
+			digits++
+		case unicode.IsSpace(r):
+			flush()
+		case unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+			unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r):
+			flush()
+			count++
+		case unicode.IsLetter(r):
+			// 其他文字（带重音的拉丁字母、西里尔字母等）按字母计，但更密集
+			if digits > 0 {
+				flush()
+			}
+			letters += 2
+		default:
+			flush()
+			count++
+		}
+	}
+	flush()
+	return count
+}
+
+var (
+	mu         sync.RWMutex
+	estimators           = map[string]Estimator{HeuristicName: Heuristic{}}
+	current    Estimator = Heuristic{}
+)
+
+// Register 注册一个估算器，同名时覆盖
+func Register(e Estimator) {
+	mu.Lock()
+	defer mu.Unlock()
+	estimators[e.Name()] = e
+}
+
+// Names 返回已注册的估算器名称
+func Names() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	names := make([]string, 0, len(estimators))
+	for name := range estimators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Use 切换默认估算器，空名称表示内置启发式估算
+func Use(name string) error {
+	if name == "" {
+		name = HeuristicName
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	e, ok := estimators[name]
+	if !ok {
+		return fmt.Errorf("未知的 token 估算器: %s", name)
+	}
+	current = e
+	return nil
+}
+
+// Default 返回当前的默认估算器
+func Default() Estimator {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// Estimate 用默认估算器估算文本的 token 数
+func Estimate(text string) int {
+	return Default().Estimate(text)
+}
+
+// Count 一个对话按角色统计的 token 数
+type Count struct {
+	Human int `json:"human"`
+	Bot   int `json:"bot"`
+	Tool  int `json:"tool"`
+	Total int `json:"total"`
+}
+
+// CountMessages 按角色估算对话消息的 token 数
+func CountMessages(e Estimator, messages []types.ChatMessage) Count {
+	var c Count
+	for _, msg := range messages {
+		n := e.Estimate(msg.Content)
+		switch msg.Role {
+		case "human":
+			c.Human += n
+		case "bot":
+			c.Bot += n
+		case "tool":
+			c.Tool += n
+		}
+		c.Total += n
+	}
+	return c
+}
+
+// Format 格式化 token 数（如 950、12.3k、1.2M）
+func Format(n int) string {
+	switch {
+	case n < 1000:
+		return fmt.Sprintf("%d", n)
+	case n < 1000000:
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%.1fM", float64(n)/1000000)
+	}
+}
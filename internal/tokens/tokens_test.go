@@ -0,0 +1,73 @@
+package tokens
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+func TestHeuristicEstimate(t *testing.T) {
+	h := Heuristic{}
+	cases := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"   \n\t", 0},
+		{"hello world", 4},    // 5 个字母 -> 2，两个单词
+		{"a b c", 3},          // 短单词各一个
+		{"func main() {}", 6}, // func, main, ( ) { } 各一个
+		{"12345678", 3},       // 数字每 3 位一个
+		{"v2", 2},             // 字母和数字分开
+		{"你好，世界", 5},          // 中文字符和全角标点各一个
+		{"naïve", 2},          // ï 按两个字母计
+		{strings.Repeat("x", 400), 100},
+	}
+	for _, c := range cases {
+		if got := h.Estimate(c.text); got != c.want {
+			t.Errorf("Estimate(%q) = %d, 期望 %d", c.text, got, c.want)
+		}
+	}
+}
+
+type fixed struct{}
+
+func (fixed) Name() string             { return "fixed" }
+func (fixed) Estimate(text string) int { return 10 }
+
+func TestRegisterAndUse(t *testing.T) {
+	defer Use(HeuristicName)
+
+	Register(fixed{})
+	if err := Use("fixed"); err != nil {
+		t.Fatal(err)
+	}
+	if Estimate("anything") != 10 || Default().Name() != "fixed" {
+		t.Error("应使用切换后的估算器")
+	}
+	if err := Use("missing"); err == nil {
+		t.Error("未注册的估算器应返回错误")
+	}
+	if names := Names(); len(names) != 2 || names[0] != "fixed" {
+		t.Errorf("Names = %v", names)
+	}
+
+	c := CountMessages(Default(), []types.ChatMessage{
+		{Role: "human", Content: "a"},
+		{Role: "bot", Content: "b"},
+		{Role: "tool", Content: "c"},
+		{Role: "tool", Content: "d"},
+	})
+	if c != (Count{Human: 10, Bot: 10, Tool: 20, Total: 40}) {
+		t.Errorf("CountMessages = %+v", c)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for n, want := range map[int]string{950: "950", 12345: "12.3k", 1250000: "1.2M"} {
+		if got := Format(n); got != want {
+			t.Errorf("Format(%d) = %s, 期望 %s", n, got, want)
+		}
+	}
+}
@@ -0,0 +1,271 @@
+package trend
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 快照中的存储类别
+const (
+	CategoryConversations = "conversations"
+	CategoryLogs          = "logs"
+	CategoryCache         = "cache"
+	CategoryIndex         = "index"
+	CategoryHistory       = "history"
+	CategoryCrash         = "crash"
+	CategoryTemp          = "temp"
+	CategoryOther         = "other"
+)
+
+// Categories 按显示顺序排列的类别
+var Categories = []string{
+	CategoryConversations,
+	CategoryLogs,
+	CategoryCache,
+	CategoryIndex,
+	CategoryHistory,
+	CategoryCrash,
+	CategoryTemp,
+	CategoryOther,
+}
+
+// Snapshot 一次扫描的存储快照
+type Snapshot struct {
+	Time          time.Time        `json:"time"`
+	Total         int64            `json:"total"`
+	Categories    map[string]int64 `json:"categories"`           // 类别 -> 字节数
+	Conversations int              `json:"conversations"`        // 对话数量
+	Workspaces    map[string]int64 `json:"workspaces,omitempty"` // 工作区路径 -> 对话字节数
+}
+
+// Point 时间序列中的一个点
+type Point struct {
+	Time  time.Time
+	Value int64
+}
+
+// Store 快照存储（JSONL，每次扫描追加一行）
+type Store struct {
+	path string
+}
+
+// NewStore 创建快照存储
+func NewStore(path string) *Store {
+	return &Store{path: path}
+}
+
+// Path 返回存储文件路径
+func (s *Store) Path() string {
+	return s.path
+}
+
+// Append 追加一个快照
+func (s *Store) Append(snap Snapshot) error {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("序列化快照失败: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("创建快照目录失败: %v", err)
+	}
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("打开快照文件失败: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("写入快照失败: %v", err)
+	}
+	return nil
+}
+
+// Load 读取 since 之后的快照，按时间从旧到新排序，跳过无法解析的行
+func (s *Store) Load(since time.Time) ([]Snapshot, error) {
+	f, err := os.Open(s.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取快照失败: %v", err)
+	}
+	defer f.Close()
+
+	var snaps []Snapshot
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var snap Snapshot
+		if err := json.Unmarshal([]byte(line), &snap); err != nil {
+			continue
+		}
+		if !since.IsZero() && snap.Time.Before(since) {
+			continue
+		}
+		snaps = append(snaps, snap)
+	}
+	if err := scanner.Err(); err != nil {
+		return snaps, fmt.Errorf("读取快照失败: %v", err)
+	}
+
+	sort.SliceStable(snaps, func(i, j int) bool {
+		return snaps[i].Time.Before(snaps[j].Time)
+	})
+	return snaps, nil
+}
+
+// Bucket 按时间段（如一天、一周）合并快照，每个时间段保留最后一个快照
+func Bucket(snaps []Snapshot, period time.Duration) []Snapshot {
+	if period <= 0 || len(snaps) == 0 {
+		return snaps
+	}
+	var result []Snapshot
+	var current time.Time
+	for _, snap := range snaps {
+		key := bucketStart(snap.Time, period)
+		if len(result) > 0 && key.Equal(current) {
+			result[len(result)-1] = snap
+			continue
+		}
+		current = key
+		result = append(result, snap)
+	}
+	return result
+}
+
+// bucketStart 返回时间所在时间段的起点（按本地时间的天对齐）
+func bucketStart(t time.Time, period time.Duration) time.Time {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	days := int64(period / (24 * time.Hour))
+	if days <= 1 {
+		return day
+	}
+	// 以周一为一周的开始
+	if days == 7 {
+		offset := (int(day.Weekday()) + 6) % 7
+		return day.AddDate(0, 0, -offset)
+	}
+	n := day.Unix() / 86400
+	return day.AddDate(0, 0, -int(n%days))
+}
+
+// Series 提取某个类别的时间序列（"total" 表示总大小）
+func Series(snaps []Snapshot, category string) []Point {
+	points := make([]Point, 0, len(snaps))
+	for _, snap := range snaps {
+		value := snap.Total
+		if category != "total" {
+			value = snap.Categories[category]
+		}
+		points = append(points, Point{Time: snap.Time, Value: value})
+	}
+	return points
+}
+
+// WorkspaceSeries 提取某个工作区的时间序列
+func WorkspaceSeries(snaps []Snapshot, workspace string) []Point {
+	points := make([]Point, 0, len(snaps))
+	for _, snap := range snaps {
+		points = append(points, Point{Time: snap.Time, Value: snap.Workspaces[workspace]})
+	}
+	return points
+}
+
+// Values 返回时间序列中的值
+func Values(points []Point) []int64 {
+	values := make([]int64, len(points))
+	for i, p := range points {
+		values[i] = p.Value
+	}
+	return values
+}
+
+// Change 返回时间序列首尾的差值
+func Change(points []Point) int64 {
+	if len(points) < 2 {
+		return 0
+	}
+	return points[len(points)-1].Value - points[0].Value
+}
+
+// DailyRate 用最小二乘法估算每天的增长字节数
+func DailyRate(points []Point) float64 {
+	if len(points) < 2 {
+		return 0
+	}
+	origin := points[0].Time
+	var sumX, sumY, sumXY, sumXX float64
+	for _, p := range points {
+		x := p.Time.Sub(origin).Hours() / 24
+		y := float64(p.Value)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	n := float64(len(points))
+	denom := n*sumXX - sumX*sumX
+	if denom == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom
+}
+
+// ETA 估算时间序列按当前增长速度何时达到阈值
+// 已超过阈值时返回最后一个点的时间；不增长时返回 false
+func ETA(points []Point, threshold int64) (time.Time, bool) {
+	if len(points) == 0 || threshold <= 0 {
+		return time.Time{}, false
+	}
+	last := points[len(points)-1]
+	if last.Value >= threshold {
+		return last.Time, true
+	}
+	rate := DailyRate(points)
+	if rate <= 0 {
+		return time.Time{}, false
+	}
+	days := float64(threshold-last.Value) / rate
+	// 超过 10 年的估算没有意义
+	if days > 3650 {
+		return time.Time{}, false
+	}
+	return last.Time.Add(time.Duration(days * float64(24*time.Hour))), true
+}
+
+// sparkTicks 迷你图字符，从低到高
+var sparkTicks = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline 将一组数值绘制为迷你图
+func Sparkline(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+	lo, hi := values[0], values[0]
+	for _, v := range values {
+		if v < lo {
+			lo = v
+		}
+		if v > hi {
+			hi = v
+		}
+	}
+	var b strings.Builder
+	for _, v := range values {
+		idx := 0
+		if hi > lo {
+			idx = int(float64(v-lo) / float64(hi-lo) * float64(len(sparkTicks)-1))
+		}
+		b.WriteRune(sparkTicks[idx])
+	}
+	return b.String()
+}
@@ -0,0 +1,128 @@
+package trend
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// day 返回基准时间之后第 n 天的中午
+func day(n int) time.Time {
+	return time.Date(2025, 3, 3, 12, 0, 0, 0, time.Local).AddDate(0, 0, n)
+}
+
+func TestStore_AppendAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trend.jsonl")
+	store := NewStore(path)
+
+	store.Append(Snapshot{Time: day(2), Total: 300, Categories: map[string]int64{CategoryLogs: 30}})
+	store.Append(Snapshot{Time: day(0), Total: 100})
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	f.WriteString("not json\n")
+	f.Close()
+	store.Append(Snapshot{Time: day(1), Total: 200})
+
+	snaps, err := store.Load(time.Time{})
+	if err != nil {
+		t.Fatalf("读取快照失败: %v", err)
+	}
+	if len(snaps) != 3 {
+		t.Fatalf("应读取 3 个快照（跳过损坏的行），实际 %d", len(snaps))
+	}
+	if snaps[0].Total != 100 || snaps[2].Total != 300 {
+		t.Error("快照应按时间从旧到新排序")
+	}
+	if snaps[2].Categories[CategoryLogs] != 30 {
+		t.Error("类别大小应保留")
+	}
+
+	recent, _ := store.Load(day(1))
+	if len(recent) != 2 {
+		t.Errorf("since 之前的快照应被过滤，实际 %d", len(recent))
+	}
+
+	none, err := NewStore(filepath.Join(t.TempDir(), "none.jsonl")).Load(time.Time{})
+	if err != nil || len(none) != 0 {
+		t.Errorf("文件不存在时应返回空列表: %v", err)
+	}
+}
+
+func TestBucket(t *testing.T) {
+	snaps := []Snapshot{
+		{Time: day(0), Total: 1},
+		{Time: day(0).Add(3 * time.Hour), Total: 2},
+		{Time: day(1), Total: 3},
+		{Time: day(7), Total: 4},
+	}
+
+	daily := Bucket(snaps, 24*time.Hour)
+	if len(daily) != 3 || daily[0].Total != 2 {
+		t.Errorf("按天合并应保留每天最后一个快照: %+v", daily)
+	}
+
+	// day(0) 是周一，day(7) 是下一周
+	weekly := Bucket(snaps, 7*24*time.Hour)
+	if len(weekly) != 2 || weekly[0].Total != 3 || weekly[1].Total != 4 {
+		t.Errorf("按周合并结果错误: %+v", weekly)
+	}
+}
+
+func TestDailyRateAndETA(t *testing.T) {
+	var points []Point
+	for i := 0; i < 5; i++ {
+		points = append(points, Point{Time: day(i), Value: int64(1000 + 100*i)})
+	}
+
+	if rate := DailyRate(points); rate < 99.9 || rate > 100.1 {
+		t.Errorf("每天增长应为 100，实际 %f", rate)
+	}
+	if Change(points) != 400 {
+		t.Errorf("总变化应为 400，实际 %d", Change(points))
+	}
+
+	eta, ok := ETA(points, 2000)
+	if !ok {
+		t.Fatal("持续增长时应能估算")
+	}
+	if want := day(10); eta.Sub(want).Abs() > time.Minute {
+		t.Errorf("预计时间错误: %v, want %v", eta, want)
+	}
+
+	if eta, ok := ETA(points, 1200); !ok || !eta.Equal(day(4)) {
+		t.Error("已超过阈值时应返回最后一个快照的时间")
+	}
+
+	flat := []Point{{Time: day(0), Value: 500}, {Time: day(1), Value: 400}}
+	if _, ok := ETA(flat, 1000); ok {
+		t.Error("不增长时不应给出估算")
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	if got := Sparkline([]int64{0, 7, 14}); got != "▁▄█" {
+		t.Errorf("Sparkline = %q", got)
+	}
+	if got := Sparkline([]int64{5, 5}); got != "▁▁" {
+		t.Errorf("数值相同时应为最低刻度: %q", got)
+	}
+	if Sparkline(nil) != "" {
+		t.Error("空序列应返回空字符串")
+	}
+}
+
+func TestSeries(t *testing.T) {
+	snaps := []Snapshot{
+		{Time: day(0), Total: 10, Categories: map[string]int64{CategoryCache: 4}, Workspaces: map[string]int64{"/ws": 1}},
+		{Time: day(1), Total: 20, Categories: map[string]int64{CategoryCache: 8}},
+	}
+	if v := Values(Series(snaps, "total")); v[0] != 10 || v[1] != 20 {
+		t.Errorf("total 序列错误: %v", v)
+	}
+	if v := Values(Series(snaps, CategoryCache)); v[0] != 4 || v[1] != 8 {
+		t.Errorf("cache 序列错误: %v", v)
+	}
+	if v := Values(WorkspaceSeries(snaps, "/ws")); v[0] != 1 || v[1] != 0 {
+		t.Errorf("工作区序列错误: %v", v)
+	}
+}
@@ -0,0 +1,210 @@
+package usage
+
+import (
+	"sort"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// 分组维度
+const (
+	ByModel    = "model"
+	ByProvider = "provider"
+	ByWorkflow = "workflow"
+)
+
+// Dimensions 所有分组维度，按显示顺序
+var Dimensions = []string{ByModel, ByProvider, ByWorkflow}
+
+// Unknown 元数据缺失时的分组名
+const Unknown = "unknown"
+
+// Options 报告选项
+type Options struct {
+	Weekly  bool      // 活动按周统计（默认按天）
+	Buckets int       // 活动统计的时间段数量（截止到 Now）
+	Now     time.Time // 统计截止时间
+}
+
+// Group 一个分组（如某个模型）的统计
+type Group struct {
+	Name           string        `json:"name"`
+	Conversations  int           `json:"conversations"`
+	Messages       int           `json:"messages"`
+	HumanMessages  int           `json:"human_messages"`
+	BotMessages    int           `json:"bot_messages"`
+	ToolMessages   int           `json:"tool_messages"`
+	Size           int64         `json:"size"`
+	Tokens         int           `json:"tokens"`          // 估算的 token 数
+	MedianDuration time.Duration `json:"median_duration"` // 有开始和结束时间的对话的时长中位数
+	Activity       []int64       `json:"activity"`        // 每个时间段开始的对话数，最后一个为当前时间段
+	LastActivity   time.Time     `json:"last_activity"`
+
+	durations []time.Duration
+}
+
+// Report 按模型、提供者和工作流分组的对话统计
+type Report struct {
+	Total   Group              `json:"total"`
+	Groups  map[string][]Group `json:"groups"`  // 维度 -> 分组，按大小降序
+	Periods []time.Time        `json:"periods"` // 活动统计各时间段的起点
+}
+
+// Build 根据解析后的对话生成统计报告
+func Build(chats []types.ChatFileInfo, opts Options) *Report {
+	if opts.Buckets <= 0 {
+		opts.Buckets = 30
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	periods := periodStarts(opts)
+
+	report := &Report{
+		Total:   Group{Name: "total", Activity: make([]int64, len(periods))},
+		Groups:  make(map[string][]Group),
+		Periods: periods,
+	}
+	index := make(map[string]map[string]*Group)
+	for _, dim := range Dimensions {
+		index[dim] = make(map[string]*Group)
+	}
+
+	for _, chat := range chats {
+		start, end := chatTimes(chat)
+		bucket := bucketIndex(periods, opts, start)
+		add(&report.Total, chat, start, end, bucket)
+		for _, dim := range Dimensions {
+			name := groupName(chat.Metadata, dim)
+			g, ok := index[dim][name]
+			if !ok {
+				g = &Group{Name: name, Activity: make([]int64, len(periods))}
+				index[dim][name] = g
+			}
+			add(g, chat, start, end, bucket)
+		}
+	}
+
+	report.Total.finish()
+	for _, dim := range Dimensions {
+		groups := make([]Group, 0, len(index[dim]))
+		for _, g := range index[dim] {
+			g.finish()
+			groups = append(groups, *g)
+		}
+		sort.Slice(groups, func(i, j int) bool {
+			if groups[i].Size != groups[j].Size {
+				return groups[i].Size > groups[j].Size
+			}
+			return groups[i].Name < groups[j].Name
+		})
+		report.Groups[dim] = groups
+	}
+	return report
+}
+
+// groupName 返回对话在某个维度下的分组名
+func groupName(m types.ChatMetadata, dim string) string {
+	var name string
+	switch dim {
+	case ByModel:
+		name = m.ModelID
+	case ByProvider:
+		name = m.ModelProvider
+	case ByWorkflow:
+		name = m.Workflow
+	}
+	if name == "" {
+		return Unknown
+	}
+	return name
+}
+
+// chatTimes 返回对话的开始和结束时间，缺失时用文件修改时间代替开始时间
+func chatTimes(chat types.ChatFileInfo) (start, end time.Time) {
+	if chat.Metadata.StartTime > 0 {
+		start = time.UnixMilli(chat.Metadata.StartTime)
+	}
+	if chat.Metadata.EndTime > 0 {
+		end = time.UnixMilli(chat.Metadata.EndTime)
+	}
+	if start.IsZero() {
+		start = end
+	}
+	if start.IsZero() {
+		start = chat.ModTime
+	}
+	return start, end
+}
+
+func add(g *Group, chat types.ChatFileInfo, start, end time.Time, bucket int) {
+	g.Conversations++
+	g.Messages += chat.MessageCount
+	g.HumanMessages += chat.HumanMessages
+	g.BotMessages += chat.BotMessages
+	g.ToolMessages += chat.ToolMessages
+	g.Size += chat.Size
+	g.Tokens += chat.Tokens
+	if chat.Metadata.StartTime > 0 && !end.IsZero() && !end.Before(start) {
+		g.durations = append(g.durations, end.Sub(start))
+	}
+	if bucket >= 0 {
+		g.Activity[bucket]++
+	}
+	last := end
+	if last.IsZero() {
+		last = start
+	}
+	if last.After(g.LastActivity) {
+		g.LastActivity = last
+	}
+}
+
+func (g *Group) finish() {
+	g.MedianDuration = median(g.durations)
+	g.durations = nil
+}
+
+// median 返回中位数，没有数据时返回 0
+func median(values []time.Duration) time.Duration {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[mid]
+	}
+	return (sorted[mid-1] + sorted[mid]) / 2
+}
+
+// periodStarts 返回截止到 Now 的各时间段起点（按本地时间的天对齐，周从周一开始）
+func periodStarts(opts Options) []time.Time {
+	now := opts.Now
+	last := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	step := 1
+	if opts.Weekly {
+		last = last.AddDate(0, 0, -((int(last.Weekday()) + 6) % 7))
+		step = 7
+	}
+	periods := make([]time.Time, opts.Buckets)
+	for i := range periods {
+		periods[i] = last.AddDate(0, 0, -step*(opts.Buckets-1-i))
+	}
+	return periods
+}
+
+// bucketIndex 返回时间所在的时间段，不在统计范围内时返回 -1
+func bucketIndex(periods []time.Time, opts Options, t time.Time) int {
+	if len(periods) == 0 || t.Before(periods[0]) || t.After(opts.Now) {
+		return -1
+	}
+	for i := len(periods) - 1; i >= 0; i-- {
+		if !t.Before(periods[i]) {
+			return i
+		}
+	}
+	return -1
+}
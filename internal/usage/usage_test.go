@@ -0,0 +1,87 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+func chat(model, provider, workflow string, size int64, start time.Time, minutes int) types.ChatFileInfo {
+	return types.ChatFileInfo{
+		Size:          size,
+		Tokens:        int(size / 4),
+		ModTime:       start,
+		MessageCount:  4,
+		HumanMessages: 1,
+		BotMessages:   1,
+		ToolMessages:  2,
+		Metadata: types.ChatMetadata{
+			ModelID:       model,
+			ModelProvider: provider,
+			Workflow:      workflow,
+			StartTime:     start.UnixMilli(),
+			EndTime:       start.Add(time.Duration(minutes) * time.Minute).UnixMilli(),
+		},
+	}
+}
+
+func TestBuildGroupsByDimension(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.Local)
+	chats := []types.ChatFileInfo{
+		chat("claude-sonnet", "anthropic", "spec", 3000, now.Add(-time.Hour), 10),
+		chat("claude-sonnet", "anthropic", "vibe", 1000, now.AddDate(0, 0, -1), 20),
+		chat("claude-haiku", "anthropic", "spec", 500, now.AddDate(0, 0, -2), 40),
+		{Size: 200, ModTime: now.AddDate(0, 0, -100)}, // 没有元数据
+	}
+
+	r := Build(chats, Options{Buckets: 7, Now: now})
+	if r.Total.Conversations != 4 || r.Total.Size != 4700 || r.Total.Messages != 12 || r.Total.Tokens != 1125 {
+		t.Fatalf("总计不正确: %+v", r.Total)
+	}
+
+	models := r.Groups[ByModel]
+	if len(models) != 3 || models[0].Name != "claude-sonnet" || models[2].Name != Unknown {
+		t.Fatalf("按模型分组不正确: %+v", models)
+	}
+	if models[0].Conversations != 2 || models[0].Size != 4000 || models[0].MedianDuration != 15*time.Minute {
+		t.Errorf("claude-sonnet 统计不正确: %+v", models[0])
+	}
+	if got := models[0].Activity; got[6] != 1 || got[5] != 1 || got[4] != 0 {
+		t.Errorf("按天活动不正确: %v", got)
+	}
+	if models[2].MedianDuration != 0 || sum(models[2].Activity) != 0 {
+		t.Errorf("缺少元数据且超出范围的对话不应计入时长和活动: %+v", models[2])
+	}
+
+	workflows := r.Groups[ByWorkflow]
+	if workflows[0].Name != "spec" || workflows[0].Size != 3500 || workflows[0].MedianDuration != 25*time.Minute {
+		t.Errorf("按工作流分组不正确: %+v", workflows[0])
+	}
+	if providers := r.Groups[ByProvider]; providers[0].Name != "anthropic" || providers[0].Conversations != 3 {
+		t.Errorf("按提供者分组不正确: %+v", providers)
+	}
+}
+
+func TestWeeklyPeriods(t *testing.T) {
+	now := time.Date(2024, 5, 10, 15, 0, 0, 0, time.Local) // 周五
+	chats := []types.ChatFileInfo{
+		chat("m", "p", "w", 1, time.Date(2024, 5, 6, 9, 0, 0, 0, time.Local), 1), // 本周一
+		chat("m", "p", "w", 1, time.Date(2024, 5, 5, 9, 0, 0, 0, time.Local), 1), // 上周日
+	}
+	r := Build(chats, Options{Weekly: true, Buckets: 4, Now: now})
+	if !r.Periods[3].Equal(time.Date(2024, 5, 6, 0, 0, 0, 0, time.Local)) {
+		t.Fatalf("最后一周应从周一开始: %v", r.Periods[3])
+	}
+	if got := r.Total.Activity; got[3] != 1 || got[2] != 1 {
+		t.Errorf("按周活动不正确: %v", got)
+	}
+}
+
+func sum(values []int64) int64 {
+	var total int64
+	for _, v := range values {
+		total += v
+	}
+	return total
+}
@@ -0,0 +1,171 @@
+package utils
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LaunchInfo 重新启动 Kiro 所需的信息
+type LaunchInfo struct {
+	PID  int      // 原主进程ID
+	Exe  string   // 可执行文件路径
+	Args []string // 启动参数（不含可执行文件）
+	Dir  string   // 工作目录
+}
+
+// StopResult 停止 Kiro 的结果
+type StopResult struct {
+	Terminated int           // 收到 TERM 后正常退出的进程数
+	Killed     int           // 超时后被 KILL 的进程数
+	StartedAt  time.Time     // 开始停止的时间
+	Elapsed    time.Duration // 停止耗时
+}
+
+// CaptureLaunchInfo 记录 Kiro 主进程的命令行和工作目录
+func CaptureLaunchInfo(processes []KiroProcess) (*LaunchInfo, error) {
+	main := mainKiroProcess(processes)
+	if main == nil {
+		return nil, fmt.Errorf("未找到 Kiro 主进程")
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if len(main.Cmdline) == 0 {
+			return nil, fmt.Errorf("无法读取进程 %d 的命令行", main.PID)
+		}
+		exe := main.Cmdline[0]
+		if !filepath.IsAbs(exe) && main.Exe != "" {
+			exe = main.Exe
+		}
+		info := &LaunchInfo{PID: main.PID, Exe: exe, Args: main.Cmdline[1:]}
+		if cwd, err := os.Readlink(filepath.Join(DefaultProcRoot, strconv.Itoa(main.PID), "cwd")); err == nil {
+			info.Dir = cwd
+		}
+		return info, nil
+
+	case "darwin":
+		info, err := darwinLaunchInfo(main.PID)
+		if err != nil {
+			return nil, err
+		}
+		if out, err := exec.Command("lsof", "-a", "-p", strconv.Itoa(main.PID), "-d", "cwd", "-Fn").Output(); err == nil {
+			for _, line := range strings.Split(string(out), "\n") {
+				if strings.HasPrefix(line, "n") {
+					info.Dir = strings.TrimPrefix(line, "n")
+				}
+			}
+		}
+		return info, nil
+	}
+
+	return nil, fmt.Errorf("不支持的操作系统: %s", runtime.GOOS)
+}
+
+// darwinLaunchInfo 读取 macOS 进程的原始 argv；读不到时只有在没有参数的情况下才用 ps 的输出，
+// 因为 ps 用空格拼接参数，带空格的参数（如 --user-data-dir "/Users/x/My Data"）无法还原
+func darwinLaunchInfo(pid int) (*LaunchInfo, error) {
+	if exe, args, err := processArgs(pid); err == nil && exe != "" {
+		return &LaunchInfo{PID: pid, Exe: exe, Args: args}, nil
+	}
+
+	exeOut, err := exec.Command("ps", "-o", "comm=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("读取进程 %d 的可执行文件失败: %v", pid, err)
+	}
+	argsOut, err := exec.Command("ps", "-o", "args=", "-p", strconv.Itoa(pid)).Output()
+	if err != nil {
+		return nil, fmt.Errorf("读取进程 %d 的命令行失败: %v", pid, err)
+	}
+	exe := strings.TrimSpace(string(exeOut))
+	if rest := strings.TrimPrefix(strings.TrimSpace(string(argsOut)), exe); strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("无法可靠还原进程 %d 的命令行参数", pid)
+	}
+	return &LaunchInfo{PID: pid, Exe: exe}, nil
+}
+
+// mainKiroProcess 选出需要重新启动的进程
+// AppImage 的挂载点在退出后会消失，因此优先选择 AppImage 启动器
+func mainKiroProcess(processes []KiroProcess) *KiroProcess {
+	var main *KiroProcess
+	for i := range processes {
+		p := &processes[i]
+		if strings.HasSuffix(strings.ToLower(p.Exe), ".appimage") {
+			return p
+		}
+		if main == nil && (p.Type == "main" || p.Name == "Kiro" || p.Name == "Kiro.exe") {
+			main = p
+		}
+	}
+	return main
+}
+
+// StopKiroWithTimeout 先发送 TERM，超时后升级为 KILL
+func StopKiroWithTimeout(timeout time.Duration) (*StopResult, error) {
+	result := &StopResult{StartedAt: time.Now()}
+	running, processes, err := IsKiroRunning()
+	if err != nil {
+		return result, fmt.Errorf("检测 Kiro 进程失败: %v", err)
+	}
+	if !running {
+		return result, nil
+	}
+
+	for _, proc := range processes {
+		// 辅助进程可能随主进程一起退出，忽略发送失败
+		stopProcess(proc.PID, true)
+	}
+
+	if WaitForKiroExit(timeout) {
+		result.Terminated = len(processes)
+		result.Elapsed = time.Since(result.StartedAt)
+		return result, nil
+	}
+
+	_, remaining, _ := IsKiroRunning()
+	for _, proc := range remaining {
+		if err := stopProcess(proc.PID, false); err == nil {
+			result.Killed++
+		}
+	}
+	result.Terminated = len(processes) - len(remaining)
+	if result.Terminated < 0 {
+		result.Terminated = 0
+	}
+
+	if !WaitForKiroExit(5 * time.Second) {
+		result.Elapsed = time.Since(result.StartedAt)
+		return result, fmt.Errorf("Kiro 进程在 KILL 后仍未退出")
+	}
+	result.Elapsed = time.Since(result.StartedAt)
+	return result, nil
+}
+
+// RelaunchKiro 使用记录的命令行和工作目录重新启动 Kiro，返回新进程ID
+func RelaunchKiro(info *LaunchInfo) (int, error) {
+	if info == nil || info.Exe == "" {
+		return 0, fmt.Errorf("缺少 Kiro 启动信息")
+	}
+
+	cmd := exec.Command(info.Exe, info.Args...)
+	cmd.Dir = info.Dir
+	if cmd.Dir != "" {
+		if _, err := os.Stat(cmd.Dir); err != nil {
+			cmd.Dir = ""
+		}
+	}
+	cmd.SysProcAttr = detachedProcAttr()
+
+	if err := cmd.Start(); err != nil {
+		return 0, fmt.Errorf("启动 Kiro 失败: %v", err)
+	}
+	pid := cmd.Process.Pid
+	// 与新进程脱离，kiro-cleaner 退出后 Kiro 继续运行
+	cmd.Process.Release()
+	return pid, nil
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package utils
+
+import "syscall"
+
+// detachedProcAttr 在新会话中启动进程，使其不随终端退出
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,13 @@
+//go:build windows
+
+package utils
+
+import "syscall"
+
+// detachedProcess Windows DETACHED_PROCESS 标志
+const detachedProcess = 0x00000008
+
+// detachedProcAttr 以独立进程组启动，不占用当前控制台
+func detachedProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP | detachedProcess}
+}
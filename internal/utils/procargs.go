@@ -0,0 +1,39 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// ParseProcArgs 解析 macOS kern.procargs2 的内容：argc（int32），可执行文件路径，
+// 填充的 NUL，然后是 argc 个以 NUL 结尾的参数（其后是环境变量）
+// 返回可执行文件路径和不含 argv[0] 的参数，参数中的空格和引号原样保留
+func ParseProcArgs(buf []byte) (string, []string, error) {
+	if len(buf) < 4 {
+		return "", nil, fmt.Errorf("procargs 数据过短")
+	}
+	argc := int(binary.LittleEndian.Uint32(buf[:4]))
+	rest := buf[4:]
+
+	end := bytes.IndexByte(rest, 0)
+	if end < 0 {
+		return "", nil, fmt.Errorf("procargs 缺少可执行文件路径")
+	}
+	exe := string(rest[:end])
+	rest = bytes.TrimLeft(rest[end:], "\x00")
+
+	argv := make([]string, 0, argc)
+	for len(argv) < argc {
+		end := bytes.IndexByte(rest, 0)
+		if end < 0 {
+			return "", nil, fmt.Errorf("procargs 参数不完整")
+		}
+		argv = append(argv, string(rest[:end]))
+		rest = rest[end+1:]
+	}
+	if len(argv) == 0 {
+		return exe, nil, nil
+	}
+	return exe, argv[1:], nil
+}
@@ -0,0 +1,14 @@
+//go:build darwin
+
+package utils
+
+import "golang.org/x/sys/unix"
+
+// processArgs 通过 sysctl 读取进程的原始 argv
+func processArgs(pid int) (string, []string, error) {
+	buf, err := unix.SysctlRaw("kern.procargs2", pid)
+	if err != nil {
+		return "", nil, err
+	}
+	return ParseProcArgs(buf)
+}
@@ -0,0 +1,10 @@
+//go:build !darwin
+
+package utils
+
+import "fmt"
+
+// processArgs 只在 macOS 上使用，Linux 从 /proc/<pid>/cmdline 读取
+func processArgs(pid int) (string, []string, error) {
+	return "", nil, fmt.Errorf("不支持的操作系统")
+}
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"os/exec"
 	"runtime"
 	"strconv"
@@ -11,8 +12,11 @@ import (
 
 // KiroProcess Kiro 进程信息
 type KiroProcess struct {
-	PID  int
-	Name string
+	PID     int
+	Name    string
+	Exe     string   // 可执行文件路径（仅 Linux procfs 可用）
+	Cmdline []string // 命令行参数（仅 Linux procfs 可用）
+	Type    string   // Electron 进程类型：main、renderer、gpu-process 等
 }
 
 // IsKiroRunning 检测 Kiro 是否正在运行
@@ -23,7 +27,8 @@ func IsKiroRunning() (bool, []KiroProcess, error) {
 	case "darwin":
 		// macOS: 使用 pgrep 查找 Kiro 主进程和 Helper 进程
 		// Kiro 应用的进程名通常是 "Kiro" 或 "Kiro Helper"
-		out, err := exec.Command("pgrep", "-l", "Kiro").Output()
+		// 只查找当前用户的进程，其他用户的 Kiro 无法停止，也不会写入当前用户的数据
+		out, err := exec.Command("pgrep", "-l", "-U", strconv.Itoa(os.Getuid()), "Kiro").Output()
 		if err != nil {
 			// pgrep 没找到进程时返回 exit code 1，这不是错误
 			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
@@ -53,8 +58,16 @@ func IsKiroRunning() (bool, []KiroProcess, error) {
 		}
 
 	case "linux":
-		// Linux: 使用 pgrep 查找 Kiro 进程
-		out, err := exec.Command("pgrep", "-l", "-i", "^kiro$").Output()
+		// Linux: 直接读取 /proc，可以识别 Electron 辅助进程和 AppImage 启动器
+		if proc := NewProcFS(""); proc.Available() {
+			found, err := proc.KiroProcesses()
+			if err == nil {
+				return len(found) > 0, found, nil
+			}
+		}
+		
+		// procfs 不可用时回退到 pgrep
+		out, err := exec.Command("pgrep", "-l", "-i", "-U", strconv.Itoa(os.Getuid()), "^kiro$").Output()
 		if err != nil {
 			if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
 				return false, nil, nil
@@ -0,0 +1,241 @@
+package utils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultProcRoot Linux procfs 挂载点
+const DefaultProcRoot = "/proc"
+
+// OpenFile 进程打开的文件
+type OpenFile struct {
+	PID     int
+	Process string // 进程名（可执行文件名，带 Electron 进程类型）
+	Path    string
+}
+
+// ProcFS 通过 /proc 读取进程信息（仅 Linux）
+type ProcFS struct {
+	root    string
+	selfPID int
+	uid     int // 只识别该用户的 Kiro 进程
+}
+
+// NewProcFS 创建 procfs 读取器，root 为空时使用 /proc
+func NewProcFS(root string) *ProcFS {
+	if root == "" {
+		root = DefaultProcRoot
+	}
+	return &ProcFS{root: root, selfPID: os.Getpid(), uid: os.Getuid()}
+}
+
+// Available 判断 procfs 是否可用
+func (p *ProcFS) Available() bool {
+	info, err := os.Stat(p.root)
+	return err == nil && info.IsDir()
+}
+
+// pids 列出所有进程ID
+func (p *ProcFS) pids() ([]int, error) {
+	entries, err := os.ReadDir(p.root)
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %v", p.root, err)
+	}
+	var pids []int
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == p.selfPID {
+			continue
+		}
+		pids = append(pids, pid)
+	}
+	sort.Ints(pids)
+	return pids, nil
+}
+
+// readProcess 读取进程的可执行文件和命令行
+func (p *ProcFS) readProcess(pid int) (exe string, cmdline []string) {
+	dir := filepath.Join(p.root, strconv.Itoa(pid))
+	if target, err := os.Readlink(filepath.Join(dir, "exe")); err == nil {
+		// 升级后旧二进制会显示为 "path (deleted)"
+		exe = strings.TrimSuffix(target, " (deleted)")
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		for _, arg := range bytes.Split(bytes.TrimRight(data, "\x00"), []byte{0}) {
+			cmdline = append(cmdline, string(arg))
+		}
+	}
+	return exe, cmdline
+}
+
+// processUID 读取进程的真实 UID（/proc/<pid>/status 中 Uid: 的第一个值）
+func (p *ProcFS) processUID(pid int) (int, bool) {
+	data, err := os.ReadFile(filepath.Join(p.root, strconv.Itoa(pid), "status"))
+	if err != nil {
+		return 0, false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "Uid:" {
+			uid, err := strconv.Atoi(fields[1])
+			return uid, err == nil
+		}
+	}
+	return 0, false
+}
+
+// KiroProcesses 列出当前用户的 Kiro 主进程、Electron 辅助进程和 AppImage 启动器
+// 其他用户的 Kiro 不计入：多用户的机器上无法也不应停止它们，它们也不会写入当前用户的数据
+func (p *ProcFS) KiroProcesses() ([]KiroProcess, error) {
+	pids, err := p.pids()
+	if err != nil {
+		return nil, err
+	}
+
+	var processes []KiroProcess
+	for _, pid := range pids {
+		exe, cmdline := p.readProcess(pid)
+		if len(cmdline) == 0 && exe == "" {
+			continue // 内核线程或已退出
+		}
+		if !isKiroExecutable(exe, cmdline) {
+			continue
+		}
+		if uid, ok := p.processUID(pid); !ok || uid != p.uid {
+			continue
+		}
+		processes = append(processes, KiroProcess{
+			PID:     pid,
+			Name:    processDisplayName(exe, cmdline),
+			Exe:     exe,
+			Cmdline: cmdline,
+			Type:    electronProcessType(cmdline),
+		})
+	}
+	return processes, nil
+}
+
+// OpenFilesUnder 列出所有进程在指定目录下打开的文件
+// 无权读取的进程（其他用户的进程）会被跳过
+func (p *ProcFS) OpenFilesUnder(roots []string) ([]OpenFile, error) {
+	pids, err := p.pids()
+	if err != nil {
+		return nil, err
+	}
+
+	var cleanRoots []string
+	for _, root := range roots {
+		if resolved, err := filepath.EvalSymlinks(root); err == nil {
+			cleanRoots = append(cleanRoots, resolved)
+		}
+		cleanRoots = append(cleanRoots, filepath.Clean(root))
+	}
+
+	var files []OpenFile
+	for _, pid := range pids {
+		fdDir := filepath.Join(p.root, strconv.Itoa(pid), "fd")
+		entries, err := os.ReadDir(fdDir)
+		if err != nil {
+			continue
+		}
+
+		var name string
+		seen := make(map[string]bool)
+		for _, entry := range entries {
+			target, err := os.Readlink(filepath.Join(fdDir, entry.Name()))
+			if err != nil || !filepath.IsAbs(target) {
+				continue
+			}
+			target = strings.TrimSuffix(target, " (deleted)")
+			if seen[target] || !pathUnderAny(target, cleanRoots) {
+				continue
+			}
+			seen[target] = true
+			if name == "" {
+				name = processDisplayName(p.readProcess(pid))
+			}
+			files = append(files, OpenFile{PID: pid, Process: name, Path: target})
+		}
+	}
+	return files, nil
+}
+
+// OpenFilesUnder 使用系统 procfs 列出指定目录下被打开的文件（仅 Linux）
+func OpenFilesUnder(roots []string) ([]OpenFile, error) {
+	proc := NewProcFS("")
+	if !proc.Available() {
+		return nil, fmt.Errorf("procfs 不可用")
+	}
+	return proc.OpenFilesUnder(roots)
+}
+
+// isKiroExecutable 根据可执行文件路径和命令行判断是否是 Kiro 进程
+func isKiroExecutable(exe string, cmdline []string) bool {
+	candidates := []string{exe}
+	if len(cmdline) > 0 {
+		candidates = append(candidates, cmdline[0])
+	}
+	for _, candidate := range candidates {
+		if candidate == "" {
+			continue
+		}
+		name := strings.ToLower(filepath.Base(candidate))
+		if strings.Contains(name, "kiro-cleaner") || strings.Contains(name, "kiro_cleaner") ||
+			strings.Contains(name, "kiro-cli") || strings.Contains(name, "kiro_cli") {
+			return false
+		}
+		switch {
+		case name == "kiro" || name == "kiro.bin":
+			// 主进程与 Electron 辅助进程（--type=renderer 等）使用同一个可执行文件
+			return true
+		case strings.HasPrefix(name, "kiro") && strings.HasSuffix(name, ".appimage"):
+			// AppImage 启动器
+			return true
+		}
+	}
+	return false
+}
+
+// electronProcessType 返回 Electron 进程类型，主进程返回 "main"
+func electronProcessType(cmdline []string) string {
+	for i, arg := range cmdline {
+		if i > 0 && strings.HasPrefix(arg, "--type=") {
+			return strings.TrimPrefix(arg, "--type=")
+		}
+	}
+	return "main"
+}
+
+// processDisplayName 进程显示名，例如 "kiro (renderer)"
+func processDisplayName(exe string, cmdline []string) string {
+	name := filepath.Base(exe)
+	if exe == "" && len(cmdline) > 0 {
+		name = filepath.Base(cmdline[0])
+	}
+	if name == "" || name == "." {
+		return "unknown"
+	}
+	if t := electronProcessType(cmdline); t != "main" {
+		return fmt.Sprintf("%s (%s)", name, t)
+	}
+	return name
+}
+
+// pathUnderAny 判断路径是否位于任一目录下
+func pathUnderAny(path string, roots []string) bool {
+	for _, root := range roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			continue
+		}
+		if rel == "." || (rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,223 @@
+package watch
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/trend"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// Category 返回文件所属的类别（与 trend 的类别一致）
+func Category(path string) string {
+	if strings.HasSuffix(strings.ToLower(path), ".chat") {
+		return trend.CategoryConversations
+	}
+	switch storage.ClassifyFile(path) {
+	case types.TypeLog:
+		return trend.CategoryLogs
+	case types.TypeCache:
+		return trend.CategoryCache
+	case types.TypeIndex:
+		return trend.CategoryIndex
+	case types.TypeBackup:
+		return trend.CategoryHistory
+	case types.TypeCrash:
+		return trend.CategoryCrash
+	case types.TypeTemp:
+		return trend.CategoryTemp
+	default:
+		return trend.CategoryOther
+	}
+}
+
+// sample 一次大小变化
+type sample struct {
+	at    time.Time
+	delta int64
+}
+
+// DirGrowth 一个目录的增长情况
+type DirGrowth struct {
+	Dir       string
+	Category  string    // 最近一次写入的文件类别
+	Growth    int64     // 开始监控以来的增长（可为负）
+	Recent    int64     // 统计窗口内的增长
+	Events    int       // 写入次数
+	LastWrite time.Time // 最近一次写入时间
+	samples   []sample
+}
+
+// Rate 返回统计窗口内的增长速度（字节/秒）
+func (d DirGrowth) Rate(window time.Duration) float64 {
+	if window <= 0 {
+		return 0
+	}
+	return float64(d.Recent) / window.Seconds()
+}
+
+// Tracker 记录每个文件的大小，按类别和目录统计增长
+type Tracker struct {
+	mu      sync.Mutex
+	window  time.Duration
+	sizes   map[string]int64
+	totals  map[string]int64 // 类别 -> 当前大小
+	growth  map[string]int64 // 类别 -> 开始监控以来的增长
+	dirs    map[string]*DirGrowth
+	started time.Time
+}
+
+// NewTracker 创建统计器，window 为计算增长速度的时间窗口
+func NewTracker(window time.Duration) *Tracker {
+	return &Tracker{
+		window:  window,
+		sizes:   make(map[string]int64),
+		totals:  make(map[string]int64),
+		growth:  make(map[string]int64),
+		dirs:    make(map[string]*DirGrowth),
+		started: time.Now(),
+	}
+}
+
+// Window 返回统计窗口
+func (t *Tracker) Window() time.Duration {
+	return t.window
+}
+
+// Started 返回开始监控的时间
+func (t *Tracker) Started() time.Time {
+	return t.started
+}
+
+// Seed 记录文件的初始大小，不计入增长
+func (t *Tracker) Seed(path string, size int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if old, ok := t.sizes[path]; ok {
+		t.totals[Category(path)] -= old
+	}
+	t.sizes[path] = size
+	t.totals[Category(path)] += size
+}
+
+// Update 记录文件的新大小，返回相对上次的变化
+func (t *Tracker) Update(path string, size int64, now time.Time) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delta := size - t.sizes[path]
+	t.sizes[path] = size
+	t.record(path, delta, now)
+	return delta
+}
+
+// Remove 记录文件被删除，返回减少的字节数（负数）
+func (t *Tracker) Remove(path string, now time.Time) int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	old, ok := t.sizes[path]
+	if !ok {
+		return 0
+	}
+	delete(t.sizes, path)
+	t.record(path, -old, now)
+	return -old
+}
+
+// record 累加类别和目录的变化（调用方持有锁）
+func (t *Tracker) record(path string, delta int64, now time.Time) {
+	category := Category(path)
+	t.totals[category] += delta
+	t.growth[category] += delta
+
+	dir := filepath.Dir(path)
+	d, ok := t.dirs[dir]
+	if !ok {
+		d = &DirGrowth{Dir: dir}
+		t.dirs[dir] = d
+	}
+	d.Category = category
+	d.Growth += delta
+	d.Events++
+	d.LastWrite = now
+	d.samples = append(d.samples, sample{at: now, delta: delta})
+	t.prune(d, now)
+}
+
+// prune 丢弃窗口之外的记录并更新窗口内增长（调用方持有锁）
+func (t *Tracker) prune(d *DirGrowth, now time.Time) {
+	cutoff := now.Add(-t.window)
+	i := 0
+	for i < len(d.samples) && d.samples[i].at.Before(cutoff) {
+		i++
+	}
+	d.samples = d.samples[i:]
+	d.Recent = 0
+	for _, s := range d.samples {
+		d.Recent += s.delta
+	}
+}
+
+// Totals 返回各类别当前大小
+func (t *Tracker) Totals() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return copyMap(t.totals)
+}
+
+// Growth 返回各类别开始监控以来的增长
+func (t *Tracker) Growth() map[string]int64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return copyMap(t.growth)
+}
+
+// Top 返回窗口内增长最快的 n 个目录（n<=0 返回全部）
+func (t *Tracker) Top(n int, now time.Time) []DirGrowth {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	var dirs []DirGrowth
+	for _, d := range t.dirs {
+		t.prune(d, now)
+		if d.Recent <= 0 && d.Growth <= 0 {
+			continue
+		}
+		dirs = append(dirs, *d)
+	}
+	sort.Slice(dirs, func(i, j int) bool {
+		if dirs[i].Recent != dirs[j].Recent {
+			return dirs[i].Recent > dirs[j].Recent
+		}
+		if dirs[i].Growth != dirs[j].Growth {
+			return dirs[i].Growth > dirs[j].Growth
+		}
+		return dirs[i].Dir < dirs[j].Dir
+	})
+	if n > 0 && len(dirs) > n {
+		dirs = dirs[:n]
+	}
+	return dirs
+}
+
+// Exceeded 返回当前大小超过限制的类别（按类别显示顺序）
+func (t *Tracker) Exceeded(limits map[string]int64) []string {
+	totals := t.Totals()
+	var exceeded []string
+	for _, category := range trend.Categories {
+		if limit := limits[category]; limit > 0 && totals[category] > limit {
+			exceeded = append(exceeded, category)
+		}
+	}
+	return exceeded
+}
+
+func copyMap(m map[string]int64) map[string]int64 {
+	out := make(map[string]int64, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
@@ -0,0 +1,152 @@
+package watch
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/trend"
+)
+
+func TestCategory(t *testing.T) {
+	cases := map[string]string{
+		"/k/logs/20240101/main.log":            trend.CategoryLogs,
+		"/k/Cache/Cache_Data/data_1":           trend.CategoryCache,
+		"/k/kiro.kiroagent/abc/123.chat":       trend.CategoryConversations,
+		"/k/Crashpad/completed/x.dmp":          trend.CategoryCrash,
+		"/k/User/workspaceStorage/x/state.tmp": trend.CategoryTemp,
+		"/k/unknown.bin":                       trend.CategoryOther,
+	}
+	for path, want := range cases {
+		if got := Category(path); got != want {
+			t.Errorf("Category(%q) = %s, 期望 %s", path, got, want)
+		}
+	}
+}
+
+func TestTrackerSeedIsNotGrowth(t *testing.T) {
+	tr := NewTracker(time.Minute)
+	tr.Seed("/k/logs/a.log", 100)
+	tr.Seed("/k/logs/a.log", 150) // 重复记录只保留最新大小
+
+	if got := tr.Totals()[trend.CategoryLogs]; got != 150 {
+		t.Errorf("日志总大小 = %d, 期望 150", got)
+	}
+	if got := tr.Growth()[trend.CategoryLogs]; got != 0 {
+		t.Errorf("初始大小不应计入增长, 实际 %d", got)
+	}
+	if top := tr.Top(5, time.Now()); len(top) != 0 {
+		t.Errorf("没有写入时不应有增长目录: %+v", top)
+	}
+}
+
+func TestTrackerUpdateAndRemove(t *testing.T) {
+	tr := NewTracker(time.Minute)
+	now := time.Now()
+	tr.Seed("/k/logs/a.log", 100)
+
+	if delta := tr.Update("/k/logs/a.log", 300, now); delta != 200 {
+		t.Errorf("变化 = %d, 期望 200", delta)
+	}
+	tr.Update("/k/logs/b.log", 50, now) // 新文件
+	if got := tr.Growth()[trend.CategoryLogs]; got != 250 {
+		t.Errorf("日志增长 = %d, 期望 250", got)
+	}
+
+	if delta := tr.Remove("/k/logs/a.log", now); delta != -300 {
+		t.Errorf("删除变化 = %d, 期望 -300", delta)
+	}
+	if delta := tr.Remove("/k/logs/missing.log", now); delta != 0 {
+		t.Errorf("未记录的文件删除应无变化, 实际 %d", delta)
+	}
+	if got := tr.Totals()[trend.CategoryLogs]; got != 50 {
+		t.Errorf("日志总大小 = %d, 期望 50", got)
+	}
+}
+
+func TestTrackerTopUsesWindow(t *testing.T) {
+	tr := NewTracker(time.Minute)
+	now := time.Now()
+	tr.Update("/k/Cache/old/x", 10000, now.Add(-5*time.Minute)) // 窗口之外
+	tr.Update("/k/logs/fast/a.log", 2000, now.Add(-10*time.Second))
+	tr.Update("/k/logs/slow/a.log", 500, now.Add(-5*time.Second))
+
+	top := tr.Top(0, now)
+	if len(top) != 3 {
+		t.Fatalf("应有 3 个目录, 实际 %d", len(top))
+	}
+	if top[0].Dir != "/k/logs/fast" || top[1].Dir != "/k/logs/slow" {
+		t.Errorf("应按窗口内增长排序: %s, %s", top[0].Dir, top[1].Dir)
+	}
+	if top[2].Recent != 0 || top[2].Growth != 10000 {
+		t.Errorf("窗口外的增长不计入 Recent: %+v", top[2])
+	}
+	if rate := top[0].Rate(time.Minute); rate < 33 || rate > 34 {
+		t.Errorf("增长速度 = %.1f B/s, 期望约 33.3", rate)
+	}
+	if top := tr.Top(1, now); len(top) != 1 {
+		t.Errorf("应只返回 1 个目录, 实际 %d", len(top))
+	}
+}
+
+func TestTrackerExceeded(t *testing.T) {
+	tr := NewTracker(time.Minute)
+	tr.Seed("/k/logs/a.log", 600)
+	tr.Seed("/k/Cache/x", 100)
+
+	exceeded := tr.Exceeded(map[string]int64{
+		trend.CategoryLogs:  500,
+		trend.CategoryCache: 500,
+	})
+	if len(exceeded) != 1 || exceeded[0] != trend.CategoryLogs {
+		t.Errorf("超限类别 = %v, 期望 [logs]", exceeded)
+	}
+}
+
+func TestWatcherTracksWrites(t *testing.T) {
+	root := t.TempDir()
+	logDir := filepath.Join(root, "logs")
+	if err := os.MkdirAll(logDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	logFile := filepath.Join(logDir, "main.log")
+	if err := os.WriteFile(logFile, make([]byte, 100), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := NewTracker(time.Minute)
+	w, err := NewWatcher([]string{root}, tr)
+	if err != nil {
+		t.Fatalf("创建监控失败: %v", err)
+	}
+	defer w.Close()
+	if dirs, _ := w.Dirs(); dirs != 2 {
+		t.Errorf("应监控 2 个目录, 实际 %d", dirs)
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	go w.Run(stop)
+
+	// 写入已有文件和新目录中的文件
+	if err := os.WriteFile(logFile, make([]byte, 1100), 0644); err != nil {
+		t.Fatal(err)
+	}
+	newDir := filepath.Join(root, "logs", "new")
+	if err := os.MkdirAll(newDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(newDir, "b.log"), make([]byte, 500), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if tr.Growth()[trend.CategoryLogs] == 1500 {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Errorf("日志增长 = %d, 期望 1500", tr.Growth()[trend.CategoryLogs])
+}
@@ -0,0 +1,143 @@
+package watch
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher 监控目录树中的文件写入，并更新 Tracker
+type Watcher struct {
+	fs      *fsnotify.Watcher
+	tracker *Tracker
+	mu      sync.Mutex
+	dirs    int   // 已添加监控的目录数
+	failed  int   // 添加监控失败的目录数（如超出 inotify 上限）
+	lastErr error // 最近一次添加监控的错误
+}
+
+// NewWatcher 创建监控器：遍历根目录记录文件初始大小，并监控每个子目录
+func NewWatcher(roots []string, tracker *Tracker) (*Watcher, error) {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("创建文件监控失败: %v", err)
+	}
+	w := &Watcher{fs: fw, tracker: tracker}
+	for _, root := range roots {
+		w.addTree(root, false)
+	}
+	if w.dirs == 0 {
+		fw.Close()
+		if w.lastErr != nil {
+			return nil, fmt.Errorf("无法监控任何目录: %v", w.lastErr)
+		}
+		return nil, fmt.Errorf("没有可监控的目录")
+	}
+	return w, nil
+}
+
+// Dirs 返回已监控的目录数和失败的目录数
+func (w *Watcher) Dirs() (watched, failed int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.dirs, w.failed
+}
+
+// LastError 返回最近一次添加监控的错误
+func (w *Watcher) LastError() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.lastErr
+}
+
+// setErr 记录监控错误
+func (w *Watcher) setErr(err error) {
+	w.mu.Lock()
+	w.lastErr = err
+	w.mu.Unlock()
+}
+
+// addTree 监控目录树；grown 为 true 时新文件的大小计入增长（监控期间新建的目录）
+func (w *Watcher) addTree(root string, grown bool) {
+	now := time.Now()
+	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil // 跳过无法访问的文件
+		}
+		// 跳过符号链接，不监控链接指向的目录之外的文件
+		if info.Mode()&os.ModeSymlink != 0 {
+			return nil
+		}
+		if info.IsDir() {
+			err := w.fs.Add(path)
+			w.mu.Lock()
+			defer w.mu.Unlock()
+			if err != nil {
+				w.failed++
+				w.lastErr = err
+				return filepath.SkipDir
+			}
+			w.dirs++
+			return nil
+		}
+		if grown {
+			w.tracker.Update(path, info.Size(), now)
+		} else {
+			w.tracker.Seed(path, info.Size())
+		}
+		return nil
+	})
+}
+
+// Run 处理文件事件直到 stop 被关闭
+func (w *Watcher) Run(stop <-chan struct{}) error {
+	for {
+		select {
+		case <-stop:
+			return nil
+		case event, ok := <-w.fs.Events:
+			if !ok {
+				return nil
+			}
+			w.handle(event)
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				return nil
+			}
+			// 事件队列溢出等错误不影响继续监控
+			w.setErr(err)
+		}
+	}
+}
+
+// handle 根据事件更新文件大小
+func (w *Watcher) handle(event fsnotify.Event) {
+	now := time.Now()
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		w.tracker.Remove(event.Name, now)
+		return
+	}
+	if !event.Has(fsnotify.Create) && !event.Has(fsnotify.Write) {
+		return
+	}
+	info, err := os.Lstat(event.Name)
+	if err != nil || info.Mode()&os.ModeSymlink != 0 {
+		return
+	}
+	if info.IsDir() {
+		if event.Has(fsnotify.Create) {
+			w.addTree(event.Name, true)
+		}
+		return
+	}
+	w.tracker.Update(event.Name, info.Size(), now)
+}
+
+// Close 停止监控
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
@@ -15,6 +15,9 @@ const (
 	TypeBackup
 	TypeIndex    // 代码索引文件
 	TypeUnknown
+	// 以下类型追加在 TypeUnknown 之后，保持已有类型的数值不变（JSON 输出和规则按数值比较）
+	TypeCrash  // 崩溃报告（Crashpad）
+	TypeOrphan // 没有被任何对话、会话或执行状态引用的快照和差异内容
 )
 
 // FileInfo 文件信息结构
@@ -94,7 +97,7 @@ type Condition struct {
 
 // Action 动作结构
 type Action struct {
-	Type    string `json:"type"`    // 动作类型 (delete, archive, compress等)
+	Type    string `json:"type"`    // 动作类型 (delete, quarantine, slim等)
 	Backup  bool   `json:"backup"`  // 是否备份
 	Params  map[string]interface{} `json:"params"` // 动作参数
 }
@@ -117,6 +120,7 @@ type CleanupAction struct {
 	Reason      string    `json:"reason"`
 	Size        int64     `json:"size"`
 	BackupPath  string    `json:"backup_path,omitempty"`
+	Params      map[string]interface{} `json:"params,omitempty"` // 规则动作的参数（如 slim 的 threshold/keep）
 }
 
 // CleanupResult 清理结果结构
@@ -124,6 +128,7 @@ type CleanupResult struct {
 	Success      bool              `json:"success"`        // 是否成功
 	ActionsTaken []CleanupAction   `json:"actions_taken"`  // 执行的操作
 	BytesFreed   int64             `json:"bytes_freed"`    // 释放的字节数
+	BytesQuarantined int64         `json:"bytes_quarantined,omitempty"` // 移入隔离目录的字节数（仍占用磁盘）
 	Errors       []CleanupError    `json:"errors"`         // 错误信息
 	Duration     time.Duration     `json:"duration"`       // 执行时间
 	BackupID     string            `json:"backup_id"`      // 备份ID
@@ -165,6 +170,7 @@ type SafetyConfig struct {
 	RequireConfirmation bool `json:"require_confirmation"` // 需要用户确认
 	BackupBeforeDelete bool `json:"backup_before_delete"` // 删除前备份
 	MaxConcurrentOps int    `json:"max_concurrent_ops"` // 最大并发操作数
+	QuarantineFallback bool `json:"quarantine_fallback"` // 空间不足以备份时改为隔离（同卷重命名）
 }
 
 // UIConfig UI配置结构
@@ -248,6 +254,10 @@ type ChatFileInfo struct {
 	HumanMessages int          `json:"human_messages"` // 用户消息数
 	BotMessages   int          `json:"bot_messages"`   // 助手消息数
 	ToolMessages  int          `json:"tool_messages"`  // 工具消息数
+	Tokens        int          `json:"tokens"`         // 估算的 token 总数
+	HumanTokens   int          `json:"human_tokens"`   // 用户消息的估算 token 数
+	BotTokens     int          `json:"bot_tokens"`     // 助手消息的估算 token 数
+	ToolTokens    int          `json:"tool_tokens"`    // 工具消息的估算 token 数
 	Metadata      ChatMetadata `json:"metadata"`       // 元数据
 }
 
@@ -258,6 +268,7 @@ type WorkspaceStats struct {
 	ConversationCount int       `json:"conversation_count"` // 对话数量
 	TotalMessages     int       `json:"total_messages"`     // 总消息数
 	TotalSize         int64     `json:"total_size"`         // 总大小(字节)
+	TotalTokens       int       `json:"total_tokens"`       // 估算的 token 总数
 	LastActivity      time.Time `json:"last_activity"`      // 最后活动时间
 }
 
@@ -266,6 +277,7 @@ type ConversationStats struct {
 	TotalConversations int              `json:"total_conversations"` // 总对话数
 	TotalMessages      int              `json:"total_messages"`      // 总消息数
 	TotalSize          int64            `json:"total_size"`          // 总大小(字节)
+	TotalTokens        int              `json:"total_tokens"`        // 估算的 token 总数
 	HumanMessages      int              `json:"human_messages"`      // 用户消息数
 	BotMessages        int              `json:"bot_messages"`        // 助手消息数
 	ToolMessages       int              `json:"tool_messages"`       // 工具消息数
@@ -274,12 +286,30 @@ type ConversationStats struct {
 	LastActivity       time.Time        `json:"last_activity"`       // 最后活动时间
 }
 
+// 对话被选中清理的原因
+const (
+	ChatReasonAll        = "all"         // 没有任何保留规则
+	ChatReasonOld        = "old"         // 超过保留天数
+	ChatReasonLarge      = "large"       // 超过大小限制
+	ChatReasonBeyondLast = "beyond_last" // 不在工作区最新的N个对话中（且超过保留天数，如果设置了）
+)
+
+// ChatRetention 对话保留规则，各规则同时生效
+type ChatRetention struct {
+	AgeDays   int   // 只选择超过N天未修改的对话（0=不限）
+	SizeBytes int64 // 同时选择超过该大小的对话（0=不按大小）
+	KeepLast  int   // 每个工作区保留最新的N个对话（按元数据 endTime 排序，0=不限）
+}
+
 // CleanableConversation 可清理的对话
 type CleanableConversation struct {
-	Path    string    `json:"path"`     // 文件路径
-	Size    int64     `json:"size"`     // 文件大小(字节)
-	ModTime time.Time `json:"mod_time"` // 修改时间
-	Reason  string    `json:"reason"`   // 清理原因: "old" 或 "large"
+	Path      string    `json:"path"`      // 文件路径
+	Size      int64     `json:"size"`      // 文件大小(字节)
+	ModTime   time.Time `json:"mod_time"`  // 修改时间
+	Reason    string    `json:"reason"`    // 清理原因: ChatReason*
+	Workspace string    `json:"workspace"` // 所在工作区目录名
+	EndTime   time.Time `json:"end_time"`  // 元数据中的结束时间（设置 KeepLast 时解析，缺失时为修改时间）
+	Rank      int       `json:"rank"`      // 在工作区中的新旧排名（1=最新，设置 KeepLast 时有效）
 }
 
 
@@ -0,0 +1,114 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/cleaner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/config"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/pins"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// TestCleanupEngine_UnreadablePinsSkipsChats 固定列表损坏时不应删除任何对话
+func TestCleanupEngine_UnreadablePinsSkipsChats(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	if err := os.MkdirAll(config.ConfigDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config.PinsPath(), []byte("{broken"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := cleaner.NewCleanupEngine(nil, nil, nil, nil)
+	engine.SetRules([]types.CleanupRule{{
+		Name:       "all",
+		Enabled:    true,
+		Conditions: []types.Condition{{Type: "file_size", Operator: ">", Value: int64(0)}},
+		Actions:    []types.Action{{Type: "delete"}},
+	}})
+
+	files := []types.FileInfo{
+		{Path: filepath.Join(home, "ws", "a.chat"), Name: "a.chat", Size: 10},
+		{Path: filepath.Join(home, "logs", "main.log"), Name: "main.log", Size: 10},
+	}
+	preview, err := engine.Preview(files)
+	if err != nil {
+		t.Fatalf("预览失败: %v", err)
+	}
+	if len(preview.Actions) != 1 || preview.Actions[0].Target.Name != "main.log" {
+		t.Errorf("只应清理日志文件，得到 %+v", preview.Actions)
+	}
+	if len(preview.Warnings) == 0 {
+		t.Error("应提示固定列表读取失败")
+	}
+}
+
+// TestCleanupEngine_PinFollowsMovedChat Kiro 移动了固定的对话后仍不应清理它
+func TestCleanupEngine_PinFollowsMovedChat(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	oldPath := filepath.Join(home, "ws-old", "a.chat")
+	newPath := filepath.Join(home, "ws-new", "a.chat")
+	other := filepath.Join(home, "ws-new", "b.chat")
+	for path, id := range map[string]string{oldPath: "exec-pinned", other: "exec-other"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		content := `{"executionId":"` + id + `","chat":[{"role":"human","content":"hello"}]}`
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	registry, err := pins.Load(config.PinsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat, err := pins.ReadChat(oldPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	registry.Add(chat, time.Now())
+	if err := registry.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(newPath), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(oldPath, newPath); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := cleaner.NewCleanupEngine(nil, nil, nil, nil)
+	engine.SetRules([]types.CleanupRule{{
+		Name:       "all",
+		Enabled:    true,
+		Conditions: []types.Condition{{Type: "file_size", Operator: ">", Value: int64(0)}},
+		Actions:    []types.Action{{Type: "delete"}},
+	}})
+
+	files := []types.FileInfo{
+		{Path: newPath, Name: "a.chat", Size: 10},
+		{Path: other, Name: "b.chat", Size: 10},
+	}
+	preview, err := engine.Preview(files)
+	if err != nil {
+		t.Fatalf("预览失败: %v", err)
+	}
+	if len(preview.Actions) != 1 || preview.Actions[0].Target.Path != other {
+		t.Errorf("只应清理未固定的对话，得到 %+v", preview.Actions)
+	}
+
+	saved, err := pins.Load(config.PinsPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !saved.Contains(newPath) {
+		t.Error("固定列表应更新为对话的新路径")
+	}
+}
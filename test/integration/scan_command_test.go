@@ -122,8 +122,13 @@ func TestScanCommand_FullWorkflow(t *testing.T) {
 	}
 	
 	// 验证临时文件
-	if typeCounts[types.TypeTemp] != 2 {
-		t.Errorf("临时文件数量应该是 2，实际是 %d", typeCounts[types.TypeTemp])
+	if typeCounts[types.TypeTemp] != 1 {
+		t.Errorf("临时文件数量应该是 1，实际是 %d", typeCounts[types.TypeTemp])
+	}
+	
+	// 验证崩溃报告
+	if typeCounts[types.TypeCrash] != 1 {
+		t.Errorf("崩溃报告数量应该是 1，实际是 %d", typeCounts[types.TypeCrash])
 	}
 	
 	// 验证索引文件
@@ -325,7 +330,7 @@ func TestScanCommand_MixedContent(t *testing.T) {
 		"logs/main.log":       {generateTestContent(200), types.TypeLog},
 		"cache/data":          {generateTestContent(300), types.TypeCache},
 		"file.tmp":            {generateTestContent(50), types.TypeTemp},
-		"crashpad/crash":      {generateTestContent(75), types.TypeTemp},
+		"crashpad/crash":      {generateTestContent(75), types.TypeCrash},
 		"index/vectors.lance": {generateTestContent(400), types.TypeIndex},
 		"config.json":         {[]byte(`{}`), types.TypeConfig},
 		"history/backup":      {generateTestContent(150), types.TypeBackup},
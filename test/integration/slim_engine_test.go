@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/backup"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/cleaner"
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/ui"
+	"github.com/vibe-coding-labs/kiro-cleaner/pkg/types"
+)
+
+// slimChat 写入一个带大段工具输出的对话文件
+func slimChat(t *testing.T, path string) []byte {
+	t.Helper()
+	data, err := json.Marshal(map[string]interface{}{
+		"executionId": "slim-test",
+		"chat": []map[string]interface{}{
+			{"role": "human", "content": "run the tests"},
+			{"role": "tool", "content": strings.Repeat("x", 50000), "toolUseId": "t1"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.MkdirAll(filepath.Dir(path), 0755)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func slimEngine(root string, backupMgr *backup.BackupManager) *cleaner.CleanupEngine {
+	engine := cleaner.NewCleanupEngine(nil, nil, backupMgr, ui.NewSimplePrompter(io.Discard))
+	engine.SetAllowedRoots(root)
+	engine.SetRules([]types.CleanupRule{{
+		Name:       "slim",
+		Enabled:    true,
+		Conditions: []types.Condition{{Type: "file_size", Operator: ">", Value: int64(0)}},
+		Actions:    []types.Action{{Type: "slim"}},
+	}})
+	return engine
+}
+
+// TestCleanupEngine_SlimNeedsBackup 没有备份时不改写对话，有备份时先备份再裁剪
+func TestCleanupEngine_SlimNeedsBackup(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	root := t.TempDir()
+	path := filepath.Join(root, "ws", "a.chat")
+	original := slimChat(t, path)
+	files := []types.FileInfo{{Path: path, Name: "a.chat", Size: int64(len(original))}}
+
+	result, _ := slimEngine(root, nil).Execute(files, false)
+	if result.Success || len(result.ActionsTaken) != 0 {
+		t.Errorf("没有备份时不应裁剪: %+v", result)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, original) {
+		t.Error("没有备份时对话文件不应被改写")
+	}
+
+	backupDir := filepath.Join(t.TempDir(), "backups")
+	backupMgr := backup.NewBackupManager(&types.BackupConfig{Path: backupDir})
+	result, _ = slimEngine(root, backupMgr).Execute(files, false)
+	if len(result.ActionsTaken) != 1 || result.BytesFreed <= 0 {
+		t.Fatalf("有备份时应裁剪: %+v", result)
+	}
+	var backups []string
+	filepath.Walk(filepath.Join(backupDir, "files"), func(p string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() && filepath.Base(p) == "a.chat" {
+			backups = append(backups, p)
+		}
+		return nil
+	})
+	if len(backups) != 1 {
+		t.Fatalf("应单独备份对话文件，得到 %v", backups)
+	}
+	if data, _ := os.ReadFile(backups[0]); !bytes.Equal(data, original) {
+		t.Error("备份应与原文件相同")
+	}
+}
@@ -0,0 +1,173 @@
+package storage_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/storage"
+)
+
+// isolate 使用空的 HOME 和 XDG_CONFIG_HOME，避免发现本机真实的 Kiro 安装
+func isolate(t *testing.T) string {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv(storage.UserDataDirEnv, "")
+	t.Setenv(storage.PortableEnv, "")
+	t.Cleanup(func() { storage.UseInstall("") })
+	return home
+}
+
+func findInstall(installs []storage.Install, label string) (storage.Install, bool) {
+	for _, in := range installs {
+		if in.Label == label {
+			return in, true
+		}
+	}
+	return storage.Install{}, false
+}
+
+func TestUserDataDirArg(t *testing.T) {
+	cases := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"/opt/kiro/kiro", "--user-data-dir=/data/kiro"}, "/data/kiro"},
+		{[]string{"/opt/kiro/kiro", "--user-data-dir", "/data/kiro", "--verbose"}, "/data/kiro"},
+		{[]string{"/opt/kiro/kiro", "--user-data-dir"}, ""},
+		{[]string{"/opt/kiro/kiro", "--extensions-dir=/x"}, ""},
+	}
+	for _, c := range cases {
+		if got := storage.UserDataDirArg(c.args); got != c.want {
+			t.Errorf("UserDataDirArg(%v) = %q, 期望 %q", c.args, got, c.want)
+		}
+	}
+}
+
+func TestDiscoverInstalls_EnvAndChannels(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("发布渠道目录的位置与系统有关")
+	}
+	home := isolate(t)
+	config := filepath.Join(home, ".config")
+	for _, dir := range []string{"Kiro", "Kiro - Insiders", "kiro-cleaner"} {
+		if err := os.MkdirAll(filepath.Join(config, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	custom := filepath.Join(home, "custom-data")
+	os.MkdirAll(custom, 0755)
+	t.Setenv(storage.UserDataDirEnv, custom)
+
+	installs := storage.DiscoverInstalls()
+	expected := map[string]string{
+		"default":  filepath.Join(config, "Kiro"),
+		"insiders": filepath.Join(config, "Kiro - Insiders"),
+		"env":      custom,
+	}
+	for label, path := range expected {
+		in, ok := findInstall(installs, label)
+		if !ok {
+			t.Errorf("未发现 %s 安装: %+v", label, installs)
+			continue
+		}
+		if in.Path != path {
+			t.Errorf("%s 的路径为 %s，期望 %s", label, in.Path, path)
+		}
+	}
+	for _, in := range installs {
+		if filepath.Base(in.Path) == "kiro-cleaner" {
+			t.Errorf("kiro-cleaner 的配置目录不应被当作 Kiro 安装")
+		}
+	}
+}
+
+func TestDiscoverInstalls_SameDirOnce(t *testing.T) {
+	home := isolate(t)
+	dir := filepath.Join(home, "data")
+	os.MkdirAll(dir, 0755)
+	t.Setenv(storage.UserDataDirEnv, dir)
+	storage.RegisterSource(storage.SourceFunc{SourceName: "test", Find: func() []storage.Candidate {
+		return []storage.Candidate{
+			{Label: "test", Path: dir},
+			{Label: "test", Path: filepath.Join(home, "missing")},
+		}
+	}})
+
+	count := 0
+	for _, in := range storage.DiscoverInstalls() {
+		if in.Path == dir {
+			count++
+			if in.Source != "env" {
+				t.Errorf("同一目录应以最先发现的来源为准，得到 %s", in.Source)
+			}
+		}
+		if in.Source == "test" {
+			t.Errorf("不存在的目录不应被发现: %s", in.Path)
+		}
+	}
+	if count != 1 {
+		t.Errorf("同一目录出现了 %d 次", count)
+	}
+}
+
+func TestUseInstall(t *testing.T) {
+	home := isolate(t)
+	dir := filepath.Join(home, "data")
+	os.MkdirAll(dir, 0755)
+	t.Setenv(storage.UserDataDirEnv, dir)
+
+	if err := storage.UseInstall("no-such-install"); err == nil {
+		t.Error("未知标签应返回错误")
+	}
+	if _, ok := storage.SelectedInstall(); ok {
+		t.Error("选择失败后不应有选中的安装")
+	}
+
+	if err := storage.UseInstall("env"); err != nil {
+		t.Fatalf("UseInstall 失败: %v", err)
+	}
+	paths, err := storage.NewStorageDetector().FindKiroPaths()
+	if err != nil {
+		t.Fatalf("FindKiroPaths 失败: %v", err)
+	}
+	if len(paths) != 1 || paths[0] != dir {
+		t.Errorf("选中安装后 FindKiroPaths 应只返回 %s，得到 %v", dir, paths)
+	}
+}
+
+func TestUseInstall_DoesNotWidenScope(t *testing.T) {
+	home := isolate(t)
+	dir := filepath.Join(home, "data")
+	other := filepath.Join(home, "other")
+	os.MkdirAll(dir, 0755)
+	os.MkdirAll(other, 0755)
+	t.Setenv(storage.UserDataDirEnv, dir)
+	storage.RegisterSource(storage.SourceFunc{SourceName: "widen-test", Find: func() []storage.Candidate {
+		return []storage.Candidate{{Label: "other", Path: other}}
+	}})
+
+	if err := storage.UseInstall("env"); err != nil {
+		t.Fatalf("UseInstall 失败: %v", err)
+	}
+	// 来源消失后仍使用选择时解析的目录
+	t.Setenv(storage.UserDataDirEnv, "")
+	in, ok := storage.SelectedInstall()
+	if !ok || in.Path != dir {
+		t.Fatalf("来源消失后选中的安装应不变，得到 %+v, %v", in, ok)
+	}
+	detector := storage.NewStorageDetector()
+	if paths, err := detector.FindKiroPaths(); err != nil || len(paths) != 1 || paths[0] != dir {
+		t.Errorf("FindKiroPaths = %v, %v，期望只有 %s", paths, err, dir)
+	}
+
+	// 目录被删除后报错，不退回到其他安装
+	os.RemoveAll(dir)
+	paths, err := detector.FindKiroPaths()
+	if err == nil || len(paths) != 0 {
+		t.Errorf("选中的目录不存在时应报错且不返回其他安装，得到 %v, %v", paths, err)
+	}
+}
@@ -0,0 +1,88 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+)
+
+func TestRelaunchKiro_UsesArgsAndDir(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("需要 /bin/sh")
+	}
+
+	workDir := t.TempDir()
+	outFile := filepath.Join(t.TempDir(), "out.txt")
+	info := &utils.LaunchInfo{
+		Exe:  "/bin/sh",
+		Args: []string{"-c", `pwd > "$0"; echo "$1" >> "$0"`, outFile, "--new-window"},
+		Dir:  workDir,
+	}
+
+	pid, err := utils.RelaunchKiro(info)
+	if err != nil {
+		t.Fatalf("启动失败: %v", err)
+	}
+	if pid <= 0 {
+		t.Errorf("应返回新进程ID，实际 %d", pid)
+	}
+
+	// 等待子进程写入结果
+	var data []byte
+	for i := 0; i < 50; i++ {
+		data, _ = os.ReadFile(outFile)
+		if strings.Count(string(data), "\n") >= 2 {
+			break
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("输出不完整: %q", data)
+	}
+	resolved, _ := filepath.EvalSymlinks(workDir)
+	if lines[0] != workDir && lines[0] != resolved {
+		t.Errorf("工作目录错误: %s", lines[0])
+	}
+	if lines[1] != "--new-window" {
+		t.Errorf("参数错误: %s", lines[1])
+	}
+}
+
+func TestRelaunchKiro_MissingInfo(t *testing.T) {
+	if _, err := utils.RelaunchKiro(nil); err == nil {
+		t.Error("缺少启动信息时应该返回错误")
+	}
+	if _, err := utils.RelaunchKiro(&utils.LaunchInfo{Exe: "/nonexistent/kiro"}); err == nil {
+		t.Error("可执行文件不存在时应该返回错误")
+	}
+}
+
+func TestParseProcArgs_KeepsSpaces(t *testing.T) {
+	exe := "/Applications/Kiro.app/Contents/MacOS/Kiro"
+	buf := []byte{3, 0, 0, 0}
+	buf = append(buf, exe+"\x00\x00\x00"...)
+	buf = append(buf, exe+"\x00--user-data-dir\x00/Users/x/My Data\x00"...)
+	buf = append(buf, "HOME=/Users/x\x00"...)
+
+	gotExe, args, err := utils.ParseProcArgs(buf)
+	if err != nil {
+		t.Fatalf("解析失败: %v", err)
+	}
+	if gotExe != exe {
+		t.Errorf("可执行文件 = %q", gotExe)
+	}
+	if len(args) != 2 || args[0] != "--user-data-dir" || args[1] != "/Users/x/My Data" {
+		t.Errorf("参数应原样保留，实际 %q", args)
+	}
+
+	if _, _, err := utils.ParseProcArgs([]byte{2, 0, 0, 0, 'a', 0, 'a', 0}); err == nil {
+		t.Error("参数不完整时应返回错误")
+	}
+}
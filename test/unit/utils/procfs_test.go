@@ -0,0 +1,134 @@
+package utils_test
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vibe-coding-labs/kiro-cleaner/internal/utils"
+)
+
+// fakeProcess 在假的 procfs 中创建进程目录
+func fakeProcess(t *testing.T, procRoot string, pid int, exe string, args []string, fds []string) {
+	t.Helper()
+	dir := filepath.Join(procRoot, strconv.Itoa(pid))
+	if err := os.MkdirAll(filepath.Join(dir, "fd"), 0755); err != nil {
+		t.Fatalf("创建进程目录失败: %v", err)
+	}
+	if exe != "" {
+		if err := os.Symlink(exe, filepath.Join(dir, "exe")); err != nil {
+			t.Fatalf("创建 exe 链接失败: %v", err)
+		}
+	}
+	cmdline := strings.Join(args, "\x00") + "\x00"
+	if err := os.WriteFile(filepath.Join(dir, "cmdline"), []byte(cmdline), 0644); err != nil {
+		t.Fatalf("写入 cmdline 失败: %v", err)
+	}
+	fakeStatus(t, procRoot, pid, os.Getuid())
+	for i, target := range fds {
+		if err := os.Symlink(target, filepath.Join(dir, "fd", strconv.Itoa(i+3))); err != nil {
+			t.Fatalf("创建 fd 链接失败: %v", err)
+		}
+	}
+}
+
+// fakeStatus 写入进程的 status 文件（只包含 Uid 行）
+func fakeStatus(t *testing.T, procRoot string, pid, uid int) {
+	t.Helper()
+	id := strconv.Itoa(uid)
+	status := "Name:\tkiro\nUid:\t" + id + "\t" + id + "\t" + id + "\t" + id + "\n"
+	if err := os.WriteFile(filepath.Join(procRoot, strconv.Itoa(pid), "status"), []byte(status), 0644); err != nil {
+		t.Fatalf("写入 status 失败: %v", err)
+	}
+}
+
+func TestProcFS_KiroProcessesOnlyCurrentUser(t *testing.T) {
+	procRoot := t.TempDir()
+	fakeProcess(t, procRoot, 200, "/usr/share/kiro/kiro", []string{"/usr/share/kiro/kiro"}, nil)
+	fakeProcess(t, procRoot, 201, "/usr/share/kiro/kiro", []string{"/usr/share/kiro/kiro"}, nil)
+	fakeStatus(t, procRoot, 201, os.Getuid()+1) // 其他用户的 Kiro
+	fakeProcess(t, procRoot, 202, "/usr/share/kiro/kiro", []string{"/usr/share/kiro/kiro"}, nil)
+	os.Remove(filepath.Join(procRoot, "202", "status")) // 无法确定属主
+
+	processes, err := utils.NewProcFS(procRoot).KiroProcesses()
+	if err != nil {
+		t.Fatalf("读取进程失败: %v", err)
+	}
+	if len(processes) != 1 || processes[0].PID != 200 {
+		t.Errorf("只应识别当前用户的 Kiro 进程，得到 %+v", processes)
+	}
+}
+
+func TestProcFS_KiroProcesses(t *testing.T) {
+	procRoot := t.TempDir()
+	fakeProcess(t, procRoot, 100, "/usr/share/kiro/kiro", []string{"/usr/share/kiro/kiro"}, nil)
+	fakeProcess(t, procRoot, 101, "/usr/share/kiro/kiro", []string{"/usr/share/kiro/kiro", "--type=renderer"}, nil)
+	fakeProcess(t, procRoot, 102, "/home/dev/Apps/Kiro-1.2.AppImage", []string{"/home/dev/Apps/Kiro-1.2.AppImage"}, nil)
+	fakeProcess(t, procRoot, 103, "/usr/local/bin/kiro-cleaner", []string{"kiro-cleaner", "scan"}, nil)
+	fakeProcess(t, procRoot, 104, "/usr/bin/bash", []string{"bash"}, nil)
+	// 无权读取 exe 时通过命令行识别
+	fakeProcess(t, procRoot, 105, "", []string{"/tmp/.mount_KiroAb/kiro", "--type=gpu-process"}, nil)
+	// 升级后旧二进制
+	fakeProcess(t, procRoot, 106, "/opt/Kiro/kiro (deleted)", []string{"/opt/Kiro/kiro", "--type=utility"}, nil)
+
+	processes, err := utils.NewProcFS(procRoot).KiroProcesses()
+	if err != nil {
+		t.Fatalf("读取进程失败: %v", err)
+	}
+
+	got := make(map[int]utils.KiroProcess)
+	for _, p := range processes {
+		got[p.PID] = p
+	}
+	for _, pid := range []int{100, 101, 102, 105, 106} {
+		if _, ok := got[pid]; !ok {
+			t.Errorf("进程 %d 应该被识别为 Kiro", pid)
+		}
+	}
+	for _, pid := range []int{103, 104} {
+		if _, ok := got[pid]; ok {
+			t.Errorf("进程 %d 不应该被识别为 Kiro", pid)
+		}
+	}
+	if got[100].Type != "main" || got[101].Type != "renderer" {
+		t.Errorf("Electron 进程类型错误: %q, %q", got[100].Type, got[101].Type)
+	}
+	if got[106].Exe != "/opt/Kiro/kiro" {
+		t.Errorf("应去掉 (deleted) 后缀: %q", got[106].Exe)
+	}
+}
+
+func TestProcFS_OpenFilesUnder(t *testing.T) {
+	procRoot := t.TempDir()
+	dataRoot := t.TempDir()
+	logFile := filepath.Join(dataRoot, "logs", "main.log")
+	dbFile := filepath.Join(dataRoot, "User", "globalStorage", "state.vscdb")
+
+	fakeProcess(t, procRoot, 200, "/usr/share/kiro/kiro", []string{"/usr/share/kiro/kiro"},
+		[]string{logFile, dbFile, "/dev/null", "socket:[12345]", logFile, logFile + " (deleted)"})
+	fakeProcess(t, procRoot, 201, "/usr/bin/node", []string{"node", "server.js"},
+		[]string{filepath.Join(dataRoot, "logs", "ext.log")})
+	fakeProcess(t, procRoot, 202, "/usr/bin/vim", []string{"vim"},
+		[]string{"/etc/hosts", dataRoot + "-other/file"})
+
+	files, err := utils.NewProcFS(procRoot).OpenFilesUnder([]string{dataRoot})
+	if err != nil {
+		t.Fatalf("读取打开文件失败: %v", err)
+	}
+	if len(files) != 3 {
+		t.Fatalf("应该有 3 个打开的文件，实际 %d: %+v", len(files), files)
+	}
+
+	byPID := make(map[int]int)
+	for _, f := range files {
+		byPID[f.PID]++
+		if f.PID == 200 && f.Process != "kiro" {
+			t.Errorf("进程名错误: %q", f.Process)
+		}
+	}
+	if byPID[200] != 2 || byPID[201] != 1 || byPID[202] != 0 {
+		t.Errorf("按进程统计错误: %v", byPID)
+	}
+}